@@ -0,0 +1,69 @@
+package oop
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestPoolAllocator tests the PoolAllocator type
+func TestPoolAllocator(t *testing.T) {
+	allocator := NewPoolAllocator()
+	structType := reflect.TypeOf(TestStruct{})
+
+	klass := New(allocator, structType, nil)
+	ts, ok := klass.Class.(*TestStruct)
+	if !ok {
+		t.Fatalf("New allocated %T, want *TestStruct", klass.Class)
+	}
+	ts.Value = 42
+
+	stats := allocator.Stats()
+	if stats.Allocs != 1 || stats.Frees != 0 || stats.Live() != 1 {
+		t.Errorf("Stats after Alloc are %+v, want 1 alloc and 1 live", stats)
+	}
+
+	freed := klass.Ptr()
+	klass.Deinit()
+	if stats := allocator.Stats(); stats.Frees != 1 || stats.Live() != 0 {
+		t.Errorf("Stats after Free are %+v, want 1 free and 0 live", stats)
+	}
+
+	// Test that reused instances are zeroed
+	ptr := allocator.Alloc(structType)
+	if ptr == freed && allocator.Stats().Reused != 1 {
+		t.Error("Reused was not counted for a reused instance")
+	}
+	if value := (*TestStruct)(ptr).Value; value != 0 {
+		t.Errorf("Alloc returned an instance with Value %d, want 0", value)
+	}
+	if stats := allocator.Stats(); stats.Allocs != 2 {
+		t.Errorf("Allocs is %d, want 2", stats.Allocs)
+	}
+
+	// Test that instances freed without their class type are not reused
+	allocator.Free(ptr)
+	if stats := allocator.Stats(); stats.Live() != 0 {
+		t.Errorf("Stats after Free are %+v, want 0 live", stats)
+	}
+	for range 10 {
+		if allocator.Alloc(structType) == ptr {
+			t.Fatal("Alloc reused an instance freed without its class type")
+		}
+	}
+}
+
+// TestDefaultAllocator tests that New uses the DefaultAllocator for nil allocators
+func TestDefaultAllocator(t *testing.T) {
+	before := DefaultAllocator.Stats()
+
+	klass := New(nil, reflect.TypeOf(TestStruct{}), nil)
+	if klass.Allocator != DefaultAllocator {
+		t.Error("New did not use the DefaultAllocator")
+	}
+	klass.Deinit()
+
+	after := DefaultAllocator.Stats()
+	if after.Allocs-before.Allocs != 1 || after.Frees-before.Frees != 1 {
+		t.Errorf("Stats changed from %+v to %+v, want 1 alloc and 1 free", before, after)
+	}
+}
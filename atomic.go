@@ -0,0 +1,116 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"unsafe"
+)
+
+// Access gives the function passed to ApplyAtomic access to the locked objects.
+// It must not be used after the function returns.
+type Access struct {
+	objs map[*ObjectWrapper]bool
+}
+
+// Get returns the underlying object of a locked wrapper.
+// It returns nil if the wrapper is not part of the transaction.
+func (tx Access) Get(obj *ObjectWrapper) interface{} {
+	if !tx.objs[obj] {
+		return nil
+	}
+	return obj.GetUnderlyingObject()
+}
+
+// SetFields sets fields on a locked wrapper, see ObjectWrapper.SetFields.
+// Unique indexes are checked when the transaction ends.
+func (tx Access) SetFields(obj *ObjectWrapper, values map[string]interface{}) error {
+	if !tx.objs[obj] {
+		return fmt.Errorf("object is not part of the transaction")
+	}
+	target, assignments, err := obj.fieldAssignments(values)
+	if err != nil {
+		return err
+	}
+	for _, a := range assignments {
+		setField(target, a.index, a.value)
+	}
+	return nil
+}
+
+// ApplyAtomic applies changes to several objects as a single unit.
+// The objects are locked in a deterministic order, so concurrent calls over
+// overlapping objects cannot deadlock. Each object's state is snapshotted first;
+// if fn returns an error or panics, every object is restored from its snapshot.
+// Snapshots are shallow copies: changes made inside maps, slices or pointed-to
+// values shared with the snapshot are not rolled back.
+// It fails without calling fn if any object is soft-deleted. Once fn returns,
+// the indexes of the objects are updated, see CreateIndex; if that violates a
+// unique index, every object is restored and the *UniqueViolationError returned.
+// Example: oop.ApplyAtomic(func(tx oop.Access) error { ... }, fromObj, toObj)
+func ApplyAtomic(fn func(tx Access) error, objs ...*ObjectWrapper) (err error) {
+	if fn == nil {
+		return fmt.Errorf("fn cannot be nil")
+	}
+
+	tx := Access{objs: map[*ObjectWrapper]bool{}}
+	ordered := make([]*ObjectWrapper, 0, len(objs))
+	for _, obj := range objs {
+		if obj == nil {
+			return fmt.Errorf("objs cannot contain nil")
+		}
+		if !tx.objs[obj] {
+			tx.objs[obj] = true
+			ordered = append(ordered, obj)
+		}
+	}
+
+	// Lock in address order to avoid deadlocks between overlapping transactions
+	sort.Slice(ordered, func(i, j int) bool {
+		return uintptr(unsafe.Pointer(ordered[i])) < uintptr(unsafe.Pointer(ordered[j]))
+	})
+	for _, obj := range ordered {
+		obj.mu.Lock()
+		defer obj.mu.Unlock()
+	}
+	for _, obj := range ordered {
+		if err := obj.checkMutable(); err != nil {
+			return err
+		}
+	}
+
+	snapshots := make([]reflect.Value, len(ordered))
+	for i, obj := range ordered {
+		target, err := obj.structValue()
+		if err != nil {
+			return err
+		}
+		snapshots[i] = reflect.New(target.Type()).Elem()
+		snapshots[i].Set(target)
+	}
+
+	rollback := func() {
+		for i, obj := range ordered {
+			target, _ := obj.structValue()
+			target.Set(snapshots[i])
+		}
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			rollback()
+			panic(r)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		rollback()
+		return err
+	}
+	if err := reindex(ordered); err != nil {
+		rollback()
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package oop
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestApplyAtomic tests the ApplyAtomic function
+func TestApplyAtomic(t *testing.T) {
+	factory := NewObjectFactory()
+
+	dog := &TestDog{Name: "Buddy"}
+	cat := &TestCat{Name: "Whiskers"}
+	dogObj := factory.CreateObject(dog)
+	catObj := factory.CreateObject(cat)
+
+	// Test successful apply
+	err := ApplyAtomic(func(tx Access) error {
+		if err := tx.SetFields(dogObj, map[string]interface{}{"Name": "Rex"}); err != nil {
+			return err
+		}
+		tx.Get(catObj).(*TestCat).Name = "Felix"
+		return nil
+	}, dogObj, catObj)
+	if err != nil {
+		t.Fatalf("ApplyAtomic returned error: %v", err)
+	}
+	if dog.Name != "Rex" || cat.Name != "Felix" {
+		t.Errorf("ApplyAtomic did not apply changes, got %q and %q", dog.Name, cat.Name)
+	}
+
+	// Test rollback on error
+	errFailed := errors.New("failed")
+	err = ApplyAtomic(func(tx Access) error {
+		tx.Get(dogObj).(*TestDog).Name = "Max"
+		tx.Get(catObj).(*TestCat).Name = "Tom"
+		return errFailed
+	}, catObj, dogObj)
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("ApplyAtomic returned %v, want %v", err, errFailed)
+	}
+	if dog.Name != "Rex" || cat.Name != "Felix" {
+		t.Errorf("ApplyAtomic did not roll back changes, got %q and %q", dog.Name, cat.Name)
+	}
+
+	// Test rollback on panic
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("ApplyAtomic should re-panic")
+			}
+		}()
+		_ = ApplyAtomic(func(tx Access) error {
+			tx.Get(dogObj).(*TestDog).Name = "Max"
+			panic("boom")
+		}, dogObj)
+	}()
+	if dog.Name != "Rex" {
+		t.Errorf("ApplyAtomic did not roll back after panic, got %q", dog.Name)
+	}
+
+	// Test access to objects outside the transaction
+	err = ApplyAtomic(func(tx Access) error {
+		if tx.Get(catObj) != nil {
+			t.Error("Get should return nil for objects outside the transaction")
+		}
+		return tx.SetFields(catObj, map[string]interface{}{"Name": "Tom"})
+	}, dogObj)
+	if err == nil {
+		t.Error("SetFields should return error for objects outside the transaction")
+	}
+
+	// Test with nil object
+	if err := ApplyAtomic(func(tx Access) error { return nil }, nil); err == nil {
+		t.Error("ApplyAtomic should return error for nil object")
+	}
+}
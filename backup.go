@@ -0,0 +1,228 @@
+package oop
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// backupVersion is the version of the archive format written by Backup.
+const backupVersion = 1
+
+// factoryArchive is the archive written by Backup.
+type factoryArchive struct {
+	Version int              `json:"version"`
+	Budget  int64            `json:"budget,omitempty"` // Reserved memory, see ReserveMemory.
+	Objects []archivedObject `json:"objects"`
+}
+
+// archivedObject is a live object of a factory in an archive.
+type archivedObject struct {
+	ID        int              `json:"id"`
+	Object    json.RawMessage  `json:"object"` // Written by MarshalPolymorphic, without the relation fields.
+	State     State            `json:"state"`
+	DeletedAt int64            `json:"deletedAt,omitempty"` // See SoftDestroy.
+	Interned  bool             `json:"interned,omitempty"`  // See Intern.
+	Relations map[string][]int `json:"relations,omitempty"` // IDs of the related objects, by relation field.
+}
+
+// Backup writes all live objects of the factory to w as a single JSON
+// archive that RestoreFactory rebuilds: their fields, lifecycle states,
+// soft deletion and interning, the relations between them, see
+// DefineRelation, and the memory budget. Objects are encoded like
+// MarshalPolymorphic, so their classes must be registered, and each is encoded
+// under its lock. Relations to objects of other factories are left out, and
+// other pointers are followed and copied like MarshalPolymorphic does.
+// Example: err := factory.Backup(file)
+func (f *ObjectFactory) Backup(w io.Writer) error {
+	objs := f.live.list()
+	ids := make(map[uintptr]int, len(objs))
+	for i, obj := range objs {
+		if v, ok := objectValue(obj.class()); ok {
+			ids[v.Pointer()] = i + 1
+		}
+	}
+
+	f.interned.mu.Lock()
+	interned := make(map[*ObjectWrapper]bool, len(f.interned.objects))
+	for _, obj := range f.interned.objects {
+		interned[obj] = true
+	}
+	f.interned.mu.Unlock()
+
+	archive := factoryArchive{Version: backupVersion, Budget: f.budget.limit.Load(), Objects: []archivedObject{}}
+	for i, obj := range objs {
+		record, ok, err := obj.archive(i+1, ids)
+		if err != nil {
+			return err
+		}
+		if ok {
+			record.Interned = interned[obj]
+			archive.Objects = append(archive.Objects, record)
+		}
+	}
+
+	return json.NewEncoder(w).Encode(archive)
+}
+
+// archive copies the object into an archive record with the given ID,
+// referring to related objects by their IDs. It returns false if the object
+// was destroyed in the meantime.
+func (o *ObjectWrapper) archive(id int, ids map[uintptr]int) (archivedObject, bool, error) {
+	o.mu.Lock()
+	if o.State() == StateDestroyed {
+		o.mu.Unlock()
+		return archivedObject{}, false, nil
+	}
+	target, err := o.structValue()
+	if err != nil {
+		o.mu.Unlock()
+		return archivedObject{}, false, err
+	}
+
+	// The copy is shallow, so it is encoded before the lock is released: its
+	// slices, maps and pointers are shared with the object.
+	copied := reflect.New(target.Type()).Elem()
+	copied.Set(target)
+	record := archivedObject{ID: id, State: o.State(), DeletedAt: o.deletedAt.Load()}
+
+	relationMu.Lock()
+	for _, r := range relationsOf(target.Type()) {
+		related := []int{}
+		for _, v := range relatedObjects(target.Field(r.index)) {
+			if relatedID, ok := ids[v.Pointer()]; ok {
+				related = append(related, relatedID)
+			}
+		}
+		if len(related) > 0 {
+			if record.Relations == nil {
+				record.Relations = map[string][]int{}
+			}
+			record.Relations[r.Name] = related
+		}
+		copied.Field(r.index).SetZero()
+	}
+	relationMu.Unlock()
+
+	record.Object, err = encodePolymorphic(copied, true)
+	o.mu.Unlock()
+	if err != nil {
+		return archivedObject{}, false, fmt.Errorf("object %d: %w", id, err)
+	}
+	return record, true, nil
+}
+
+// RestoreFactory reads an archive written by Backup and returns a new factory
+// holding the objects it contains, with their relations, lifecycle states,
+// soft deletion and interning restored. Classes are looked up by their
+// registered names, and objects are added to the indexes of their classes,
+// see CreateIndex. Lifecycle states are restored without calling Init or
+// Start. On error, the objects restored so far are destroyed.
+// Example: factory, err := oop.RestoreFactory(file)
+func RestoreFactory(r io.Reader) (*ObjectFactory, error) {
+	var archive factoryArchive
+	if err := json.NewDecoder(r).Decode(&archive); err != nil {
+		return nil, fmt.Errorf("invalid backup: %w", err)
+	}
+	if archive.Version != backupVersion {
+		return nil, fmt.Errorf("unsupported backup version %d", archive.Version)
+	}
+
+	f := NewObjectFactory()
+	if err := f.restore(archive); err != nil {
+		for _, obj := range f.live.list() {
+			obj.Destroy()
+		}
+		return nil, err
+	}
+	f.budget.limit.Store(archive.Budget)
+	return f, nil
+}
+
+// restore creates the objects of an archive in the factory.
+func (f *ObjectFactory) restore(archive factoryArchive) error {
+	objs := make(map[int]*ObjectWrapper, len(archive.Objects))
+	for _, record := range archive.Objects {
+		if _, ok := objs[record.ID]; ok {
+			return fmt.Errorf("object %d: duplicate ID", record.ID)
+		}
+		ptr, err := decodeTyped(record.Object)
+		if err != nil {
+			return fmt.Errorf("object %d: %w", record.ID, err)
+		}
+		obj, err := f.CreateObjectE(ptr.Interface())
+		if err != nil {
+			return fmt.Errorf("object %d: %w", record.ID, err)
+		}
+		objs[record.ID] = obj
+	}
+
+	for _, record := range archive.Objects {
+		obj := objs[record.ID]
+		target, _ := obj.structValue()
+		if len(record.Relations) > 0 {
+			if err := restoreRelations(target, record.Relations, objs); err != nil {
+				return fmt.Errorf("object %d: %w", record.ID, err)
+			}
+			if err := obj.indexedWrite(target, nil, nil); err != nil {
+				return fmt.Errorf("object %d: %w", record.ID, err)
+			}
+		}
+
+		if record.State < StateCreated || record.State >= StateDestroyed {
+			return fmt.Errorf("object %d: invalid state %d", record.ID, record.State)
+		}
+		obj.state.Store(int32(record.State))
+		if record.DeletedAt != 0 {
+			obj.deletedAt.Store(record.DeletedAt)
+			f.trash.add(obj)
+		}
+		if record.Interned {
+			key, err := naturalKeyOf(target)
+			if err != nil {
+				return fmt.Errorf("object %d: %w", record.ID, err)
+			}
+			f.interned.mu.Lock()
+			f.intern(key, obj)
+			f.interned.mu.Unlock()
+		}
+	}
+	return nil
+}
+
+// restoreRelations sets the relation fields of target to the objects with the
+// given IDs. Both sides of a relation are archived, so inverses are not synced.
+func restoreRelations(target reflect.Value, relations map[string][]int, objs map[int]*ObjectWrapper) error {
+	relationMu.Lock()
+	defer relationMu.Unlock()
+
+	for name, ids := range relations {
+		r, ok := relationOf(target.Type(), name)
+		if !ok {
+			return fmt.Errorf("relation %s not found on %s", name, target.Type())
+		}
+
+		field := target.Field(r.index)
+		related := make([]reflect.Value, len(ids))
+		for i, id := range ids {
+			obj, ok := objs[id]
+			if !ok {
+				return fmt.Errorf("relation %s refers to unknown object %d", name, id)
+			}
+			related[i] = reflect.ValueOf(obj.class())
+			if related[i].Type() != reflect.PointerTo(r.Target) {
+				return fmt.Errorf("relation %s refers to object %d of class %s, want %s", name, id, related[i].Type().Elem(), r.Target)
+			}
+		}
+
+		if field.Kind() == reflect.Slice {
+			field.Set(reflect.Append(reflect.MakeSlice(field.Type(), 0, len(related)), related...))
+		} else if len(related) == 1 {
+			field.Set(related[0])
+		} else {
+			return fmt.Errorf("relation %s holds a single object, got %d", name, len(related))
+		}
+	}
+	return nil
+}
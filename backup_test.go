@@ -0,0 +1,167 @@
+package oop
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestBackupLabel is a test class with a natural key
+type TestBackupLabel struct {
+	Text  string
+	Color string
+}
+
+// TestUnregisteredBackup is a test class that is never registered
+type TestUnregisteredBackup struct {
+	Name string
+}
+
+// TestBackup tests that RestoreFactory rebuilds the state saved by Backup
+func TestBackup(t *testing.T) {
+	if err := DefineRelation(reflect.TypeOf(TestShopper{}), "Purchases", HasMany, reflect.TypeOf(TestPurchase{}), "Shopper"); err != nil {
+		t.Fatalf("DefineRelation returned error: %v", err)
+	}
+	if err := RegisterNaturalKey(reflect.TypeOf(TestBackupLabel{}), "Text"); err != nil {
+		t.Fatalf("RegisterNaturalKey returned error: %v", err)
+	}
+
+	factory := NewObjectFactory()
+	factory.ReserveMemory(1 << 20)
+	annObj := factory.CreateObject(&TestShopper{Name: "Ann"})
+	bookObj := factory.CreateObject(&TestPurchase{Item: "book"})
+	penObj := factory.CreateObject(&TestPurchase{Item: "pen"})
+	annObj.Relate("Purchases", bookObj)
+	annObj.Relate("Purchases", penObj)
+	annObj.Initialize()
+	annObj.Start()
+	penObj.SoftDestroy()
+	if _, err := factory.Intern(&TestBackupLabel{Text: "sale", Color: "red"}); err != nil {
+		t.Fatalf("Intern returned error: %v", err)
+	}
+	gone := factory.CreateObject(&TestPurchase{Item: "gone"})
+	gone.Destroy()
+
+	var buf bytes.Buffer
+	if err := factory.Backup(&buf); err != nil {
+		t.Fatalf("Backup returned error: %v", err)
+	}
+	for _, obj := range []*ObjectWrapper{annObj, bookObj, penObj} {
+		obj.Destroy()
+	}
+
+	restored, err := RestoreFactory(&buf)
+	if err != nil {
+		t.Fatalf("RestoreFactory returned error: %v", err)
+	}
+	objs := restored.live.list()
+	if len(objs) != 4 {
+		t.Fatalf("RestoreFactory restored %d objects, want 4", len(objs))
+	}
+	defer func() {
+		for _, obj := range objs {
+			obj.Destroy()
+		}
+	}()
+
+	// Test fields, relations and their inverses
+	ann := objs[0].GetUnderlyingObject().(*TestShopper)
+	book := objs[1].GetUnderlyingObject().(*TestPurchase)
+	pen := objs[2].GetUnderlyingObject().(*TestPurchase)
+	if ann.Name != "Ann" || book.Item != "book" || pen.Item != "pen" {
+		t.Errorf("RestoreFactory restored %+v, %+v and %+v", ann, book, pen)
+	}
+	if len(ann.Purchases) != 2 || ann.Purchases[0] != book || ann.Purchases[1] != pen || book.Shopper != ann || pen.Shopper != ann {
+		t.Errorf("RestoreFactory restored relations %v, %v and %v", ann.Purchases, book.Shopper, pen.Shopper)
+	}
+
+	// Test lifecycle states, soft deletion, interning and the budget
+	if objs[0].State() != StateStarted || objs[1].State() != StateCreated {
+		t.Errorf("RestoreFactory restored states %s and %s", objs[0].State(), objs[1].State())
+	}
+	if !objs[2].IsSoftDeleted() || restored.Sweep(0) != 1 {
+		t.Error("RestoreFactory should restore soft-deleted objects")
+	}
+	label, err := restored.Intern(&TestBackupLabel{Text: "sale"})
+	if err != nil || label != objs[3] || label.GetUnderlyingObject().(*TestBackupLabel).Color != "red" {
+		t.Errorf("Intern returned %v, %v, want the restored label", label, err)
+	}
+	if used, reserved := restored.MemoryUsage(); reserved != 1<<20 || used == 0 {
+		t.Errorf("MemoryUsage = %d, %d, want the budget restored", used, reserved)
+	}
+}
+
+// TestBackupErrors tests Backup and RestoreFactory with invalid input
+func TestBackupErrors(t *testing.T) {
+	factory := NewObjectFactory()
+	obj := factory.CreateObject(&TestUnregisteredBackup{Name: "x"})
+	defer obj.Destroy()
+	if err := factory.Backup(&bytes.Buffer{}); err == nil {
+		t.Error("Backup of an object of an unregistered class should return an error")
+	}
+
+	registerPolymorphicClasses(t)
+	zoo := &TestZoo{Name: "loop"}
+	zoo.Keeper = zoo
+	cyclic := NewObjectFactory()
+	cyclic.CreateObject(zoo)
+	if err := cyclic.Backup(&bytes.Buffer{}); err == nil {
+		t.Error("Backup of a cyclic object should return an error")
+	}
+
+	if _, err := registeredClass(reflect.TypeOf(TestShopper{})); err != nil {
+		t.Fatalf("registeredClass returned error: %v", err)
+	}
+	for _, archive := range []string{
+		"not json",
+		`{"version":2,"objects":[]}`,
+		`{"version":1,"objects":[{"id":1,"object":{"$type":"unknown.Class"}}]}`,
+		`{"version":1,"objects":[{"id":1,"object":{"$type":"oop.TestShopper"},"relations":{"Purchases":[7]}}]}`,
+		`{"version":1,"objects":[{"id":1,"object":{"$type":"oop.TestShopper"},"state":9}]}`,
+	} {
+		if _, err := RestoreFactory(strings.NewReader(archive)); err == nil {
+			t.Errorf("RestoreFactory(%s) should return an error", archive)
+		}
+	}
+}
+
+// TestBackupNote is embedded by pointer in TestBackupTag
+type TestBackupNote struct {
+	Note string
+}
+
+// TestBackupTag is a test class whose promoted fields live behind a pointer
+type TestBackupTag struct {
+	Name string
+	*TestBackupNote
+}
+
+// TestBackupConcurrent tests that Backup does not race with SetFields writing
+// through a pointer the object shares with its copy; run with -race
+func TestBackupConcurrent(t *testing.T) {
+	if _, err := Register(reflect.TypeOf(TestBackupTag{})); err != nil {
+		t.Fatalf("Register returned error: %v", err)
+	}
+
+	factory := NewObjectFactory()
+	obj := factory.CreateObject(&TestBackupTag{Name: "tag", TestBackupNote: &TestBackupNote{}})
+	defer obj.Destroy()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			if err := obj.SetFields(map[string]interface{}{"Note": strings.Repeat("x", i)}); err != nil {
+				t.Errorf("SetFields returned error: %v", err)
+				return
+			}
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if err := factory.Backup(&bytes.Buffer{}); err != nil {
+			t.Fatalf("Backup returned error: %v", err)
+		}
+	}
+	<-done
+}
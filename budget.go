@@ -0,0 +1,88 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"weak"
+)
+
+// memoryBudget tracks the memory used by the objects of a factory against a reserved limit.
+type memoryBudget struct {
+	limit atomic.Int64 // Reserved bytes, 0 for no limit.
+	used  atomic.Int64 // Bytes used by live objects.
+}
+
+// reserve accounts size bytes against the budget.
+// It returns false, leaving the budget unchanged, if the reservation would exceed the limit.
+func (b *memoryBudget) reserve(size int64) bool {
+	for {
+		used := b.used.Load()
+		if limit := b.limit.Load(); limit > 0 && used+size > limit {
+			return false
+		}
+		if b.used.CompareAndSwap(used, used+size) {
+			return true
+		}
+	}
+}
+
+// release returns size bytes to the budget.
+func (b *memoryBudget) release(size int64) {
+	b.used.Add(-size)
+}
+
+// ReserveMemory sets the memory budget of the factory in bytes.
+// Once set, CreateObject and NewObject return nil instead of creating an object
+// that would exceed the budget; destroying objects frees their share again.
+// Objects are accounted by their own size (reflect.Type.Size), not by the
+// memory they reference. A budget of 0 removes the limit.
+// It returns an error if live objects already use more than the requested budget.
+// Example: err := factory.ReserveMemory(64 << 20)
+func (f *ObjectFactory) ReserveMemory(bytes int64) error {
+	if bytes < 0 {
+		return fmt.Errorf("memory budget cannot be negative")
+	}
+	if used := f.budget.used.Load(); bytes > 0 && used > bytes {
+		return fmt.Errorf("memory budget of %d bytes is below the %d bytes in use", bytes, used)
+	}
+
+	f.budget.limit.Store(bytes)
+	return nil
+}
+
+// MemoryUsage returns the bytes used by the live objects of the factory and
+// the reserved budget, 0 if there is no limit.
+func (f *ObjectFactory) MemoryUsage() (used, reserved int64) {
+	return f.budget.used.Load(), f.budget.limit.Load()
+}
+
+// wrap wraps a new object of the factory, accounting it against the memory
+// budget and adding it to the indexes of its class, see CreateIndex.
+// It returns an error if the object does not fit into the budget, cannot be
+// created or violates a unique index.
+func (f *ObjectFactory) wrap(classType reflect.Type, create func() (*Klass, error)) (*ObjectWrapper, error) {
+	size := int64(classType.Size())
+	if !f.budget.reserve(size) {
+		return nil, fmt.Errorf("creating %s would exceed the memory budget", classType)
+	}
+
+	klass, err := create()
+	if err != nil {
+		f.budget.release(size)
+		return nil, err
+	}
+	obj := &ObjectWrapper{
+		klass:    klass,
+		budget:   &f.budget,
+		reserved: size,
+		trash:    &f.trash,
+		live:     &f.live,
+	}
+	obj.klass.owner = weak.Make(obj)
+	if err := obj.indexNew(); err != nil {
+		return nil, err
+	}
+	f.live.add(obj)
+	return obj, nil
+}
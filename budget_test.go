@@ -0,0 +1,106 @@
+package oop
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+// TestReserveMemory tests the ReserveMemory and MemoryUsage methods of ObjectFactory
+func TestReserveMemory(t *testing.T) {
+	factory := NewObjectFactory()
+	size := int64(reflect.TypeOf(TestStruct{}).Size())
+
+	if err := factory.ReserveMemory(2 * size); err != nil {
+		t.Fatalf("ReserveMemory returned error: %v", err)
+	}
+
+	first := factory.CreateObject(&TestStruct{Value: 1})
+	second := factory.NewObject(reflect.TypeOf(TestStruct{}))
+	if first == nil || second == nil {
+		t.Fatal("objects within the budget were not created")
+	}
+	if used, reserved := factory.MemoryUsage(); used != 2*size || reserved != 2*size {
+		t.Errorf("MemoryUsage returned %d, %d, want %d, %d", used, reserved, 2*size, 2*size)
+	}
+
+	// Test that the budget is enforced
+	if factory.CreateObject(&TestStruct{Value: 3}) != nil {
+		t.Error("CreateObject should return nil when the budget is exceeded")
+	}
+	if _, err := factory.FromMap(reflect.TypeOf(TestStruct{}), nil); err == nil {
+		t.Error("FromMap should return error when the budget is exceeded")
+	}
+	if err := factory.ReserveMemory(size); err == nil {
+		t.Error("ReserveMemory should return error for a budget below the memory in use")
+	}
+
+	// Test that destroying objects frees their share
+	first.Destroy()
+	first.Destroy()
+	if used, _ := factory.MemoryUsage(); used != size {
+		t.Errorf("MemoryUsage returned %d after Destroy, want %d", used, size)
+	}
+	third := factory.CreateObject(&TestStruct{Value: 3})
+	if third == nil {
+		t.Fatal("CreateObject should succeed after memory was freed")
+	}
+
+	// Test removing the limit
+	if err := factory.ReserveMemory(0); err != nil {
+		t.Fatalf("ReserveMemory returned error: %v", err)
+	}
+	fourth := factory.CreateObject(&TestStruct{Value: 4})
+	if fourth == nil {
+		t.Error("CreateObject should succeed without a limit")
+	}
+	if err := factory.ReserveMemory(-1); err == nil {
+		t.Error("ReserveMemory should return error for a negative budget")
+	}
+
+	second.Destroy()
+	third.Destroy()
+	fourth.Destroy()
+	if used, _ := factory.MemoryUsage(); used != 0 {
+		t.Errorf("MemoryUsage returned %d after destroying all objects, want 0", used)
+	}
+}
+
+// testFailingAllocator is an allocator that is out of memory
+type testFailingAllocator struct{}
+
+// Alloc fails
+func (testFailingAllocator) Alloc(classType reflect.Type) unsafe.Pointer { return nil }
+
+// Free does nothing
+func (testFailingAllocator) Free(ptr unsafe.Pointer) {}
+
+// Stats returns no statistics
+func (testFailingAllocator) Stats() AllocStats { return AllocStats{} }
+
+// TestCreateObjectAllocatorFailure tests that a failing allocator is reported
+// and gives the reserved memory back
+func TestCreateObjectAllocatorFailure(t *testing.T) {
+	factory := NewObjectFactoryWithAllocator(testFailingAllocator{})
+	if err := factory.ReserveMemory(1 << 10); err != nil {
+		t.Fatalf("ReserveMemory returned error: %v", err)
+	}
+
+	if obj := factory.NewObject(reflect.TypeOf(TestStruct{})); obj != nil {
+		t.Error("NewObject should return nil when the allocator fails")
+	}
+	if _, err := factory.FromMap(reflect.TypeOf(TestStruct{}), nil); err == nil || !strings.Contains(err.Error(), "allocator returned nil") {
+		t.Errorf("FromMap returned error %v, want the allocator failure", err)
+	}
+	if used, _ := factory.MemoryUsage(); used != 0 {
+		t.Errorf("MemoryUsage returned %d after failed allocations, want 0", used)
+	}
+
+	// Test that initializers do not use the allocator
+	obj, err := factory.CreateObjectE(&TestStruct{Value: 1})
+	if err != nil {
+		t.Fatalf("CreateObjectE returned error: %v", err)
+	}
+	obj.Destroy()
+}
@@ -0,0 +1,321 @@
+package oop
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// Tags used by the canonical encoding to mark the kind of each encoded value.
+const (
+	canonicalNil     byte = 'n'
+	canonicalTrue    byte = 't'
+	canonicalFalse   byte = 'f'
+	canonicalInt     byte = 'i'
+	canonicalUint    byte = 'u'
+	canonicalFloat   byte = 'd'
+	canonicalComplex byte = 'c'
+	canonicalString  byte = 's'
+	canonicalList    byte = 'l'
+	canonicalMap     byte = 'm'
+	canonicalStruct  byte = 'S'
+	canonicalOpaque  byte = 'o'
+)
+
+var (
+	binaryMarshalerType = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// CanonicalBytes returns a deterministic byte encoding of an object.
+// Map keys are sorted, floats are normalized (-0 becomes 0, all NaNs are equal)
+// and struct fields are written by name, so two objects with the same content
+// always produce the same bytes. Only exported struct fields are encoded;
+// structs implementing encoding.BinaryMarshaler or encoding.TextMarshaler,
+// such as time.Time, are encoded by their marshaled form instead. Channels,
+// functions, unsafe pointers, cyclic graphs and other structs whose fields
+// are all unexported cannot be encoded.
+func CanonicalBytes(obj interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := &canonicalEncoder{buf: &buf, visiting: map[refKey]bool{}}
+	if err := enc.encode(reflect.ValueOf(obj)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// canonicalEncoder holds the state of a single CanonicalBytes call.
+type canonicalEncoder struct {
+	buf      *bytes.Buffer
+	visiting map[refKey]bool // Pointers, maps and slices on the current path, used to detect cycles.
+}
+
+// refKey identifies a pointer, map or slice by the memory it refers to.
+// Slices also need their length, as a slice and its prefix share an address.
+type refKey struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// newRefKey returns the refKey of a non-nil pointer, map or slice.
+func newRefKey(v reflect.Value) refKey {
+	key := refKey{ptr: v.Pointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		key.len = v.Len()
+	}
+	return key
+}
+
+// encode writes the canonical form of v to the buffer.
+func (e *canonicalEncoder) encode(v reflect.Value) error {
+	if !v.IsValid() {
+		e.buf.WriteByte(canonicalNil)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf.WriteByte(canonicalTrue)
+		} else {
+			e.buf.WriteByte(canonicalFalse)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.buf.WriteByte(canonicalInt)
+		e.writeUint64(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.buf.WriteByte(canonicalUint)
+		e.writeUint64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		e.buf.WriteByte(canonicalFloat)
+		e.writeFloat(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		e.buf.WriteByte(canonicalComplex)
+		e.writeFloat(real(v.Complex()))
+		e.writeFloat(imag(v.Complex()))
+	case reflect.String:
+		e.buf.WriteByte(canonicalString)
+		e.writeString(v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			e.buf.WriteByte(canonicalNil)
+			return nil
+		}
+		return e.visit(v, func() error { return e.encodeList(v) })
+	case reflect.Array:
+		return e.encodeList(v)
+	case reflect.Map:
+		if v.IsNil() {
+			e.buf.WriteByte(canonicalNil)
+			return nil
+		}
+		return e.visit(v, func() error { return e.encodeMap(v) })
+	case reflect.Struct:
+		return e.encodeStruct(v)
+	case reflect.Ptr:
+		if v.IsNil() {
+			e.buf.WriteByte(canonicalNil)
+			return nil
+		}
+		return e.visit(v, func() error { return e.encode(v.Elem()) })
+	case reflect.Interface:
+		if v.IsNil() {
+			e.buf.WriteByte(canonicalNil)
+			return nil
+		}
+		return e.encode(v.Elem())
+	default:
+		return fmt.Errorf("cannot encode value of kind %s", v.Kind())
+	}
+
+	return nil
+}
+
+// visit encodes a pointer, map or slice with fn, and returns an error instead
+// if it is already on the current path.
+func (e *canonicalEncoder) visit(v reflect.Value, fn func() error) error {
+	key := newRefKey(v)
+	if e.visiting[key] {
+		return fmt.Errorf("cannot encode cyclic value of type %s", v.Type())
+	}
+	e.visiting[key] = true
+	defer delete(e.visiting, key)
+	return fn()
+}
+
+// encodeList writes a slice or array element by element.
+func (e *canonicalEncoder) encodeList(v reflect.Value) error {
+	e.buf.WriteByte(canonicalList)
+	e.writeLen(v.Len())
+	for i := range v.Len() {
+		if err := e.encode(v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeMap writes a map with its entries sorted by their encoded keys.
+func (e *canonicalEncoder) encodeMap(v reflect.Value) error {
+	type entry struct {
+		key   []byte
+		value reflect.Value
+	}
+
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		keyBytes, err := e.sub(iter.Key())
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry{key: keyBytes, value: iter.Value()})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+
+	e.buf.WriteByte(canonicalMap)
+	e.writeLen(len(entries))
+	for _, en := range entries {
+		e.buf.Write(en.key)
+		if err := e.encode(en.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeStruct writes the type name followed by the exported fields sorted by
+// name, or by the marshaled form of structs that marshal themselves.
+func (e *canonicalEncoder) encodeStruct(v reflect.Value) error {
+	t := v.Type()
+	if data, ok, err := marshaledForm(v); err != nil {
+		return fmt.Errorf("cannot encode %s: %w", t, err)
+	} else if ok {
+		e.buf.WriteByte(canonicalOpaque)
+		e.writeString(t.PkgPath() + "." + t.Name())
+		e.writeString(string(data))
+		return nil
+	}
+	if opaqueStruct(t) {
+		return fmt.Errorf("cannot encode %s: its fields are unexported and it does not marshal itself", t)
+	}
+
+	names := make([]string, 0, t.NumField())
+	for i := range t.NumField() {
+		if t.Field(i).IsExported() {
+			names = append(names, t.Field(i).Name)
+		}
+	}
+	sort.Strings(names)
+
+	e.buf.WriteByte(canonicalStruct)
+	e.writeString(t.PkgPath() + "." + t.Name())
+	e.writeLen(len(names))
+	for _, name := range names {
+		e.writeString(name)
+		if err := e.encode(v.FieldByName(name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// leafStruct reports whether t is a struct that is encoded as a whole rather
+// than field by field, see CanonicalBytes.
+func leafStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	return opaqueStruct(t) || implements(t, binaryMarshalerType) || implements(t, textMarshalerType) ||
+		implements(reflect.PointerTo(t), binaryMarshalerType) || implements(reflect.PointerTo(t), textMarshalerType)
+}
+
+// opaqueStruct reports whether the struct type t has fields, but none exported.
+func opaqueStruct(t reflect.Type) bool {
+	if t.NumField() == 0 {
+		return false
+	}
+	for i := range t.NumField() {
+		if t.Field(i).IsExported() {
+			return false
+		}
+	}
+	return true
+}
+
+// marshaledForm returns the MarshalBinary or, failing that, the MarshalText
+// form of a struct, and false if it implements neither. Methods with pointer
+// receivers are called on a copy when v is not addressable.
+func marshaledForm(v reflect.Value) ([]byte, bool, error) {
+	if !v.CanInterface() || !leafStruct(v.Type()) {
+		return nil, false, nil
+	}
+	recv := v
+	if !implements(v.Type(), binaryMarshalerType) && !implements(v.Type(), textMarshalerType) {
+		if v.CanAddr() {
+			recv = v.Addr()
+		} else {
+			recv = reflect.New(v.Type())
+			recv.Elem().Set(v)
+		}
+	}
+
+	switch m := recv.Interface().(type) {
+	case encoding.BinaryMarshaler:
+		data, err := m.MarshalBinary()
+		return data, true, err
+	case encoding.TextMarshaler:
+		data, err := m.MarshalText()
+		return data, true, err
+	default:
+		return nil, false, nil
+	}
+}
+
+// sub encodes v into a separate buffer, sharing the cycle detection state.
+func (e *canonicalEncoder) sub(v reflect.Value) ([]byte, error) {
+	var buf bytes.Buffer
+	child := &canonicalEncoder{buf: &buf, visiting: e.visiting}
+	if err := child.encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeFloat writes a normalized float64.
+func (e *canonicalEncoder) writeFloat(f float64) {
+	switch {
+	case f == 0:
+		f = 0 // Folds -0 into 0.
+	case math.IsNaN(f):
+		f = math.NaN() // Folds all NaN payloads into one.
+	}
+	e.writeUint64(math.Float64bits(f))
+}
+
+// writeUint64 writes a fixed-width big-endian integer.
+func (e *canonicalEncoder) writeUint64(n uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], n)
+	e.buf.Write(b[:])
+}
+
+// writeLen writes a length prefix.
+func (e *canonicalEncoder) writeLen(n int) {
+	var b [binary.MaxVarintLen64]byte
+	e.buf.Write(b[:binary.PutUvarint(b[:], uint64(n))])
+}
+
+// writeString writes a length-prefixed string.
+func (e *canonicalEncoder) writeString(s string) {
+	e.writeLen(len(s))
+	e.buf.WriteString(s)
+}
@@ -0,0 +1,143 @@
+package oop
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+// TestCanonicalNode is a test struct used to build cyclic graphs
+type TestCanonicalNode struct {
+	Name string
+	Next *TestCanonicalNode
+}
+
+// TestCanonicalBytes tests the CanonicalBytes function
+func TestCanonicalBytes(t *testing.T) {
+	// Test that map ordering does not affect the encoding
+	a := map[string]int{"a": 1, "b": 2, "c": 3}
+	b := map[string]int{"c": 3, "b": 2, "a": 1}
+
+	aBytes, err := CanonicalBytes(a)
+	if err != nil {
+		t.Fatalf("CanonicalBytes returned error: %v", err)
+	}
+	for range 10 {
+		bBytes, err := CanonicalBytes(b)
+		if err != nil {
+			t.Fatalf("CanonicalBytes returned error: %v", err)
+		}
+		if !bytes.Equal(aBytes, bBytes) {
+			t.Fatal("CanonicalBytes is not deterministic for maps")
+		}
+	}
+
+	// Test that equal structs encode the same, pointers or not
+	dogBytes, err := CanonicalBytes(&TestDog{Name: "Buddy"})
+	if err != nil {
+		t.Fatalf("CanonicalBytes returned error: %v", err)
+	}
+	dogValueBytes, err := CanonicalBytes(TestDog{Name: "Buddy"})
+	if err != nil {
+		t.Fatalf("CanonicalBytes returned error: %v", err)
+	}
+	if !bytes.Equal(dogBytes, dogValueBytes) {
+		t.Error("CanonicalBytes should encode a pointer and its value the same")
+	}
+
+	// Test that different types with the same fields encode differently
+	catBytes, err := CanonicalBytes(&TestCat{Name: "Buddy"})
+	if err != nil {
+		t.Fatalf("CanonicalBytes returned error: %v", err)
+	}
+	if bytes.Equal(dogBytes, catBytes) {
+		t.Error("CanonicalBytes should encode different types differently")
+	}
+
+	// Test float normalization
+	zero, _ := CanonicalBytes(0.0)
+	negZero, _ := CanonicalBytes(math.Copysign(0, -1))
+	if !bytes.Equal(zero, negZero) {
+		t.Error("CanonicalBytes should encode -0 and 0 the same")
+	}
+}
+
+// TestCanonicalBytesErrors tests CanonicalBytes with values that cannot be encoded
+func TestCanonicalBytesErrors(t *testing.T) {
+	// Test with a function
+	if _, err := CanonicalBytes(func() {}); err == nil {
+		t.Error("CanonicalBytes should return error for functions")
+	}
+
+	// Test with a cycle
+	node := &TestCanonicalNode{Name: "a"}
+	node.Next = node
+	if _, err := CanonicalBytes(node); err == nil {
+		t.Error("CanonicalBytes should return error for cyclic values")
+	}
+
+	// Test with self-referencing maps and slices
+	m := map[string]interface{}{}
+	m["self"] = m
+	if _, err := CanonicalBytes(m); err == nil {
+		t.Error("CanonicalBytes should return error for cyclic maps")
+	}
+	s := make([]interface{}, 1)
+	s[0] = s
+	if _, err := CanonicalBytes(s); err == nil {
+		t.Error("CanonicalBytes should return error for cyclic slices")
+	}
+
+	// Test that a slice holding its own prefix is not a cycle
+	buf := make([]interface{}, 2)
+	buf[1] = buf[:1]
+	if _, err := CanonicalBytes(buf); err != nil {
+		t.Errorf("CanonicalBytes returned error for a slice holding its prefix: %v", err)
+	}
+
+	// Test that shared (non-cyclic) pointers are fine
+	shared := &TestCanonicalNode{Name: "shared"}
+	pair := []*TestCanonicalNode{shared, shared}
+	if _, err := CanonicalBytes(pair); err != nil {
+		t.Errorf("CanonicalBytes returned error for shared pointers: %v", err)
+	}
+}
+
+// TestCanonicalEvent is a test struct holding a timestamp
+type TestCanonicalEvent struct {
+	Name string
+	At   time.Time
+}
+
+// TestCanonicalSecret is a test struct whose fields are all unexported
+type TestCanonicalSecret struct {
+	value int
+}
+
+// TestCanonicalBytesOpaque tests structs encoded by their marshaled form
+func TestCanonicalBytesOpaque(t *testing.T) {
+	at := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	first, err := CanonicalBytes(TestCanonicalEvent{Name: "launch", At: at})
+	if err != nil {
+		t.Fatalf("CanonicalBytes returned error: %v", err)
+	}
+	later, err := CanonicalBytes(TestCanonicalEvent{Name: "launch", At: at.Add(time.Second)})
+	if err != nil {
+		t.Fatalf("CanonicalBytes returned error: %v", err)
+	}
+	if bytes.Equal(first, later) {
+		t.Error("CanonicalBytes should tell timestamps apart")
+	}
+	same, err := CanonicalBytes(&TestCanonicalEvent{Name: "launch", At: at})
+	if err != nil || !bytes.Equal(first, same) {
+		t.Errorf("CanonicalBytes returned %v for the same timestamp, want the same bytes", err)
+	}
+
+	if _, err := CanonicalBytes(TestCanonicalSecret{value: 1}); err == nil {
+		t.Error("CanonicalBytes should return error for a struct with only unexported fields")
+	}
+	if _, err := CanonicalBytes(struct{}{}); err != nil {
+		t.Errorf("CanonicalBytes returned error for an empty struct: %v", err)
+	}
+}
@@ -0,0 +1,280 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// castKind is how Cast converts objects of one type to a target type.
+type castKind uint8
+
+const (
+	castNone            castKind = iota // The cast is not possible.
+	castIdentity                        // The object is returned as it is.
+	castAddress                         // A pointer to a copy of the value is returned.
+	castConvert                         // The object is converted to the target type.
+	castEmbeddedPointer                 // A pointer to the embedded struct is returned.
+	castEmbeddedValue                   // A copy of the embedded struct is returned.
+)
+
+// castPlan is how Cast converts objects of one type to a target type.
+type castPlan struct {
+	kind  castKind
+	index []int // Index sequence of the embedded struct, if it is reached through values only.
+}
+
+// castTargets holds the cast plans for one concrete type.
+type castTargets struct {
+	plans   sync.Map                  // Target reflect.Type -> *castPlan.
+	info    atomic.Pointer[ClassInfo] // Registered class of the type, once it was found.
+	missing atomic.Uint64             // Registry version + 1 at which the type was not registered.
+}
+
+// castCache maps concrete types to their cast plans. Both levels are keyed by
+// reflect.Type, so lookups do not allocate, and plans depend only on the
+// types, so they never go stale.
+var castCache sync.Map // reflect.Type -> *castTargets
+
+// castTargetsFor returns the cast plans of a concrete type.
+func castTargetsFor(t reflect.Type) *castTargets {
+	if targets, ok := castCache.Load(t); ok {
+		return targets.(*castTargets)
+	}
+	targets, _ := castCache.LoadOrStore(t, &castTargets{})
+	return targets.(*castTargets)
+}
+
+// plan returns how objects of type t are cast to the target type.
+func (c *castTargets) plan(t, targetType reflect.Type) *castPlan {
+	if plan, ok := c.plans.Load(targetType); ok {
+		return plan.(*castPlan)
+	}
+	plan, _ := c.plans.LoadOrStore(targetType, planCast(t, targetType))
+	return plan.(*castPlan)
+}
+
+// classInfo returns the registered class of type t, if any. Classes cannot be
+// unregistered, so a class that was found is remembered, and a type that was
+// not found is only looked up again after another class is registered.
+func (c *castTargets) classInfo(t reflect.Type) (*ClassInfo, bool) {
+	if info := c.info.Load(); info != nil {
+		return info, true
+	}
+	version := registry.version.Load()
+	if c.missing.Load() == version+1 {
+		return nil, false
+	}
+
+	info, ok := LookupType(t)
+	if ok {
+		c.info.Store(info)
+	} else {
+		c.missing.Store(version + 1)
+	}
+	return info, ok
+}
+
+// planCast decides how objects of type t are cast to the target type.
+func planCast(t, targetType reflect.Type) *castPlan {
+	// For class types, resolve structs promoted through embedding,
+	// e.g. a *Dog embedding Animal casts to *Animal
+	if targetType.Kind() != reflect.Interface {
+		switch {
+		case t == targetType:
+			return &castPlan{kind: castIdentity}
+		case t.AssignableTo(targetType):
+			return &castPlan{kind: castConvert}
+		case targetType.Kind() == reflect.Ptr && embeds(classElem(t), targetType.Elem()):
+			return &castPlan{kind: castEmbeddedPointer, index: embeddedIndex(classElem(t), targetType.Elem())}
+		case embeds(classElem(t), targetType):
+			return &castPlan{kind: castEmbeddedValue, index: embeddedIndex(classElem(t), targetType)}
+		}
+		return &castPlan{kind: castNone}
+	}
+
+	switch {
+	case implements(t, targetType):
+		return &castPlan{kind: castIdentity}
+	case t.Kind() == reflect.Ptr && implements(t.Elem(), targetType):
+		return &castPlan{kind: castIdentity}
+	case t.Kind() != reflect.Ptr && implements(reflect.PointerTo(t), targetType):
+		// Only the address of a value has the pointer methods, and the
+		// original value might not be addressable, so a copy is addressed.
+		return &castPlan{kind: castAddress}
+	case t.AssignableTo(targetType):
+		return &castPlan{kind: castConvert}
+	}
+	return &castPlan{kind: castNone}
+}
+
+// embeddedIndex returns the index sequence of the parent struct embedded in
+// the struct type t, in the order superReceiver searches, if it is embedded
+// by value all the way. It returns nil if a struct embedded by pointer could
+// be found first, since that depends on which pointers are nil.
+func embeddedIndex(t, parent reflect.Type) []int {
+	queue := [][]int{nil}
+	for len(queue) > 0 {
+		index := queue[0]
+		queue = queue[1:]
+
+		st := t
+		if len(index) > 0 {
+			st = t.FieldByIndex(index).Type
+		}
+		for i := range st.NumField() {
+			sf := st.Field(i)
+			if !sf.Anonymous || !sf.IsExported() {
+				continue
+			}
+			switch {
+			case sf.Type == parent:
+				return append(slices.Clone(index), i)
+			case sf.Type.Kind() == reflect.Ptr && sf.Type.Elem().Kind() == reflect.Struct:
+				return nil // Embedded by pointer, resolved when casting.
+			case sf.Type.Kind() == reflect.Struct:
+				queue = append(queue, append(slices.Clone(index), i))
+			}
+		}
+	}
+	return nil
+}
+
+// apply casts obj according to the plan.
+func (p *castPlan) apply(obj any, targetType reflect.Type) any {
+	switch p.kind {
+	case castIdentity:
+		return obj
+	case castAddress:
+		v := reflect.ValueOf(obj)
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		return ptr.Interface()
+	case castConvert:
+		return reflect.ValueOf(obj).Convert(targetType).Interface()
+	case castEmbeddedPointer, castEmbeddedValue:
+		parent := targetType
+		if p.kind == castEmbeddedPointer {
+			parent = targetType.Elem()
+		}
+		embedded, ok := p.embedded(reflect.ValueOf(obj), parent)
+		if !ok {
+			return nil
+		}
+		if p.kind == castEmbeddedValue {
+			embedded = embedded.Elem()
+		}
+		return embedded.Interface()
+	}
+	return nil
+}
+
+// embedded returns a pointer to the parent struct embedded in v, like promoted.
+func (p *castPlan) embedded(v reflect.Value, parent reflect.Type) (reflect.Value, bool) {
+	if p.index == nil {
+		return promoted(v, parent)
+	}
+
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	} else {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		v = ptr.Elem()
+	}
+	return v.FieldByIndex(p.index).Addr(), true
+}
+
+// CastE casts an object to a different type like Cast, and returns an error
+// telling why the cast is not possible instead of nil.
+// Example: reader, err := oop.CastE(dog, reflect.TypeOf((*io.Reader)(nil)).Elem())
+func CastE(obj any, targetType reflect.Type) (any, error) {
+	if targetType == nil {
+		return nil, fmt.Errorf("targetType cannot be nil")
+	}
+	t := reflect.TypeOf(obj)
+	if t == nil {
+		return nil, fmt.Errorf("cannot cast nil to %s", targetType)
+	}
+
+	result, plan := cast(obj, targetType)
+	if result != nil {
+		return result, nil
+	}
+	switch {
+	case plan.kind != castNone:
+		return nil, fmt.Errorf("cannot cast %s to %s through a nil pointer", t, targetType)
+	case targetType.Kind() == reflect.Interface:
+		return nil, fmt.Errorf("%s does not implement %s: %s", t, targetType, missingMethod(t, targetType))
+	}
+	return nil, fmt.Errorf("%s is not assignable to %s and does not embed it", t, targetType)
+}
+
+// cast casts obj to the target type, firing the HookCast hooks of its class
+// on success, and returns the plan that was applied.
+func cast(obj any, targetType reflect.Type) (any, *castPlan) {
+	t := reflect.TypeOf(obj)
+	if t == nil || targetType == nil {
+		return nil, &castPlan{kind: castNone}
+	}
+
+	targets := castTargetsFor(t)
+	plan := targets.plan(t, targetType)
+	result := plan.apply(obj, targetType)
+	if result != nil {
+		targets.fireCast(t, result)
+	}
+	return result, plan
+}
+
+// fireCast fires the HookCast hooks of the class of t, if it is registered,
+// with the result of a successful cast.
+func (c *castTargets) fireCast(t reflect.Type, result any) {
+	if info, ok := c.classInfo(t); ok {
+		info.fireHook(HookCast, result)
+	}
+}
+
+// missingMethod describes the first method of the interface type iface that
+// t lacks, looking at the methods of *t for non-pointer types since Cast
+// addresses values.
+func missingMethod(t, iface reflect.Type) string {
+	if t.Kind() != reflect.Ptr {
+		t = reflect.PointerTo(t)
+	}
+	for i := range iface.NumMethod() {
+		want := iface.Method(i)
+		got, ok := t.MethodByName(want.Name)
+		if !ok || want.PkgPath != "" {
+			return "missing method " + want.Name
+		}
+		if !sameSignature(got.Type, want.Type) {
+			return "wrong type for method " + want.Name
+		}
+	}
+	return "not assignable"
+}
+
+// sameSignature reports whether the method type of a concrete type, whose
+// first parameter is the receiver, matches the method type of an interface.
+func sameSignature(method, want reflect.Type) bool {
+	if method.NumIn()-1 != want.NumIn() || method.NumOut() != want.NumOut() || method.IsVariadic() != want.IsVariadic() {
+		return false
+	}
+	for i := range want.NumIn() {
+		if method.In(i+1) != want.In(i) {
+			return false
+		}
+	}
+	for i := range want.NumOut() {
+		if method.Out(i) != want.Out(i) {
+			return false
+		}
+	}
+	return true
+}
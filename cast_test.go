@@ -0,0 +1,165 @@
+package oop
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+// TestCastPlan tests the cached cast decisions
+func TestCastPlan(t *testing.T) {
+	animalType := reflect.TypeOf((*TestAnimal)(nil)).Elem()
+	mammalType := reflect.TypeOf(TestMammal{})
+
+	tests := []struct {
+		name       string
+		obj        any
+		targetType reflect.Type
+		want       castKind
+	}{
+		{"pointer to interface", &TestDog{}, animalType, castIdentity},
+		{"value to interface", TestDog{}, animalType, castAddress},
+		{"unrelated interface", 42, animalType, castNone},
+		{"same type", &TestDog{}, reflect.TypeOf(&TestDog{}), castIdentity},
+		{"embedded pointer", &TestKennel{}, reflect.PointerTo(mammalType), castEmbeddedPointer},
+		{"embedded value", TestKennel{}, mammalType, castEmbeddedValue},
+		{"unrelated struct", &TestDog{}, mammalType, castNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objType := reflect.TypeOf(tt.obj)
+			targets := castTargetsFor(objType)
+			plan := targets.plan(objType, tt.targetType)
+			if plan.kind != tt.want {
+				t.Errorf("kind = %d, want %d", plan.kind, tt.want)
+			}
+			if targets.plan(objType, tt.targetType) != plan {
+				t.Error("plan should be cached")
+			}
+			if castTargetsFor(objType) != targets {
+				t.Error("castTargetsFor should return the same plans for a type")
+			}
+		})
+	}
+
+	// Test that value embedding is resolved by index and pointer embedding at cast time
+	kennelType := reflect.TypeOf(&TestKennel{})
+	if index := castTargetsFor(kennelType).plan(kennelType, reflect.PointerTo(mammalType)).index; !reflect.DeepEqual(index, []int{0, 0}) {
+		t.Errorf("index = %v, want [0 0]", index)
+	}
+	puppyType := reflect.TypeOf(&TestPuppy{})
+	if plan := castTargetsFor(puppyType).plan(puppyType, reflect.TypeOf(&TestToy{})); plan.kind != castEmbeddedPointer || plan.index != nil {
+		t.Errorf("plan = %+v, want an embedded pointer without index", plan)
+	}
+	if Cast(&TestPuppy{}, reflect.TypeOf(&TestToy{})) != nil {
+		t.Error("Cast through a nil embedded pointer should return nil")
+	}
+
+	// Test that cached casts give the same results
+	kennel := &TestKennel{Name: "Rex"}
+	for range 2 {
+		mammal, ok := Cast(kennel, reflect.PointerTo(mammalType)).(*TestMammal)
+		if !ok || mammal != &kennel.TestMammal {
+			t.Errorf("Cast returned %v, want a pointer into the kennel", mammal)
+		}
+		if _, ok := Cast(TestDog{Name: "Rex"}, animalType).(*TestDog); !ok {
+			t.Error("Cast should address a copy of the value")
+		}
+	}
+
+	// Test with nil
+	if Cast(nil, animalType) != nil {
+		t.Error("Cast of nil should return nil")
+	}
+}
+
+// TestCastE tests that CastE explains failed casts
+func TestCastE(t *testing.T) {
+	readerType := reflect.TypeOf((*io.Reader)(nil)).Elem()
+	stringerType := reflect.TypeOf((*interface{ Sound() int })(nil)).Elem()
+	animalType := reflect.TypeOf((*TestAnimal)(nil)).Elem()
+
+	tests := []struct {
+		name       string
+		obj        any
+		targetType reflect.Type
+		want       string
+	}{
+		{"missing method", &TestDog{}, readerType, "*oop.TestDog does not implement io.Reader: missing method Read"},
+		{"wrong method type", TestDog{}, stringerType, "oop.TestDog does not implement interface { Sound() int }: wrong type for method Sound"},
+		{"unrelated struct", &TestDog{}, reflect.TypeOf(TestMammal{}), "*oop.TestDog is not assignable to oop.TestMammal and does not embed it"},
+		{"nil embedded pointer", &TestPuppy{}, reflect.TypeOf(&TestToy{}), "cannot cast *oop.TestPuppy to *oop.TestToy through a nil pointer"},
+		{"nil object", nil, animalType, "cannot cast nil to oop.TestAnimal"},
+		{"nil target type", &TestDog{}, nil, "targetType cannot be nil"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := CastE(tt.obj, tt.targetType)
+			if result != nil || err == nil || err.Error() != tt.want {
+				t.Errorf("CastE returned %v, %v, want error %q", result, err, tt.want)
+			}
+		})
+	}
+
+	// Test a successful cast
+	dog := &TestDog{Name: "Rex"}
+	if result, err := CastE(dog, animalType); err != nil || result != dog {
+		t.Errorf("CastE returned %v, %v, want the dog", result, err)
+	}
+	if Cast(dog, nil) != nil {
+		t.Error("Cast with a nil target type should return nil")
+	}
+}
+
+// TestCastAllocations tests that cached casts to interfaces do not allocate
+func TestCastAllocations(t *testing.T) {
+	animalType := reflect.TypeOf((*TestAnimal)(nil)).Elem()
+	var dog any = &TestDog{Name: "Rex"}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if Cast(dog, animalType) == nil {
+			t.Fatal("Cast failed")
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Cast allocated %v times, want 0", allocs)
+	}
+}
+
+// castBenchmarks are the casts measured by the benchmarks.
+var castBenchmarks = []struct {
+	name       string
+	obj        any
+	targetType reflect.Type
+}{
+	{"interface", &TestDog{Name: "Rex"}, reflect.TypeOf((*TestAnimal)(nil)).Elem()},
+	{"embedded", &TestKennel{Name: "Rex"}, reflect.TypeOf(&TestMammal{})},
+}
+
+// BenchmarkCast measures casts with cached plans
+func BenchmarkCast(b *testing.B) {
+	for _, bm := range castBenchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				Cast(bm.obj, bm.targetType)
+			}
+		})
+	}
+}
+
+// BenchmarkCastUncached measures casts that are planned on every call
+func BenchmarkCastUncached(b *testing.B) {
+	for _, bm := range castBenchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if planCast(reflect.TypeOf(bm.obj), bm.targetType).apply(bm.obj, bm.targetType) != nil {
+					LookupType(reflect.TypeOf(bm.obj))
+				}
+			}
+		})
+	}
+}
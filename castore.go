@@ -0,0 +1,365 @@
+package oop
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Hash is the content hash of an object stored in a CAStore.
+// It is the SHA-256 digest of the object's type, the canonical encoding of its
+// own fields and the paths and hashes of its struct sub-objects, so equal
+// content always has the same hash.
+type Hash [sha256.Size]byte
+
+// String returns the hash as a hex string.
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// IsZero reports whether the hash is the zero value.
+func (h Hash) IsZero() bool {
+	return h == Hash{}
+}
+
+// CAStore is a content-addressable object store.
+// Objects are keyed by the hash of their canonical encoding, and every struct
+// reachable from a stored object is stored as its own node, so identical
+// sub-objects shared by many versions of a graph are kept only once. Structs
+// encoded as a whole by CanonicalBytes, such as time.Time, are content of the
+// object holding them instead.
+// Put stores a deep copy of the object and Get returns a new copy, so stored
+// content cannot change after Put.
+type CAStore struct {
+	mu    sync.RWMutex
+	nodes map[Hash]*casNode
+}
+
+// casNode is a single stored object together with the hashes of its sub-objects.
+type casNode struct {
+	value    interface{}     // Deep copy of the stored object.
+	own      Hash            // Hash of the object's own fields, sub-objects excluded.
+	children map[string]Hash // Hashes of struct sub-objects keyed by their path segment.
+}
+
+// NewCAStore creates a new, empty CAStore.
+func NewCAStore() *CAStore {
+	return &CAStore{
+		nodes: map[Hash]*casNode{},
+	}
+}
+
+// Put stores a deep copy of an object and of all its struct sub-objects.
+// It returns the hash of the object, which can be used to retrieve it with Get.
+// Storing an object whose content is already present is a no-op.
+// Cyclic graphs cannot be stored.
+func (s *CAStore) Put(obj interface{}) (Hash, error) {
+	if obj == nil {
+		return Hash{}, fmt.Errorf("cannot store a nil object")
+	}
+	copied := Clone(obj) // Outside the lock; the copy cannot change while it is stored.
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.put(reflect.ValueOf(copied), map[refKey]bool{})
+}
+
+// Get returns a copy of the object stored under the given hash.
+func (s *CAStore) Get(h Hash) (interface{}, bool) {
+	s.mu.RLock()
+	node, ok := s.nodes[h]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, false
+	}
+	return Clone(node.value), true
+}
+
+// Has reports whether an object with the given hash is stored.
+func (s *CAStore) Has(h Hash) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.nodes[h]
+	return ok
+}
+
+// Len returns the number of distinct objects in the store, sub-objects included.
+func (s *CAStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.nodes)
+}
+
+// put stores v and its sub-objects, and returns the hash of v. Sub-objects are
+// stored first and v is hashed from their hashes, so every object is encoded
+// once. visiting holds the pointers on the current path, to report cycles.
+// The caller must hold the write lock.
+func (s *CAStore) put(v reflect.Value, visiting map[refKey]bool) (Hash, error) {
+	if !v.IsValid() {
+		return Hash{}, fmt.Errorf("cannot store a nil object")
+	}
+	if p := casPointer(v); p.IsValid() {
+		key := newRefKey(p)
+		if visiting[key] {
+			return Hash{}, fmt.Errorf("cannot store cyclic value of type %s", p.Type())
+		}
+		visiting[key] = true
+		defer delete(visiting, key)
+	}
+
+	own, childValues := casSplit(v)
+	ownData, err := CanonicalBytes(own)
+	if err != nil {
+		return Hash{}, err
+	}
+
+	children := make(map[string]Hash, len(childValues))
+	collisions := map[string][]Hash{}
+	for _, child := range childValues {
+		childHash, err := s.put(child.value, visiting)
+		if err != nil {
+			return Hash{}, err
+		}
+		collisions[child.path] = append(collisions[child.path], childHash)
+	}
+	for path, hashes := range collisions {
+		if len(hashes) == 1 {
+			children[path] = hashes[0]
+			continue
+		}
+		// Map keys with the same text, e.g. several NaN keys, are told apart
+		// by the order of their hashes.
+		sort.Slice(hashes, func(i, j int) bool { return bytes.Compare(hashes[i][:], hashes[j][:]) < 0 })
+		for i, h := range hashes {
+			children[fmt.Sprintf("%s#%d", path, i)] = h
+		}
+	}
+
+	h := casHash(casIndirect(v), ownData, children)
+	if _, ok := s.nodes[h]; !ok {
+		s.nodes[h] = &casNode{
+			value:    v.Interface(),
+			own:      Hash(sha256.Sum256(ownData)),
+			children: children,
+		}
+	}
+	return h, nil
+}
+
+// casHash returns the hash of an object from its type, its own content and
+// the hashes of its sub-objects.
+func casHash(v reflect.Value, ownData []byte, children map[string]Hash) Hash {
+	paths := make([]string, 0, len(children))
+	for path := range children {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var buf bytes.Buffer
+	enc := &canonicalEncoder{buf: &buf}
+	if v.IsValid() && v.Kind() == reflect.Struct {
+		enc.writeString(v.Type().PkgPath() + "." + v.Type().Name())
+	} else {
+		enc.writeString("")
+	}
+	enc.writeString(string(ownData))
+	enc.writeLen(len(paths))
+	for _, path := range paths {
+		h := children[path]
+		enc.writeString(path)
+		buf.Write(h[:])
+	}
+	return Hash(sha256.Sum256(buf.Bytes()))
+}
+
+// casChild is a struct sub-object found by casSplit.
+type casChild struct {
+	path  string        // Field name, with "[i]" or "[key]" appended for collection elements.
+	value reflect.Value // The sub-object.
+}
+
+// casSplit separates v into its own content and the struct sub-objects directly
+// reachable from it. Sub-objects are keyed by field name, with "[i]" or "[key]"
+// appended for collection elements; map keys with the same text share a path,
+// see put. Collections of sub-objects contribute only their length to the own
+// content.
+func casSplit(v reflect.Value) (interface{}, []casChild) {
+	s := casIndirect(v)
+	if !s.IsValid() || !casIsStruct(s.Type()) {
+		return v.Interface(), nil
+	}
+
+	own := map[string]interface{}{}
+	var children []casChild
+	t := s.Type()
+	for i := range t.NumField() {
+		if !t.Field(i).IsExported() {
+			continue
+		}
+		name := t.Field(i).Name
+		field := casIndirect(s.Field(i))
+		if !field.IsValid() {
+			own[name] = nil
+			continue
+		}
+
+		switch {
+		case casIsStruct(field.Type()):
+			children = append(children, casChild{path: name, value: s.Field(i)})
+		case (field.Kind() == reflect.Slice || field.Kind() == reflect.Array) && casIsStruct(field.Type().Elem()):
+			own[name] = field.Len()
+			for j := range field.Len() {
+				if elem := casIndirect(field.Index(j)); elem.IsValid() {
+					children = append(children, casChild{path: fmt.Sprintf("%s[%d]", name, j), value: field.Index(j)})
+				}
+			}
+		case field.Kind() == reflect.Map && casIsStruct(field.Type().Elem()):
+			own[name] = field.Len()
+			iter := field.MapRange()
+			for iter.Next() {
+				if elem := casIndirect(iter.Value()); elem.IsValid() {
+					children = append(children, casChild{path: fmt.Sprintf("%s[%v]", name, iter.Key().Interface()), value: iter.Value()})
+				}
+			}
+		default:
+			own[name] = field.Interface()
+		}
+	}
+	return own, children
+}
+
+// casIsStruct reports whether t is a struct or a pointer to a struct stored
+// as its own node. Structs encoded as a whole, such as time.Time, are content.
+func casIsStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !leafStruct(t)
+}
+
+// casPointer returns v, or the value held by the interface v, if it is a
+// non-nil pointer, and an invalid value otherwise.
+func casPointer(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		return v
+	}
+	return reflect.Value{}
+}
+
+// casIndirect follows pointers and interfaces, returning an invalid value for nil.
+func casIndirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// ChangeKind describes how a sub-object differs between two snapshots.
+type ChangeKind int
+
+const (
+	ChangeAdded    ChangeKind = iota // The sub-object exists only in the new snapshot.
+	ChangeRemoved                    // The sub-object exists only in the old snapshot.
+	ChangeModified                   // The sub-object's own fields differ.
+)
+
+// String returns the name of the change kind.
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "modified"
+	default:
+		return fmt.Sprintf("ChangeKind(%d)", int(k))
+	}
+}
+
+// Change is a single difference found by DiffSnapshots.
+type Change struct {
+	Path string     // Dotted path of the sub-object, empty for the root.
+	Kind ChangeKind // Kind of change.
+	Old  Hash       // Hash in the old snapshot, zero when added.
+	New  Hash       // Hash in the new snapshot, zero when removed.
+}
+
+// DiffSnapshots compares two object graphs stored in the CAStore.
+// It walks both hash trees and descends only into sub-objects whose hashes
+// differ, so unchanged parts of large graphs are skipped without inspection.
+// Changes are returned sorted by path.
+func (s *CAStore) DiffSnapshots(a, b Hash) ([]Change, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	changes := []Change{}
+	if err := s.diff("", a, b, &changes); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes, nil
+}
+
+// diff appends the changes between the nodes a and b to changes.
+// The caller must hold the read lock.
+func (s *CAStore) diff(path string, a, b Hash, changes *[]Change) error {
+	if a == b {
+		return nil
+	}
+
+	nodeA, ok := s.nodes[a]
+	if !ok {
+		return fmt.Errorf("hash %s not found", a)
+	}
+	nodeB, ok := s.nodes[b]
+	if !ok {
+		return fmt.Errorf("hash %s not found", b)
+	}
+
+	if nodeA.own != nodeB.own {
+		*changes = append(*changes, Change{Path: path, Kind: ChangeModified, Old: a, New: b})
+	}
+
+	for key, childA := range nodeA.children {
+		childB, ok := nodeB.children[key]
+		if !ok {
+			*changes = append(*changes, Change{Path: casJoin(path, key), Kind: ChangeRemoved, Old: childA})
+			continue
+		}
+		if err := s.diff(casJoin(path, key), childA, childB, changes); err != nil {
+			return err
+		}
+	}
+
+	for key, childB := range nodeB.children {
+		if _, ok := nodeA.children[key]; !ok {
+			*changes = append(*changes, Change{Path: casJoin(path, key), Kind: ChangeAdded, New: childB})
+		}
+	}
+
+	return nil
+}
+
+// casJoin appends a path segment to a dotted path.
+func casJoin(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
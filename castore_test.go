@@ -0,0 +1,248 @@
+package oop
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestCASOwner is a test struct with struct sub-objects
+type TestCASOwner struct {
+	Name string
+	Dog  *TestDog
+	Cats []TestCat
+}
+
+// TestCAStorePutGet tests the Put and Get methods of CAStore
+func TestCAStorePutGet(t *testing.T) {
+	store := NewCAStore()
+
+	owner := &TestCASOwner{
+		Name: "Alice",
+		Dog:  &TestDog{Name: "Buddy"},
+		Cats: []TestCat{{Name: "Whiskers"}},
+	}
+
+	h, err := store.Put(owner)
+	if err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	if h.IsZero() {
+		t.Fatal("Put returned zero hash")
+	}
+
+	// Owner, dog and cat are stored
+	if store.Len() != 3 {
+		t.Errorf("Len returned %d, want 3", store.Len())
+	}
+
+	got, ok := store.Get(h)
+	if !ok {
+		t.Fatal("Get did not find stored object")
+	}
+	if got == owner || !reflect.DeepEqual(got, owner) {
+		t.Errorf("Get returned %v, want a copy of %v", got, owner)
+	}
+
+	// Test that mutating the object or a copy after Put leaves the store unchanged
+	owner.Dog.Name = "Rex"
+	got.(*TestCASOwner).Cats[0].Name = "Felix"
+	if again, _ := store.Get(h); again.(*TestCASOwner).Dog.Name != "Buddy" || again.(*TestCASOwner).Cats[0].Name != "Whiskers" {
+		t.Errorf("Get returned %+v after mutations, want the stored content", again)
+	}
+	owner.Dog.Name = "Buddy"
+
+	// The same content stored twice has the same hash
+	h2, err := store.Put(&TestCASOwner{
+		Name: "Alice",
+		Dog:  &TestDog{Name: "Buddy"},
+		Cats: []TestCat{{Name: "Whiskers"}},
+	})
+	if err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	if h2 != h {
+		t.Error("Put returned different hashes for equal content")
+	}
+	if store.Len() != 3 {
+		t.Errorf("Len returned %d after storing duplicate, want 3", store.Len())
+	}
+
+	// Test with missing hash
+	if _, ok := store.Get(Hash{}); ok {
+		t.Error("Get should not find zero hash")
+	}
+
+	// Test with nil object
+	if _, err := store.Put(nil); err == nil {
+		t.Error("Put should return error for nil object")
+	}
+
+	// Test with a cycle
+	node := &TestCanonicalNode{Name: "a"}
+	node.Next = node
+	if _, err := store.Put(node); err == nil {
+		t.Error("Put should return error for cyclic objects")
+	}
+}
+
+// TestCAStoreSharing tests that identical sub-objects are stored once
+func TestCAStoreSharing(t *testing.T) {
+	store := NewCAStore()
+
+	if _, err := store.Put(&TestCASOwner{Name: "Alice", Dog: &TestDog{Name: "Buddy"}}); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	if _, err := store.Put(&TestCASOwner{Name: "Bob", Dog: &TestDog{Name: "Buddy"}}); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+
+	// Two owners and one shared dog
+	if store.Len() != 3 {
+		t.Errorf("Len returned %d, want 3", store.Len())
+	}
+
+	dogHash, err := store.Put(&TestDog{Name: "Buddy"})
+	if err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	if !store.Has(dogHash) {
+		t.Error("Has returned false for shared sub-object")
+	}
+}
+
+// TestCAStoreDiffSnapshots tests the DiffSnapshots method of CAStore
+func TestCAStoreDiffSnapshots(t *testing.T) {
+	store := NewCAStore()
+
+	a, err := store.Put(&TestCASOwner{
+		Name: "Alice",
+		Dog:  &TestDog{Name: "Buddy"},
+		Cats: []TestCat{{Name: "Whiskers"}},
+	})
+	if err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+
+	b, err := store.Put(&TestCASOwner{
+		Name: "Alice",
+		Dog:  &TestDog{Name: "Rex"},
+		Cats: []TestCat{{Name: "Whiskers"}, {Name: "Felix"}},
+	})
+	if err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+
+	changes, err := store.DiffSnapshots(a, b)
+	if err != nil {
+		t.Fatalf("DiffSnapshots returned error: %v", err)
+	}
+
+	want := []struct {
+		path string
+		kind ChangeKind
+	}{
+		{"", ChangeModified}, // Cats length changed
+		{"Cats[1]", ChangeAdded},
+		{"Dog", ChangeModified},
+	}
+
+	if len(changes) != len(want) {
+		t.Fatalf("DiffSnapshots returned %d changes, want %d: %v", len(changes), len(want), changes)
+	}
+	for i, w := range want {
+		if changes[i].Path != w.path || changes[i].Kind != w.kind {
+			t.Errorf("change %d is %s %q, want %s %q", i, changes[i].Kind, changes[i].Path, w.kind, w.path)
+		}
+	}
+
+	// Test that identical snapshots have no changes
+	changes, err = store.DiffSnapshots(a, a)
+	if err != nil {
+		t.Fatalf("DiffSnapshots returned error: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("DiffSnapshots returned %d changes for identical snapshots, want 0", len(changes))
+	}
+
+	// Test with unknown hash
+	if _, err := store.DiffSnapshots(a, Hash{}); err == nil {
+		t.Error("DiffSnapshots should return error for unknown hash")
+	}
+}
+
+// TestCASKennel is a test struct holding sub-objects in a map
+type TestCASKennel struct {
+	Dogs map[float64]*TestDog
+}
+
+// TestCAStoreMapKeys tests map keys with the same text, such as NaN keys
+func TestCAStoreMapKeys(t *testing.T) {
+	store := NewCAStore()
+
+	kennel := &TestCASKennel{Dogs: map[float64]*TestDog{
+		math.NaN(): {Name: "Rex"},
+		1:          {Name: "Max"},
+	}}
+	kennel.Dogs[math.NaN()] = &TestDog{Name: "Buddy"}
+
+	a, err := store.Put(kennel)
+	if err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	if store.Len() != 4 {
+		t.Errorf("Len returned %d, want the kennel and 3 dogs", store.Len())
+	}
+
+	// Test that the hash does not depend on map iteration order
+	for range 10 {
+		if h, _ := store.Put(kennel); h != a {
+			t.Fatal("Put returned different hashes for the same content")
+		}
+	}
+
+	kennel.Dogs[1] = &TestDog{Name: "Bolt"}
+	b, _ := store.Put(kennel)
+	changes, err := store.DiffSnapshots(a, b)
+	if err != nil {
+		t.Fatalf("DiffSnapshots returned error: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Path != "Dogs[1]" || changes[0].Kind != ChangeModified {
+		t.Errorf("DiffSnapshots returned %v, want Dogs[1] modified", changes)
+	}
+}
+
+// TestCASVisit is a test struct holding a timestamp
+type TestCASVisit struct {
+	Dog *TestDog
+	At  time.Time
+}
+
+// TestCAStoreTimestamps tests that timestamps are stored as content
+func TestCAStoreTimestamps(t *testing.T) {
+	store := NewCAStore()
+
+	first := &TestCASVisit{Dog: &TestDog{Name: "Buddy"}, At: time.Unix(0, 0).UTC()}
+	later := &TestCASVisit{Dog: &TestDog{Name: "Buddy"}, At: time.Unix(1000, 0).UTC()}
+	h1, err := store.Put(first)
+	if err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	h2, err := store.Put(later)
+	if err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	if h1 == h2 {
+		t.Fatal("Put returned the same hash for different timestamps")
+	}
+
+	got, ok := store.Get(h2)
+	if !ok || got.(*TestCASVisit).At.Unix() != 1000 {
+		t.Errorf("Get returned %v, %v, want the visit at 1000", got, ok)
+	}
+	// Visits and the shared dog are stored, but not the timestamps
+	if store.Len() != 3 {
+		t.Errorf("Len returned %d, want 3", store.Len())
+	}
+}
@@ -0,0 +1,175 @@
+package oop
+
+import (
+	"reflect"
+	"weak"
+)
+
+// Cloneable is implemented by classes that copy themselves. Clone and
+// ObjectWrapper.Clone call CloneObject instead of copying the value field by
+// field; the result must have the type of the receiver.
+type Cloneable interface {
+	CloneObject() any
+}
+
+var cloneableType = reflect.TypeOf((*Cloneable)(nil)).Elem()
+
+// Clone returns a deep copy of obj. Pointers, interfaces, slices, arrays and
+// maps are followed and copied, and values shared within the graph, including
+// cycles, are shared in the copy as well. Exported struct fields are copied
+// deeply; unexported fields are copied as they are. Values implementing
+// Cloneable copy themselves. Channels and functions are shared.
+// Example: copy := oop.Clone(dog).(*Dog)
+func Clone(obj any) any {
+	if obj == nil {
+		return nil
+	}
+
+	v := reflect.ValueOf(obj)
+	dst := reflect.New(v.Type()).Elem()
+	c := &cloner{visited: map[cloneKey]reflect.Value{}}
+	c.cloneInto(dst, v)
+	return dst.Interface()
+}
+
+// Clone creates a new wrapped object holding a deep copy of the object, see
+// Clone. The copy starts in the created state and counts against the memory
+// budget of the factory that created the object. It returns nil if the object
+// is destroyed, the copy would exceed the budget or it violates a unique index,
+// see CreateIndex.
+// Example: copyObj := dogObj.Clone()
+func (o *ObjectWrapper) Clone() *ObjectWrapper {
+	o.mu.Lock()
+	o.klassMu.RLock()
+	klass := o.klass
+	var copied any
+	if klass != nil && klass.Class != nil {
+		copied = Clone(klass.Class)
+	}
+	o.klassMu.RUnlock()
+	o.mu.Unlock()
+
+	if copied == nil {
+		return nil
+	}
+	if o.budget != nil && !o.budget.reserve(o.reserved) {
+		return nil
+	}
+
+	obj := &ObjectWrapper{
+		klass:    New(klass.Allocator, classElem(reflect.TypeOf(copied)), copied),
+		budget:   o.budget,
+		reserved: o.reserved,
+		trash:    o.trash,
+		live:     o.live,
+	}
+	obj.klass.owner = weak.Make(obj)
+	if obj.indexNew() != nil {
+		return nil
+	}
+	obj.live.add(obj)
+	return obj
+}
+
+// cloneKey identifies a pointer, map or slice that was already copied.
+type cloneKey struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// cloner holds the state of a single Clone call.
+type cloner struct {
+	visited map[cloneKey]reflect.Value // Copies of the pointers, maps and slices seen so far.
+}
+
+// cloneInto sets dst, which must be settable, to a deep copy of src.
+func (c *cloner) cloneInto(dst, src reflect.Value) {
+	if !src.IsValid() {
+		return
+	}
+	if src.Kind() != reflect.Interface && src.CanInterface() && src.Type().Implements(cloneableType) {
+		if !(src.Kind() == reflect.Ptr && src.IsNil()) {
+			copied := reflect.ValueOf(src.Interface().(Cloneable).CloneObject())
+			if copied.IsValid() && copied.Type().AssignableTo(dst.Type()) {
+				dst.Set(copied)
+				return
+			}
+		}
+	}
+
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		key := cloneKey{ptr: src.Pointer(), typ: src.Type()}
+		if copied, ok := c.visited[key]; ok {
+			dst.Set(copied)
+			return
+		}
+		copied := reflect.New(src.Type().Elem())
+		c.visited[key] = copied // Before copying the target, so cycles end here.
+		c.cloneInto(copied.Elem(), src.Elem())
+		dst.Set(copied)
+
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		elem := src.Elem()
+		copied := reflect.New(elem.Type()).Elem()
+		c.cloneInto(copied, elem)
+		dst.Set(copied)
+
+	case reflect.Struct:
+		dst.Set(src) // Copies the unexported fields.
+		for i := range src.NumField() {
+			if src.Type().Field(i).IsExported() {
+				c.cloneInto(dst.Field(i), src.Field(i))
+			}
+		}
+
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		key := cloneKey{ptr: src.Pointer(), typ: src.Type(), len: src.Len()}
+		if copied, ok := c.visited[key]; ok {
+			dst.Set(copied)
+			return
+		}
+		copied := reflect.MakeSlice(src.Type(), src.Len(), src.Cap())
+		c.visited[key] = copied
+		for i := range src.Len() {
+			c.cloneInto(copied.Index(i), src.Index(i))
+		}
+		dst.Set(copied)
+
+	case reflect.Array:
+		for i := range src.Len() {
+			c.cloneInto(dst.Index(i), src.Index(i))
+		}
+
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		key := cloneKey{ptr: src.Pointer(), typ: src.Type()}
+		if copied, ok := c.visited[key]; ok {
+			dst.Set(copied)
+			return
+		}
+		copied := reflect.MakeMapWithSize(src.Type(), src.Len())
+		c.visited[key] = copied
+		for iter := src.MapRange(); iter.Next(); {
+			value := reflect.New(src.Type().Elem()).Elem()
+			c.cloneInto(value, iter.Value())
+			copied.SetMapIndex(iter.Key(), value)
+		}
+		dst.Set(copied)
+
+	default:
+		dst.Set(src)
+	}
+}
@@ -0,0 +1,125 @@
+package oop
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestNode is a test class forming cyclic graphs
+type TestNode struct {
+	Name     string
+	Next     *TestNode
+	Children []*TestNode
+	Tags     map[string][]string
+	Value    any
+	secret   *int
+}
+
+// TestSession is a test class implementing Cloneable
+type TestSession struct {
+	ID     int
+	Cached []byte
+}
+
+// CloneObject implements Cloneable and drops the cache
+func (s *TestSession) CloneObject() any {
+	return &TestSession{ID: s.ID}
+}
+
+// TestClone tests the Clone function
+func TestClone(t *testing.T) {
+	secret := 42
+	root := &TestNode{Name: "root", Tags: map[string][]string{"a": {"x", "y"}}, secret: &secret}
+	child := &TestNode{Name: "child", Next: root, Value: &TestDog{Name: "Rex"}}
+	root.Next = child
+	root.Children = []*TestNode{child, child}
+
+	copied, ok := Clone(root).(*TestNode)
+	if !ok {
+		t.Fatalf("Clone returned %T, want *TestNode", Clone(root))
+	}
+	if copied == root || copied.Next == child {
+		t.Error("Clone should copy pointers")
+	}
+	if copied.Next.Next != copied {
+		t.Error("Clone should preserve cycles")
+	}
+	if copied.Children[0] != copied.Next || copied.Children[1] != copied.Next {
+		t.Error("Clone should preserve shared pointers")
+	}
+	if copied.secret != root.secret {
+		t.Error("Clone should copy unexported fields as they are")
+	}
+
+	// Test that the copy is independent of the original
+	copied.Tags["a"][0] = "changed"
+	copied.Next.Value.(*TestDog).Name = "Max"
+	if root.Tags["a"][0] != "x" || child.Value.(*TestDog).Name != "Rex" {
+		t.Error("Clone should copy maps, slices and interface values deeply")
+	}
+	if !reflect.DeepEqual(copied.Tags, map[string][]string{"a": {"changed", "y"}}) {
+		t.Errorf("Clone copied %v, want the original tags", copied.Tags)
+	}
+
+	// Test values and nil
+	if got := Clone(TestDog{Name: "Rex"}); got != (TestDog{Name: "Rex"}) {
+		t.Errorf("Clone returned %v, want a copy of the value", got)
+	}
+	if Clone(nil) != nil {
+		t.Error("Clone should return nil for nil")
+	}
+
+	// Test Cloneable
+	session := &TestSession{ID: 7, Cached: []byte("data")}
+	if got := Clone(session).(*TestSession); got == session || got.ID != 7 || got.Cached != nil {
+		t.Errorf("Clone returned %+v, want the copy made by CloneObject", got)
+	}
+	holder := &TestNode{Value: session}
+	if got := Clone(holder).(*TestNode).Value.(*TestSession); got.Cached != nil {
+		t.Error("Clone should use CloneObject for nested values")
+	}
+}
+
+// TestObjectWrapperClone tests the Clone method of ObjectWrapper
+func TestObjectWrapperClone(t *testing.T) {
+	factory := NewObjectFactory()
+	if err := factory.ReserveMemory(int64(reflect.TypeOf(TestNode{}).Size()) * 2); err != nil {
+		t.Fatalf("ReserveMemory returned error: %v", err)
+	}
+
+	obj := factory.CreateObject(&TestNode{Name: "root", Tags: map[string][]string{"a": {"x"}}})
+	defer obj.Destroy()
+	if err := obj.Initialize(); err != nil {
+		t.Fatalf("Initialize returned error: %v", err)
+	}
+
+	copyObj := obj.Clone()
+	if copyObj == nil {
+		t.Fatal("Clone returned nil")
+	}
+	defer copyObj.Destroy()
+
+	node := copyObj.GetUnderlyingObject().(*TestNode)
+	if node == obj.GetUnderlyingObject() || node.Name != "root" {
+		t.Errorf("Clone returned %+v, want a copy of the object", node)
+	}
+	node.Tags["a"][0] = "changed"
+	if obj.GetUnderlyingObject().(*TestNode).Tags["a"][0] != "x" {
+		t.Error("Clone should copy the object deeply")
+	}
+	if copyObj.State() != StateCreated {
+		t.Errorf("copy is %s, want created", copyObj.State())
+	}
+
+	// Test the memory budget and destroyed objects
+	if used, _ := factory.MemoryUsage(); used != int64(reflect.TypeOf(TestNode{}).Size())*2 {
+		t.Errorf("MemoryUsage returned %d, want both objects", used)
+	}
+	if obj.Clone() != nil {
+		t.Error("Clone should return nil when the copy exceeds the memory budget")
+	}
+	copyObj.Destroy()
+	if copyObj.Clone() != nil {
+		t.Error("Clone should return nil for destroyed objects")
+	}
+}
@@ -0,0 +1,95 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// RegisterConstructor registers a named constructor for a class.
+// The constructor must be a function returning the class, a pointer to it, or
+// either of those followed by an error. The class is registered if it is not already.
+// Example: oop.RegisterConstructor(reflect.TypeOf(Dog{}), "named", func(name string) *Dog { return &Dog{Name: name} })
+func RegisterConstructor(classType reflect.Type, name string, fn interface{}) error {
+	if classType == nil {
+		return fmt.Errorf("classType cannot be nil")
+	}
+	if name == "" {
+		return fmt.Errorf("constructor name cannot be empty")
+	}
+
+	classType = classElem(classType)
+	fv := reflect.ValueOf(fn)
+	if fv.Kind() != reflect.Func || fv.IsNil() {
+		return fmt.Errorf("constructor %s must be a function, got %T", name, fn)
+	}
+
+	ft := fv.Type()
+	switch {
+	case ft.NumOut() == 1:
+	case ft.NumOut() == 2 && ft.Out(1) == errorType:
+	default:
+		return fmt.Errorf("constructor %s must return %s and an optional error", name, classType)
+	}
+	if classElem(ft.Out(0)) != classType {
+		return fmt.Errorf("constructor %s returns %s, want %s", name, ft.Out(0), classType)
+	}
+
+	info, err := registeredClass(classType)
+	if err != nil {
+		return err
+	}
+
+	info.mu.Lock()
+	defer info.mu.Unlock()
+
+	if info.constructors == nil {
+		info.constructors = map[string]reflect.Value{}
+	}
+	info.constructors[name] = fv
+	return nil
+}
+
+// Construct creates a new class instance with the named constructor.
+// Arguments are converted like method arguments: nil becomes the zero value and
+// numeric values are converted between numeric types.
+// Example: klass, err := oop.Construct(reflect.TypeOf(Dog{}), "named", "Rex")
+func Construct(classType reflect.Type, name string, args ...interface{}) (*Klass, error) {
+	if classType == nil {
+		return nil, fmt.Errorf("classType cannot be nil")
+	}
+
+	info, ok := LookupType(classType)
+	if !ok {
+		return nil, fmt.Errorf("class %s is not registered", classElem(classType))
+	}
+
+	info.mu.Lock()
+	fv, ok := info.constructors[name]
+	info.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("constructor %s not found for class %s", name, info.Name)
+	}
+
+	results, err := callMethod(fv, args)
+	if err != nil {
+		return nil, fmt.Errorf("constructor %s: %w", name, err)
+	}
+	if len(results) == 2 && results[1] != nil {
+		return nil, results[1].(error)
+	}
+
+	obj := reflect.ValueOf(results[0])
+	if !obj.IsValid() || (obj.Kind() == reflect.Ptr && obj.IsNil()) {
+		return nil, fmt.Errorf("constructor %s returned nil", name)
+	}
+	if obj.Kind() != reflect.Ptr {
+		// Store values by pointer, like instances created by New
+		ptr := reflect.New(obj.Type())
+		ptr.Elem().Set(obj)
+		obj = ptr
+	}
+
+	return New(nil, info.Type, obj.Interface()), nil
+}
@@ -0,0 +1,79 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// TestPoint is a test class with constructors
+type TestPoint struct {
+	X, Y int
+}
+
+// TestConstructors tests the RegisterConstructor and Construct functions
+func TestConstructors(t *testing.T) {
+	pointType := reflect.TypeOf(TestPoint{})
+
+	err := RegisterConstructor(pointType, "xy", func(x, y int) *TestPoint {
+		return &TestPoint{X: x, Y: y}
+	})
+	if err != nil {
+		t.Fatalf("RegisterConstructor returned error: %v", err)
+	}
+	err = RegisterConstructor(reflect.TypeOf(&TestPoint{}), "diagonal", func(n int) (TestPoint, error) {
+		if n < 0 {
+			return TestPoint{}, fmt.Errorf("n must not be negative")
+		}
+		return TestPoint{X: n, Y: n}, nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterConstructor returned error: %v", err)
+	}
+
+	// Test constructing with arguments
+	klass, err := Construct(pointType, "xy", 1, int8(2))
+	if err != nil {
+		t.Fatalf("Construct returned error: %v", err)
+	}
+	if p, ok := klass.Class.(*TestPoint); !ok || p.X != 1 || p.Y != 2 {
+		t.Errorf("Construct created %v, want &{1 2}", klass.Class)
+	}
+
+	// Test constructors returning a value and an error
+	klass, err = Construct(pointType, "diagonal", 3)
+	if err != nil {
+		t.Fatalf("Construct returned error: %v", err)
+	}
+	if p, ok := klass.Class.(*TestPoint); !ok || p.X != 3 || p.Y != 3 {
+		t.Errorf("Construct created %v, want &{3 3}", klass.Class)
+	}
+	if _, err := Construct(pointType, "diagonal", -1); err == nil || err.Error() != "n must not be negative" {
+		t.Errorf("Construct returned error %v, want the constructor error", err)
+	}
+
+	// Test invalid calls
+	if _, err := Construct(pointType, "unknown"); err == nil {
+		t.Error("Construct should return error for unknown constructors")
+	}
+	if _, err := Construct(pointType, "xy", 1); err == nil {
+		t.Error("Construct should return error for wrong argument count")
+	}
+	if _, err := Construct(reflect.TypeOf(TestCat{}), "xy"); err == nil {
+		t.Error("Construct should return error for unregistered classes")
+	}
+
+	// Test invalid constructors
+	if err := RegisterConstructor(pointType, "bad", 42); err == nil {
+		t.Error("RegisterConstructor should return error for non-functions")
+	}
+	if err := RegisterConstructor(pointType, "bad", func() *TestDog { return nil }); err == nil {
+		t.Error("RegisterConstructor should return error for a wrong return type")
+	}
+	if err := RegisterConstructor(pointType, "bad", func() (*TestPoint, string) { return nil, "" }); err == nil {
+		t.Error("RegisterConstructor should return error when the second result is not an error")
+	}
+	if err := RegisterConstructor(pointType, "", func() *TestPoint { return nil }); err == nil {
+		t.Error("RegisterConstructor should return error for an empty name")
+	}
+}
@@ -0,0 +1,514 @@
+package oop
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Lifetime controls how often a Container calls the constructor of a provider.
+type Lifetime int
+
+const (
+	LifetimeTransient Lifetime = iota // A new instance for every resolution.
+	LifetimeSingleton                 // One instance shared by the container and all its scopes.
+	LifetimeScoped                    // One instance per scope created with Container.Scope.
+)
+
+// String returns the name of the lifetime.
+func (l Lifetime) String() string {
+	switch l {
+	case LifetimeTransient:
+		return "transient"
+	case LifetimeSingleton:
+		return "singleton"
+	case LifetimeScoped:
+		return "scoped"
+	default:
+		return "unknown"
+	}
+}
+
+// ProviderOption configures a provider registered with Provide, Singleton or Scoped.
+type ProviderOption func(*provider)
+
+// Named qualifies a provider with a name, so ResolveNamed can select it among
+// the providers of the same type. Several providers can return the same type
+// under different names.
+// Example: c.Singleton(NewRedisCache, oop.Named("fast"))
+func Named(name string) ProviderOption {
+	return func(p *provider) {
+		p.name = name
+	}
+}
+
+// Primary makes a provider win over the other providers satisfying the same
+// type, see Resolve.
+// Example: c.Provide(NewPostgresStore, oop.Primary())
+func Primary() ProviderOption {
+	return func(p *provider) {
+		p.primary = true
+	}
+}
+
+// Weight sets the weight of a provider, 0 by default. Among the providers
+// satisfying a type, the one with the highest weight wins, see Resolve.
+// Example: c.Provide(NewFileStore, oop.Weight(10))
+func Weight(weight int) ProviderOption {
+	return func(p *provider) {
+		p.weight = weight
+	}
+}
+
+// providerKey identifies a provider by the type it returns and its name.
+type providerKey struct {
+	out  reflect.Type
+	name string
+}
+
+// provider is a constructor registered in a Container.
+type provider struct {
+	out      reflect.Type   // Type the constructor returns.
+	fn       reflect.Value  // The constructor.
+	deps     []reflect.Type // Parameter types of the constructor.
+	lifetime Lifetime       // How often the constructor is called.
+	name     string         // Qualifier set with Named, empty if none.
+	primary  bool           // Whether the provider was registered with Primary.
+	weight   int            // Weight set with Weight.
+	order    int            // Registration order, for stable results.
+
+	mu        sync.Mutex                         // Guards instance, built and decorated.
+	instance  reflect.Value                      // Instance of a singleton; invalid if the constructor returned a nil interface.
+	built     bool                               // Whether the singleton instance was built.
+	decorated map[reflect.Type]decoratedInstance // Decorated singleton instance per interface, see DecorateAll.
+}
+
+// scopedInstance is the instance of a scoped provider within one scope.
+type scopedInstance struct {
+	mu        sync.Mutex                         // Held while the instance is built or decorated.
+	value     reflect.Value                      // The instance; invalid if the constructor returned a nil interface.
+	built     bool                               // Whether the instance was built.
+	decorated map[reflect.Type]decoratedInstance // Decorated instance per interface, see DecorateAll.
+}
+
+// Container is a dependency injection container.
+// Constructors are registered with Provide, Singleton or Scoped, and their
+// parameters are resolved by type from the other providers. A type is
+// satisfied by the providers of the type itself, of the types implementing it
+// if it is an interface, and of its subclasses, using the class registry and
+// Cast. When several providers satisfy a type, one is selected as described
+// by Resolve.
+type Container struct {
+	root *Container // Container the providers are registered in; the container itself for the root.
+
+	mu         sync.Mutex                       // Guards providers, scoped, pending, decorators, singletons and compiled.
+	providers  map[providerKey]*provider        // Providers keyed by the type they return and their name; only used on the root.
+	scoped     map[*provider]*scopedInstance    // Instances of scoped providers; only used on scopes.
+	pending    []conditionalProvider            // Providers registered through Profile and When, see Compile; only used on the root.
+	decorators map[reflect.Type][]reflect.Value // Decorators registered with DecorateAll, keyed by interface type; only used on the root.
+	singletons []*provider                      // Singletons in the order they were built, see StopAll; only used on the root.
+	compiled   bool                             // Whether Compile was called; only used on the root.
+}
+
+// NewContainer creates a new, empty dependency injection container.
+// Example: c := oop.NewContainer()
+func NewContainer() *Container {
+	c := &Container{providers: map[providerKey]*provider{}}
+	c.root = c
+	return c
+}
+
+// Scope creates a scope of the container. A scope shares the providers and
+// singletons of its container, and holds its own instances of scoped providers.
+// Example: scope := c.Scope()
+func (c *Container) Scope() *Container {
+	return &Container{root: c.root, scoped: map[*provider]*scopedInstance{}}
+}
+
+// Provide registers a transient constructor, which is called for every resolution.
+// The constructor must be a function returning a value and an optional error;
+// its parameters are resolved from the container.
+// Example: c.Provide(func(db *DB) *UserRepository { return &UserRepository{db: db} })
+func (c *Container) Provide(constructor interface{}, opts ...ProviderOption) error {
+	return c.register(constructor, LifetimeTransient, opts)
+}
+
+// Singleton registers a constructor that is called once; its instance is
+// shared by the container and all its scopes.
+// Example: c.Singleton(func() (*DB, error) { return OpenDB() })
+func (c *Container) Singleton(constructor interface{}, opts ...ProviderOption) error {
+	return c.register(constructor, LifetimeSingleton, opts)
+}
+
+// Scoped registers a constructor that is called once per scope.
+// Scoped types can only be resolved from a scope created with Scope.
+// Example: c.Scoped(func() *RequestContext { return &RequestContext{} })
+func (c *Container) Scoped(constructor interface{}, opts ...ProviderOption) error {
+	return c.register(constructor, LifetimeScoped, opts)
+}
+
+// Resolve returns an instance of T from the container, building it and its
+// dependencies with the registered constructors. Dependencies are selected
+// the same way. When several providers satisfy T, the provider registered
+// with Primary wins, then a provider of T itself over those of implementations
+// and subclasses, then the provider with the highest Weight; if that leaves
+// more than one, Resolve returns an ambiguity error naming them.
+// Example: repo, err := oop.Resolve[*UserRepository](c)
+func Resolve[T any](c *Container) (T, error) {
+	return resolveAs[T](c, "")
+}
+
+// ResolveNamed returns an instance of T like Resolve, selected among the
+// providers registered with Named(name). The dependencies of the provider are
+// resolved without a name.
+// Example: cache, err := oop.ResolveNamed[Cache](c, "fast")
+func ResolveNamed[T any](c *Container, name string) (T, error) {
+	var zero T
+	if name == "" {
+		return zero, fmt.Errorf("name cannot be empty")
+	}
+	return resolveAs[T](c, name)
+}
+
+// ResolveAll returns an instance of T from every provider that satisfies it:
+// the primary providers first, then the providers of T itself, then by
+// decreasing weight and in registration order.
+// Example: handlers, err := oop.ResolveAll[Handler](c)
+func ResolveAll[T any](c *Container) ([]T, error) {
+	if c == nil {
+		return nil, fmt.Errorf("container cannot be nil")
+	}
+
+	t := reflect.TypeFor[T]()
+	var all []T
+	for _, p := range c.root.candidates(t, "") {
+		v, err := c.resolveFrom(p, t)
+		if err != nil {
+			return nil, err
+		}
+		var instance T
+		if v.IsValid() {
+			instance = v.Interface().(T)
+		}
+		all = append(all, instance)
+	}
+	return all, nil
+}
+
+// resolveAs implements Resolve and ResolveNamed.
+func resolveAs[T any](c *Container, name string) (T, error) {
+	var zero T
+	if c == nil {
+		return zero, fmt.Errorf("container cannot be nil")
+	}
+
+	v, err := c.resolve(reflect.TypeOf((*T)(nil)).Elem(), name)
+	if err != nil {
+		return zero, err
+	}
+	if !v.IsValid() {
+		return zero, nil
+	}
+	return v.Interface().(T), nil
+}
+
+// register validates a constructor and adds it to the root container.
+func (c *Container) register(constructor interface{}, lifetime Lifetime, opts []ProviderOption) error {
+	p, err := newProvider(constructor, lifetime, opts)
+	if err != nil {
+		return err
+	}
+	return c.root.add(p)
+}
+
+// newProvider validates a constructor and returns its provider.
+func newProvider(constructor interface{}, lifetime Lifetime, opts []ProviderOption) (*provider, error) {
+	fv := reflect.ValueOf(constructor)
+	if fv.Kind() != reflect.Func || fv.IsNil() {
+		return nil, fmt.Errorf("constructor must be a function, got %T", constructor)
+	}
+
+	ft := fv.Type()
+	switch {
+	case ft.IsVariadic():
+		return nil, fmt.Errorf("constructor %s cannot be variadic", ft)
+	case ft.NumOut() == 1:
+	case ft.NumOut() == 2 && ft.Out(1) == errorType:
+	default:
+		return nil, fmt.Errorf("constructor %s must return a value and an optional error", ft)
+	}
+
+	p := &provider{out: ft.Out(0), fn: fv, lifetime: lifetime}
+	for i := range ft.NumIn() {
+		p.deps = append(p.deps, ft.In(i))
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p, nil
+}
+
+// add registers a provider in the root container.
+func (c *Container) add(p *provider) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := providerKey{out: p.out, name: p.name}
+	if _, ok := c.providers[key]; ok {
+		if p.name != "" {
+			return fmt.Errorf("a provider for %s named %s is already registered", p.out, p.name)
+		}
+		return fmt.Errorf("a provider for %s is already registered", p.out)
+	}
+	p.order = len(c.providers)
+	c.providers[key] = p
+	return nil
+}
+
+// resolve returns an instance of type t from the provider selected among
+// those with the given name, or among all of them if name is empty.
+func (c *Container) resolve(t reflect.Type, name string) (reflect.Value, error) {
+	p, err := c.root.provider(t, name)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return c.resolveFrom(p, t)
+}
+
+// resolveFrom returns an instance of type t from provider p. The dependency
+// graph is checked for cycles before anything is built.
+func (c *Container) resolveFrom(p *provider, t reflect.Type) (reflect.Value, error) {
+	checked := map[reflect.Type]bool{}
+	for _, dep := range p.deps {
+		if err := c.root.check(dep, []reflect.Type{t}, checked); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	return c.buildFrom(p, t)
+}
+
+// check walks the dependencies of t and reports missing providers and cycles,
+// with the chain of types that led to them.
+func (c *Container) check(t reflect.Type, path []reflect.Type, checked map[reflect.Type]bool) error {
+	path = append(path, t)
+	if i := slices.Index(path, t); i < len(path)-1 {
+		return fmt.Errorf("circular dependency: %s", formatChain(path[i:]))
+	}
+	if checked[t] {
+		return nil
+	}
+
+	p, err := c.provider(t, "")
+	if err != nil {
+		if len(path) > 1 {
+			return fmt.Errorf("%w (required by %s)", err, formatChain(path[:len(path)-1]))
+		}
+		return err
+	}
+	for _, dep := range p.deps {
+		if err := c.check(dep, path, checked); err != nil {
+			return err
+		}
+	}
+
+	checked[t] = true
+	return nil
+}
+
+// build returns an instance of type t from the provider that satisfies it.
+func (c *Container) build(t reflect.Type) (reflect.Value, error) {
+	p, err := c.root.provider(t, "")
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return c.buildFrom(p, t)
+}
+
+// buildFrom returns an instance of type t from provider p.
+func (c *Container) buildFrom(p *provider, t reflect.Type) (reflect.Value, error) {
+	v, err := c.instance(p)
+	if err != nil || !v.IsValid() {
+		return v, err
+	}
+
+	if p.out != t {
+		converted := Cast(v.Interface(), t)
+		if converted == nil {
+			return reflect.Value{}, fmt.Errorf("cannot cast %s to %s", p.out, t)
+		}
+		v = reflect.ValueOf(converted)
+	}
+	return c.decorated(p, t, v)
+}
+
+// instance returns the instance of a provider according to its lifetime.
+func (c *Container) instance(p *provider) (reflect.Value, error) {
+	switch p.lifetime {
+	case LifetimeSingleton:
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		if !p.built {
+			// Singletons are built from the root, so they cannot capture scoped instances.
+			v, err := c.root.call(p)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			p.instance, p.built = v, true
+
+			c.root.mu.Lock()
+			c.root.singletons = append(c.root.singletons, p)
+			c.root.mu.Unlock()
+		}
+		return p.instance, nil
+
+	case LifetimeScoped:
+		if c == c.root {
+			return reflect.Value{}, fmt.Errorf("scoped %s must be resolved from a scope", p.out)
+		}
+
+		c.mu.Lock()
+		entry, ok := c.scoped[p]
+		if !ok {
+			entry = &scopedInstance{}
+			c.scoped[p] = entry
+		}
+		c.mu.Unlock()
+
+		entry.mu.Lock()
+		defer entry.mu.Unlock()
+
+		if !entry.built {
+			v, err := c.call(p)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			entry.value, entry.built = v, true
+		}
+		return entry.value, nil
+
+	default:
+		return c.call(p)
+	}
+}
+
+// call resolves the dependencies of a provider and calls its constructor.
+// Interfaces returned by the constructor are unwrapped to their dynamic value,
+// so a nil interface results in an invalid value.
+func (c *Container) call(p *provider) (reflect.Value, error) {
+	args := make([]reflect.Value, len(p.deps))
+	for i, dep := range p.deps {
+		v, err := c.build(dep)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("resolving %s for %s: %w", dep, p.out, err)
+		}
+		if !v.IsValid() {
+			v = reflect.Zero(dep)
+		}
+		args[i] = v
+	}
+
+	out := p.fn.Call(args)
+	if len(out) == 2 && !out[1].IsNil() {
+		return reflect.Value{}, fmt.Errorf("constructor of %s: %w", p.out, out[1].Interface().(error))
+	}
+	if out[0].Kind() == reflect.Interface {
+		return out[0].Elem(), nil
+	}
+	return out[0], nil
+}
+
+// provider returns the provider selected for type t among those with the
+// given name, or among all of them if name is empty, as described by Resolve.
+func (c *Container) provider(t reflect.Type, name string) (*provider, error) {
+	candidates := c.candidates(t, name)
+	switch {
+	case len(candidates) == 0 && name != "":
+		return nil, fmt.Errorf("no provider for %s named %s", t, name)
+	case len(candidates) == 0:
+		return nil, fmt.Errorf("no provider for %s", t)
+	case len(candidates) == 1 || compareProviders(t, candidates[0], candidates[1]) != 0:
+		return candidates[0], nil
+	}
+
+	var names []string
+	for _, p := range candidates {
+		if compareProviders(t, candidates[0], p) == 0 {
+			names = append(names, p.String())
+		}
+	}
+	slices.Sort(names)
+	return nil, fmt.Errorf("ambiguous providers for %s: %s", t, strings.Join(names, ", "))
+}
+
+// candidates returns the providers that satisfy type t among those with the
+// given name, or among all of them if name is empty, in the order of ResolveAll.
+func (c *Container) candidates(t reflect.Type, name string) []*provider {
+	c.mu.Lock()
+	var candidates []*provider
+	for key, p := range c.providers {
+		if (name == "" || key.name == name) && (key.out == t || satisfies(key.out, t)) {
+			candidates = append(candidates, p)
+		}
+	}
+	c.mu.Unlock()
+
+	slices.SortFunc(candidates, func(a, b *provider) int {
+		if n := compareProviders(t, a, b); n != 0 {
+			return n
+		}
+		return cmp.Compare(a.order, b.order)
+	})
+	return candidates
+}
+
+// compareProviders orders two providers satisfying type t by precedence:
+// primary providers first, then providers of t itself, then by decreasing
+// weight. It returns 0 if neither takes precedence.
+func compareProviders(t reflect.Type, a, b *provider) int {
+	switch {
+	case a.primary != b.primary:
+		if a.primary {
+			return -1
+		}
+		return 1
+	case (a.out == t) != (b.out == t):
+		if a.out == t {
+			return -1
+		}
+		return 1
+	default:
+		return cmp.Compare(b.weight, a.weight)
+	}
+}
+
+// String returns the type of the provider, with its name if it has one.
+func (p *provider) String() string {
+	if p.name != "" {
+		return fmt.Sprintf("%s (%s)", p.out, p.name)
+	}
+	return p.out.String()
+}
+
+// satisfies reports whether a value of type out can be resolved as type t.
+func satisfies(out, t reflect.Type) bool {
+	if t.Kind() == reflect.Interface {
+		return implements(out, t)
+	}
+	if classElem(t).Kind() != reflect.Struct || classElem(out) == classElem(t) {
+		return false
+	}
+	return IsSubclassOf(out, t)
+}
+
+// formatChain formats a chain of dependencies, e.g. "*A -> *B -> *A".
+func formatChain(path []reflect.Type) string {
+	names := make([]string, len(path))
+	for i, t := range path {
+		names[i] = t.String()
+	}
+	return strings.Join(names, " -> ")
+}
@@ -0,0 +1,267 @@
+package oop
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestDB is a test dependency
+type TestDB struct {
+	Name string
+}
+
+// TestStore is a test interface implemented by TestRepo
+type TestStore interface {
+	Find(id int) string
+}
+
+// TestRepo is a test class depending on TestDB
+type TestRepo struct {
+	DB *TestDB
+}
+
+// Find implements TestStore
+func (r *TestRepo) Find(id int) string {
+	return r.DB.Name
+}
+
+// TestUserService is a test class depending on TestStore
+type TestUserService struct {
+	Store TestStore
+}
+
+// TestRequest is a test class with a scoped lifetime
+type TestRequest struct {
+	DB *TestDB
+}
+
+// TestContainer tests the Provide, Singleton, Scoped and Resolve functions
+func TestContainer(t *testing.T) {
+	c := NewContainer()
+	dbCount := 0
+	if err := c.Singleton(func() *TestDB { dbCount++; return &TestDB{Name: "main"} }); err != nil {
+		t.Fatalf("Singleton returned error: %v", err)
+	}
+	if err := c.Provide(func(db *TestDB) *TestRepo { return &TestRepo{DB: db} }); err != nil {
+		t.Fatalf("Provide returned error: %v", err)
+	}
+	if err := c.Provide(func(store TestStore) (*TestUserService, error) { return &TestUserService{Store: store}, nil }); err != nil {
+		t.Fatalf("Provide returned error: %v", err)
+	}
+	if err := c.Scoped(func(db *TestDB) *TestRequest { return &TestRequest{DB: db} }); err != nil {
+		t.Fatalf("Scoped returned error: %v", err)
+	}
+
+	// Test resolution through interfaces
+	service, err := Resolve[*TestUserService](c)
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if service.Store.Find(1) != "main" {
+		t.Errorf("Resolve wired %v, want a TestRepo with the main TestDB", service.Store)
+	}
+	store, err := Resolve[TestStore](c)
+	if err != nil || store == service.Store {
+		t.Errorf("Resolve returned %v, %v, want a new transient TestRepo", store, err)
+	}
+
+	// Test singletons
+	db, _ := Resolve[*TestDB](c)
+	if db != service.Store.(*TestRepo).DB || dbCount != 1 {
+		t.Errorf("singleton was built %d times, want once", dbCount)
+	}
+
+	// Test scopes
+	if _, err := Resolve[*TestRequest](c); err == nil {
+		t.Error("Resolve should return error for scoped types outside a scope")
+	}
+	scope := c.Scope()
+	request, err := Resolve[*TestRequest](scope)
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if again, _ := Resolve[*TestRequest](scope); again != request {
+		t.Error("Resolve should return the same instance within a scope")
+	}
+	if other, _ := Resolve[*TestRequest](c.Scope()); other == request {
+		t.Error("Resolve should return a new instance in another scope")
+	}
+	if request.DB != db {
+		t.Error("scopes should share singletons")
+	}
+
+	// Test concurrent resolution of singletons
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, err := Resolve[*TestUserService](c.Scope()); err != nil || got.Store.Find(1) != "main" {
+				t.Errorf("Resolve returned %v, %v", got, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// TestContainerErrors tests the errors reported by a Container
+func TestContainerErrors(t *testing.T) {
+	c := NewContainer()
+
+	// Test invalid constructors
+	if err := c.Provide("not a function"); err == nil {
+		t.Error("Provide should return error for non-functions")
+	}
+	if err := c.Provide(func() {}); err == nil {
+		t.Error("Provide should return error for constructors without a result")
+	}
+	if err := c.Provide(func(names ...string) *TestDB { return nil }); err == nil {
+		t.Error("Provide should return error for variadic constructors")
+	}
+	if err := c.Provide(func() *TestDB { return &TestDB{} }); err != nil {
+		t.Fatalf("Provide returned error: %v", err)
+	}
+	if err := c.Singleton(func() *TestDB { return &TestDB{} }); err == nil {
+		t.Error("Singleton should return error for types that are already provided")
+	}
+
+	// Test missing dependencies
+	if err := c.Provide(func(store TestStore) *TestUserService { return &TestUserService{Store: store} }); err != nil {
+		t.Fatalf("Provide returned error: %v", err)
+	}
+	_, err := Resolve[*TestUserService](c)
+	if err == nil || !strings.Contains(err.Error(), "no provider for oop.TestStore (required by *oop.TestUserService)") {
+		t.Errorf("Resolve returned %v, want a missing provider error", err)
+	}
+
+	// Test circular dependencies
+	if err := c.Provide(func(service *TestUserService) *TestRepo { return &TestRepo{} }); err != nil {
+		t.Fatalf("Provide returned error: %v", err)
+	}
+	_, err = Resolve[*TestUserService](c)
+	want := "circular dependency: *oop.TestUserService -> oop.TestStore -> *oop.TestUserService"
+	if err == nil || err.Error() != want {
+		t.Errorf("Resolve returned %v, want %q", err, want)
+	}
+
+	// Test constructor errors
+	failing := NewContainer()
+	errFailed := errors.New("failed")
+	if err := failing.Provide(func() (*TestDB, error) { return nil, errFailed }); err != nil {
+		t.Fatalf("Provide returned error: %v", err)
+	}
+	if err := failing.Provide(func(db *TestDB) *TestRepo { return &TestRepo{DB: db} }); err != nil {
+		t.Fatalf("Provide returned error: %v", err)
+	}
+	if _, err := Resolve[*TestRepo](failing); !errors.Is(err, errFailed) {
+		t.Errorf("Resolve returned %v, want the constructor error", err)
+	}
+
+	// Test ambiguous providers
+	if err := failing.Provide(func() *TestParrot { return &TestParrot{} }); err != nil {
+		t.Fatalf("Provide returned error: %v", err)
+	}
+	if err := failing.Provide(func() *TestCat { return &TestCat{} }); err != nil {
+		t.Fatalf("Provide returned error: %v", err)
+	}
+	if _, err := Resolve[TestAnimal](failing); err == nil || !strings.Contains(err.Error(), "ambiguous providers") {
+		t.Errorf("Resolve returned %v, want an ambiguous providers error", err)
+	}
+}
+
+// TestContainerSubclasses tests resolving classes through their subclasses
+func TestContainerSubclasses(t *testing.T) {
+	c := NewContainer()
+	if err := c.Provide(func() *TestKennel { return &TestKennel{Name: "Home"} }); err != nil {
+		t.Fatalf("Provide returned error: %v", err)
+	}
+
+	mammal, err := Resolve[*TestMammal](c)
+	if err != nil || mammal == nil {
+		t.Fatalf("Resolve returned %v, %v, want the TestMammal embedded in TestKennel", mammal, err)
+	}
+	if _, err := Resolve[*TestDog](c); err == nil {
+		t.Error("Resolve should return error for classes without a provider")
+	}
+}
+
+// TestMemoryStore is another test implementation of TestStore
+type TestMemoryStore struct {
+	Name string
+}
+
+// Find implements TestStore
+func (s *TestMemoryStore) Find(id int) string {
+	return s.Name
+}
+
+// TestContainerSelection tests selecting among several providers of a type
+func TestContainerSelection(t *testing.T) {
+	c := NewContainer()
+	if err := c.Provide(func() *TestRepo { return &TestRepo{DB: &TestDB{Name: "repo"}} }, Weight(5)); err != nil {
+		t.Fatalf("Provide returned error: %v", err)
+	}
+	if err := c.Provide(func() *TestMemoryStore { return &TestMemoryStore{Name: "memory"} }); err != nil {
+		t.Fatalf("Provide returned error: %v", err)
+	}
+	if err := c.Provide(func() *TestMemoryStore { return &TestMemoryStore{Name: "fast"} }, Named("fast")); err != nil {
+		t.Fatalf("Provide returned error: %v", err)
+	}
+	if err := c.Provide(func() *TestMemoryStore { return nil }, Named("fast")); err == nil {
+		t.Error("Provide should return error for names that are already provided")
+	}
+	find := func(store TestStore, err error) string {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("Resolve returned error: %v", err)
+		}
+		return store.Find(0)
+	}
+
+	// Test that the highest weight wins, and qualifiers
+	if name := find(Resolve[TestStore](c)); name != "repo" {
+		t.Errorf("Resolve returned the %s store, want the heaviest", name)
+	}
+	if name := find(ResolveNamed[TestStore](c, "fast")); name != "fast" {
+		t.Errorf("ResolveNamed returned the %s store, want fast", name)
+	}
+	if _, err := ResolveNamed[TestStore](c, "slow"); err == nil || !strings.Contains(err.Error(), "no provider for oop.TestStore named slow") {
+		t.Errorf("ResolveNamed returned %v, want a missing provider error", err)
+	}
+
+	// Test that providers of the type itself of the same weight are ambiguous
+	_, err := Resolve[*TestMemoryStore](c)
+	want := "ambiguous providers for *oop.TestMemoryStore: *oop.TestMemoryStore, *oop.TestMemoryStore (fast)"
+	if err == nil || err.Error() != want {
+		t.Errorf("Resolve returned %v, want %q", err, want)
+	}
+
+	// Test that a primary provider wins
+	if err := c.Provide(func() *TestMemoryStore { return &TestMemoryStore{Name: "primary"} }, Named("primary"), Primary()); err != nil {
+		t.Fatalf("Provide returned error: %v", err)
+	}
+	if name := find(Resolve[TestStore](c)); name != "primary" {
+		t.Errorf("Resolve returned the %s store, want the primary", name)
+	}
+
+	// Test that ResolveAll orders the providers by precedence
+	all, err := ResolveAll[TestStore](c)
+	if err != nil {
+		t.Fatalf("ResolveAll returned error: %v", err)
+	}
+	var names []string
+	for _, store := range all {
+		names = append(names, store.Find(0))
+	}
+	if got := strings.Join(names, ","); got != "primary,repo,memory,fast" {
+		t.Errorf("ResolveAll returned %s, want primary,repo,memory,fast", got)
+	}
+	if all, err := ResolveAll[*TestDB](c); err != nil || len(all) != 0 {
+		t.Errorf("ResolveAll without providers returned %v, %v", all, err)
+	}
+	if _, err := ResolveNamed[TestStore](c, ""); err == nil {
+		t.Error("ResolveNamed with an empty name should return an error")
+	}
+}
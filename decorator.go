@@ -0,0 +1,116 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// DecorateAll registers a decorator for an interface type, given as a pointer
+// to the interface or as a reflect.Type. Every instance the container resolves
+// as that interface, including dependencies of that type, is passed to the
+// decorators of the interface, in registration order, so the last decorator
+// is the outermost. The decorator must be a func(I) I or func(I) (I, error).
+// Singletons and scoped instances are decorated once per interface, in the
+// container or in the scope, so resolving them again returns the same
+// decorated instance; transient instances are decorated on every resolution.
+// Instances resolved as their own type are not decorated.
+// Example: c.DecorateAll((*Repository)(nil), func(r Repository) Repository { return &MetricsRepository{next: r} })
+func (c *Container) DecorateAll(iface interface{}, decorator interface{}) error {
+	t, ok := iface.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(iface)
+		if t == nil || t.Kind() != reflect.Ptr {
+			return fmt.Errorf("iface must be a pointer to an interface type")
+		}
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Interface {
+		return fmt.Errorf("iface must be a pointer to an interface type")
+	}
+
+	dv := reflect.ValueOf(decorator)
+	if dv.Kind() != reflect.Func || dv.IsNil() {
+		return fmt.Errorf("decorator must be a function, got %T", decorator)
+	}
+	dt := dv.Type()
+	validOut := dt.NumOut() == 1 || (dt.NumOut() == 2 && dt.Out(1) == errorType)
+	if dt.NumIn() != 1 || dt.In(0) != t || !validOut || dt.Out(0) != t {
+		return fmt.Errorf("decorator of %s must be a func(%s) %s with an optional error result, got %s", t, t, t, dt)
+	}
+
+	root := c.root
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	if root.decorators == nil {
+		root.decorators = map[reflect.Type][]reflect.Value{}
+	}
+	root.decorators[t] = append(root.decorators[t], dv)
+	return nil
+}
+
+// decoratedInstance is a decorated singleton or scoped instance.
+type decoratedInstance struct {
+	value      reflect.Value // The decorated instance.
+	decorators int           // Number of decorators applied, to decorate again if more were registered.
+}
+
+// decorated passes the instance v of provider p, resolved as type t, to the
+// decorators of t. The decorated instances of singletons and scoped providers
+// are cached per type, so they are decorated once.
+func (c *Container) decorated(p *provider, t reflect.Type, v reflect.Value) (reflect.Value, error) {
+	root := c.root
+	root.mu.Lock()
+	decorators := root.decorators[t]
+	root.mu.Unlock()
+	if len(decorators) == 0 {
+		return v, nil
+	}
+
+	var mu *sync.Mutex
+	var cache *map[reflect.Type]decoratedInstance
+	switch p.lifetime {
+	case LifetimeSingleton:
+		mu, cache = &p.mu, &p.decorated
+	case LifetimeScoped:
+		c.mu.Lock()
+		entry := c.scoped[p] // Created by instance.
+		c.mu.Unlock()
+		mu, cache = &entry.mu, &entry.decorated
+	default:
+		return decorate(t, v, decorators)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if cached, ok := (*cache)[t]; ok && cached.decorators == len(decorators) {
+		return cached.value, nil
+	}
+	d, err := decorate(t, v, decorators)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if *cache == nil {
+		*cache = map[reflect.Type]decoratedInstance{}
+	}
+	(*cache)[t] = decoratedInstance{value: d, decorators: len(decorators)}
+	return d, nil
+}
+
+// decorate passes an instance resolved as type t to decorators.
+func decorate(t reflect.Type, v reflect.Value, decorators []reflect.Value) (reflect.Value, error) {
+	for _, decorator := range decorators {
+		in := reflect.New(t).Elem()
+		in.Set(v)
+
+		out := decorator.Call([]reflect.Value{in})
+		if len(out) == 2 && !out[1].IsNil() {
+			return reflect.Value{}, fmt.Errorf("decorator of %s: %w", t, out[1].Interface().(error))
+		}
+		if out[0].IsNil() {
+			return reflect.Value{}, fmt.Errorf("decorator of %s returned nil", t)
+		}
+		v = out[0].Elem()
+	}
+	return v, nil
+}
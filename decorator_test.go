@@ -0,0 +1,166 @@
+package oop
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestMetricsStore is a test decorator of TestStore counting lookups
+type TestMetricsStore struct {
+	Next  TestStore
+	Calls *int
+}
+
+// Find implements TestStore
+func (s *TestMetricsStore) Find(id int) string {
+	*s.Calls++
+	return s.Next.Find(id)
+}
+
+// TestCachedStore is a test decorator of TestStore tagging results
+type TestCachedStore struct {
+	Next TestStore
+}
+
+// Find implements TestStore
+func (s *TestCachedStore) Find(id int) string {
+	return "cached " + s.Next.Find(id)
+}
+
+// TestDecorateAll tests wrapping every resolved implementation of an interface
+func TestDecorateAll(t *testing.T) {
+	c := NewContainer()
+	c.Singleton(func() *TestDB { return &TestDB{Name: "main"} })
+	c.Provide(func(db *TestDB) *TestRepo { return &TestRepo{DB: db} }, Primary())
+	c.Provide(func() *TestMemoryStore { return &TestMemoryStore{} }, Named("memory"))
+	c.Provide(func(store TestStore) *TestUserService { return &TestUserService{Store: store} })
+
+	calls := 0
+	if err := c.DecorateAll((*TestStore)(nil), func(s TestStore) TestStore { return &TestMetricsStore{Next: s, Calls: &calls} }); err != nil {
+		t.Fatalf("DecorateAll returned error: %v", err)
+	}
+	if err := c.DecorateAll(reflect.TypeFor[TestStore](), func(s TestStore) (TestStore, error) { return &TestCachedStore{Next: s}, nil }); err != nil {
+		t.Fatalf("DecorateAll returned error: %v", err)
+	}
+
+	// Test that the last decorator is the outermost
+	store, err := Resolve[TestStore](c)
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if got := store.Find(1); got != "cached main" || calls != 1 {
+		t.Errorf("Find returned %q after %d calls, want %q after 1", got, calls, "cached main")
+	}
+	if _, ok := store.(*TestCachedStore).Next.(*TestMetricsStore); !ok {
+		t.Errorf("the decorators were applied in the wrong order: %#v", store)
+	}
+
+	// Test that dependencies, named and all providers are decorated
+	service, err := Resolve[*TestUserService](c)
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if _, ok := service.Store.(*TestCachedStore); !ok {
+		t.Errorf("the dependency was not decorated: %#v", service.Store)
+	}
+	named, err := ResolveNamed[TestStore](c, "memory")
+	if err != nil {
+		t.Fatalf("ResolveNamed returned error: %v", err)
+	}
+	if _, ok := named.(*TestCachedStore); !ok {
+		t.Errorf("the named provider was not decorated: %#v", named)
+	}
+	all, err := ResolveAll[TestStore](c)
+	if err != nil || len(all) != 2 {
+		t.Fatalf("ResolveAll returned %v, %v", all, err)
+	}
+	for _, s := range all {
+		if _, ok := s.(*TestCachedStore); !ok {
+			t.Errorf("ResolveAll returned an undecorated %#v", s)
+		}
+	}
+
+	// Test that instances resolved as their own type are not decorated
+	if repo, err := Resolve[*TestRepo](c); err != nil || repo == nil {
+		t.Errorf("Resolve returned %v, %v", repo, err)
+	}
+}
+
+// TestDecorateAllErrors tests the errors reported by DecorateAll and decorators
+func TestDecorateAllErrors(t *testing.T) {
+	c := NewContainer()
+	c.Provide(func() *TestMemoryStore { return &TestMemoryStore{} })
+
+	for name, args := range map[string][2]interface{}{
+		"not an interface": {(*TestRepo)(nil), func(s *TestRepo) *TestRepo { return s }},
+		"nil interface":    {nil, func(s TestStore) TestStore { return s }},
+		"not a function":   {(*TestStore)(nil), "decorator"},
+		"nil function":     {(*TestStore)(nil), (func(TestStore) TestStore)(nil)},
+		"wrong signature":  {(*TestStore)(nil), func(s TestStore) *TestRepo { return nil }},
+		"wrong error":      {(*TestStore)(nil), func(s TestStore) (TestStore, string) { return s, "" }},
+	} {
+		if err := c.DecorateAll(args[0], args[1]); err == nil {
+			t.Errorf("DecorateAll should return error for %s", name)
+		}
+	}
+
+	// Test decorator errors
+	c.DecorateAll((*TestStore)(nil), func(s TestStore) (TestStore, error) { return nil, errors.New("boom") })
+	if _, err := Resolve[TestStore](c); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Resolve returned %v, want the decorator error", err)
+	}
+
+	// Test nil results
+	c = NewContainer()
+	c.Provide(func() *TestMemoryStore { return &TestMemoryStore{} })
+	c.DecorateAll((*TestStore)(nil), func(s TestStore) TestStore { return nil })
+	if _, err := Resolve[TestStore](c); err == nil {
+		t.Error("Resolve should return error when a decorator returns nil")
+	}
+}
+
+// TestDecorateAllLifetimes tests that singletons and scoped instances are decorated once
+func TestDecorateAllLifetimes(t *testing.T) {
+	c := NewContainer()
+	c.Singleton(func() *TestMemoryStore { return &TestMemoryStore{} }, Named("singleton"))
+	c.Scoped(func() *TestMemoryStore { return &TestMemoryStore{} }, Named("scoped"))
+	c.Provide(func() *TestMemoryStore { return &TestMemoryStore{} }, Named("transient"))
+
+	decorations := 0
+	c.DecorateAll((*TestStore)(nil), func(s TestStore) TestStore {
+		decorations++
+		return &TestCachedStore{Next: s}
+	})
+
+	first, _ := ResolveNamed[TestStore](c, "singleton")
+	second, _ := ResolveNamed[TestStore](c.Scope(), "singleton")
+	if first != second || decorations != 1 {
+		t.Errorf("the singleton was decorated %d times, want once", decorations)
+	}
+
+	scope := c.Scope()
+	a, _ := ResolveNamed[TestStore](scope, "scoped")
+	b, _ := ResolveNamed[TestStore](scope, "scoped")
+	other, _ := ResolveNamed[TestStore](c.Scope(), "scoped")
+	if a != b || a == other || decorations != 3 {
+		t.Errorf("the scoped instances were decorated %d times, want once per scope", decorations-1)
+	}
+
+	x, _ := ResolveNamed[TestStore](c, "transient")
+	y, _ := ResolveNamed[TestStore](c, "transient")
+	if x == y || decorations != 5 {
+		t.Errorf("the transient instances were decorated %d times, want twice", decorations-3)
+	}
+
+	// Test that decorators registered later apply to cached instances
+	c.DecorateAll((*TestStore)(nil), func(s TestStore) TestStore { return &TestCachedStore{Next: s} })
+	third, _ := ResolveNamed[TestStore](c, "singleton")
+	if third == first || third.Find(1) != "cached cached " {
+		t.Errorf("ResolveNamed returned %#v, want the singleton decorated twice", third)
+	}
+	if again, _ := ResolveNamed[TestStore](c, "singleton"); again != third {
+		t.Error("ResolveNamed returned a new chain for the singleton")
+	}
+}
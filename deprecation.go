@@ -0,0 +1,138 @@
+package oop
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"sync"
+)
+
+// DeprecationWarning describes the use of a deprecated class or method.
+type DeprecationWarning struct {
+	Type    reflect.Type // The deprecated class.
+	Method  string       // The deprecated method, empty when the whole class is deprecated.
+	Message string       // Migration hint, e.g. "use DogV2".
+	Since   string       // Version the class or method was deprecated in.
+}
+
+// String returns a human-readable description of the warning.
+func (w DeprecationWarning) String() string {
+	name := w.Type.String()
+	if w.Method != "" {
+		name += "." + w.Method
+	}
+
+	s := fmt.Sprintf("%s is deprecated", name)
+	if w.Since != "" {
+		s += " since " + w.Since
+	}
+	if w.Message != "" {
+		s += ": " + w.Message
+	}
+	return s
+}
+
+// deprecationKey identifies a deprecated class (empty method) or method.
+type deprecationKey struct {
+	classType reflect.Type
+	method    string
+}
+
+// deprecation holds the registered warning and its usage state.
+type deprecation struct {
+	warning DeprecationWarning
+	warned  bool // Whether the warning has been emitted.
+	count   int  // Number of times the deprecated member was used.
+}
+
+var (
+	deprecationsMu     sync.Mutex
+	deprecations       = map[deprecationKey]*deprecation{}
+	deprecationHandler = func(w DeprecationWarning) {
+		log.Printf("oop: %s", w)
+	}
+)
+
+// Deprecate marks a class as deprecated.
+// CreateObject emits a one-time warning the first time an object of the class is created.
+// Example: oop.Deprecate(reflect.TypeOf(Dog{}), "use DogV2", "v1.4.0")
+func Deprecate(classType reflect.Type, message, since string) {
+	registerDeprecation(classType, "", message, since)
+}
+
+// DeprecateMethod marks a method of a class as deprecated.
+// Dynamic invocations (BoundMethod.Invoke, BindMethod, CallIdempotent, Shadow)
+// emit a one-time warning the first time the method is called.
+func DeprecateMethod(classType reflect.Type, method, message, since string) {
+	registerDeprecation(classType, method, message, since)
+}
+
+// SetDeprecationHandler replaces the function that receives deprecation warnings.
+// By default warnings are written with the standard logger. Passing nil silences them.
+func SetDeprecationHandler(fn func(DeprecationWarning)) {
+	if fn == nil {
+		fn = func(DeprecationWarning) {}
+	}
+
+	deprecationsMu.Lock()
+	defer deprecationsMu.Unlock()
+	deprecationHandler = fn
+}
+
+// DeprecationCount returns how many times a deprecated class (empty method)
+// or method has been used.
+func DeprecationCount(classType reflect.Type, method string) int {
+	deprecationsMu.Lock()
+	defer deprecationsMu.Unlock()
+
+	if d, ok := deprecations[deprecationKey{classType: classElem(classType), method: method}]; ok {
+		return d.count
+	}
+	return 0
+}
+
+// registerDeprecation records a deprecated class or method.
+func registerDeprecation(classType reflect.Type, method, message, since string) {
+	classType = classElem(classType)
+
+	deprecationsMu.Lock()
+	defer deprecationsMu.Unlock()
+
+	deprecations[deprecationKey{classType: classType, method: method}] = &deprecation{
+		warning: DeprecationWarning{
+			Type:    classType,
+			Method:  method,
+			Message: message,
+			Since:   since,
+		},
+	}
+}
+
+// warnDeprecated counts a use of a class or method and emits its warning once.
+func warnDeprecated(classType reflect.Type, method string) {
+	deprecationsMu.Lock()
+	d, ok := deprecations[deprecationKey{classType: classElem(classType), method: method}]
+	if !ok {
+		deprecationsMu.Unlock()
+		return
+	}
+
+	d.count++
+	emit := !d.warned
+	d.warned = true
+	handler := deprecationHandler
+	warning := d.warning
+	deprecationsMu.Unlock()
+
+	if emit {
+		handler(warning)
+	}
+}
+
+// classElem returns the struct type behind a pointer type.
+func classElem(t reflect.Type) reflect.Type {
+	if t != nil && t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
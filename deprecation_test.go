@@ -0,0 +1,73 @@
+package oop
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestOldDog is a test struct used to test deprecation
+type TestOldDog struct {
+	Name string
+}
+
+// Bark is a deprecated method
+func (d *TestOldDog) Bark() string {
+	return d.Name + ": Woof!"
+}
+
+// TestDeprecate tests the Deprecate and DeprecateMethod functions
+func TestDeprecate(t *testing.T) {
+	warnings := []DeprecationWarning{}
+	SetDeprecationHandler(func(w DeprecationWarning) {
+		warnings = append(warnings, w)
+	})
+	defer SetDeprecationHandler(nil)
+
+	classType := reflect.TypeOf(TestOldDog{})
+	Deprecate(classType, "use TestDog", "v1.2.0")
+	DeprecateMethod(classType, "Bark", "use Sound", "v1.3.0")
+
+	factory := NewObjectFactory()
+	factory.CreateObject(&TestOldDog{Name: "Buddy"})
+	factory.CreateObject(&TestOldDog{Name: "Rex"})
+
+	// Test that the class warning is emitted once and counted twice
+	if len(warnings) != 1 {
+		t.Fatalf("got %d warnings, want 1", len(warnings))
+	}
+	if warnings[0].Method != "" || warnings[0].Since != "v1.2.0" {
+		t.Errorf("got wrong warning: %+v", warnings[0])
+	}
+	if DeprecationCount(classType, "") != 2 {
+		t.Errorf("DeprecationCount returned %d, want 2", DeprecationCount(classType, ""))
+	}
+
+	// Test method deprecation
+	bark, err := Method(&TestOldDog{Name: "Max"}, "Bark")
+	if err != nil {
+		t.Fatalf("Method returned error: %v", err)
+	}
+	bark.Invoke()
+	bark.Invoke()
+
+	if len(warnings) != 2 {
+		t.Fatalf("got %d warnings, want 2", len(warnings))
+	}
+	if warnings[1].Method != "Bark" {
+		t.Errorf("got wrong warning: %+v", warnings[1])
+	}
+	if DeprecationCount(reflect.TypeOf(&TestOldDog{}), "Bark") != 2 {
+		t.Errorf("DeprecationCount returned %d, want 2", DeprecationCount(classType, "Bark"))
+	}
+
+	// Test warning message
+	if !strings.Contains(warnings[1].String(), "Bark is deprecated since v1.3.0: use Sound") {
+		t.Errorf("String returned %q", warnings[1].String())
+	}
+
+	// Test that other classes are not affected
+	if DeprecationCount(reflect.TypeOf(TestDog{}), "") != 0 {
+		t.Error("DeprecationCount should return 0 for classes that are not deprecated")
+	}
+}
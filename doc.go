@@ -0,0 +1,29 @@
+// Package oop implements object-oriented programming patterns in Go:
+// classes with runtime metadata, virtual method tables, inheritance,
+// dynamic casting and a user-friendly object factory.
+//
+// # Concurrency
+//
+// The package is safe for concurrent use with the following contract:
+//
+//   - The class registry (Register, Lookup, Extend, RegisterConstructor) and
+//     the metadata built lazily on a ClassInfo (vtables, hooks, constructors)
+//     are guarded by locks. Classes can be registered and looked up from
+//     many goroutines.
+//   - New, Cast, As and ObjectFactory.CreateObject can be called concurrently.
+//     Whether a type implements an interface is cached process-wide in an
+//     immutable snapshot that is replaced atomically, so Cast, IsInstanceOf
+//     and Vtable lookups never block each other. Cast plans are cached per
+//     (concrete type, target type) pair in a sync.Map.
+//     The memory budget and intern table of an ObjectFactory are synchronized.
+//   - ObjectWrapper methods can be called concurrently on the same object.
+//     Mutations (SetFields, ApplyAtomic, FieldRef setters, Destroy) are
+//     serialized, and Destroy waits for in-flight reads of the object.
+//   - The exported fields of ClassInfo (Deinit, IsClass, ...) and of Klass are
+//     configuration: set them before the class or instance is shared.
+//   - Klass.Deinit must be called once per instance; use ObjectWrapper.Destroy
+//     when several goroutines may destroy the same object.
+//   - The fields of the underlying objects are not synchronized by the package.
+//     Code reading them directly while other goroutines write them must use
+//     its own locking, or go through ApplyAtomic.
+package oop
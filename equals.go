@@ -0,0 +1,388 @@
+package oop
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Equaler is implemented by classes that define their own equality.
+// Equals and HashCode use it instead of comparing fields; classes
+// implementing Equaler should implement Hasher consistently.
+type Equaler interface {
+	Equals(other any) bool
+}
+
+// Hasher is implemented by classes that define their own hash code.
+// Objects that are equal must have the same hash code.
+type Hasher interface {
+	HashCode() uint64
+}
+
+var (
+	equalerType = reflect.TypeOf((*Equaler)(nil)).Elem()
+	hasherType  = reflect.TypeOf((*Hasher)(nil)).Elem()
+)
+
+// EqualOption configures how Equals and HashCode compare objects.
+type EqualOption func(*equalOptions)
+
+// equalOptions holds the options of a single Equals or HashCode call.
+type equalOptions struct {
+	ignoreTag string
+}
+
+// WithIgnoreTag makes Equals and HashCode skip the fields whose given struct
+// tag is "-", e.g. WithIgnoreTag("json") skips fields tagged json:"-".
+// Example: oop.Equals(a, b, oop.WithIgnoreTag("equals"))
+func WithIgnoreTag(tag string) EqualOption {
+	return func(o *equalOptions) {
+		o.ignoreTag = tag
+	}
+}
+
+// Equals reports whether two objects are deeply equal. Objects implementing
+// Equaler decide themselves; otherwise the exported fields of structs are
+// compared, pointers are followed, and slices and maps are compared by content.
+// Unexported fields are ignored, but structs whose fields are all unexported,
+// such as time.Time, are compared with their Equal method if they have one
+// and as whole values otherwise. Cyclic graphs are compared by shape: a
+// reference back to an object on the current path must go back as far in
+// both objects, so a ring of one node does not equal a ring of two. Equal
+// objects have the same HashCode, so objects can be used as keys of
+// hash-based sets and maps.
+// Example: oop.Equals(dog, otherDog)
+func Equals(a, b any, opts ...EqualOption) bool {
+	e := &equalState{options: newEqualOptions(opts), pathA: map[refKey]int{}, pathB: map[refKey]int{}}
+	return e.equal(reflect.ValueOf(a), reflect.ValueOf(b))
+}
+
+// HashCode returns a hash of an object that is consistent with Equals.
+// Objects implementing Hasher return their own hash code.
+// Example: key := oop.HashCode(dog)
+func HashCode(obj any, opts ...EqualOption) uint64 {
+	h := &hashState{options: newEqualOptions(opts), hash: fnv.New64a(), visiting: map[refKey]int{}}
+	h.write(reflect.ValueOf(obj))
+	return h.hash.Sum64()
+}
+
+// newEqualOptions applies the options of an Equals or HashCode call.
+func newEqualOptions(opts []EqualOption) equalOptions {
+	options := equalOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// equalFieldsKey identifies the compared fields of a struct type.
+type equalFieldsKey struct {
+	typ       reflect.Type
+	ignoreTag string
+}
+
+// equalFieldsCache caches the indexes of the compared fields of struct types.
+var equalFieldsCache sync.Map // equalFieldsKey -> []int
+
+// equalFields returns the indexes of the exported fields of a struct type
+// that are not ignored by the options.
+func equalFields(t reflect.Type, opts equalOptions) []int {
+	key := equalFieldsKey{typ: t, ignoreTag: opts.ignoreTag}
+	if fields, ok := equalFieldsCache.Load(key); ok {
+		return fields.([]int)
+	}
+
+	var fields []int
+	for _, field := range exportedFields(t) {
+		if opts.ignoreTag != "" && field.Tag.Get(opts.ignoreTag) == "-" {
+			continue
+		}
+		fields = append(fields, field.Index[0])
+	}
+
+	equalFieldsCache.Store(key, fields)
+	return fields
+}
+
+// equalState holds the state of a single Equals call.
+type equalState struct {
+	options      equalOptions
+	pathA, pathB map[refKey]int // Depth of the pointers, maps and slices on the current path of each side, to end cycles.
+	diverged     int            // Number of depths at which the paths refer to different memory.
+}
+
+// equal reports whether two values are deeply equal.
+func (e *equalState) equal(a, b reflect.Value) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() == b.IsValid()
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+	if eq, ok := implementation[Equaler](a, equalerType); ok {
+		return eq.Equals(b.Interface())
+	}
+
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		if a.Kind() == reflect.Slice && a.Len() != b.Len() {
+			return false
+		}
+		ka, kb := newRefKey(a), newRefKey(b)
+		da, onA := e.pathA[ka]
+		db, onB := e.pathB[kb]
+		if onA || onB {
+			return onA && onB && da == db // Both sides must close the cycle at the same depth.
+		}
+		if ka == kb && e.diverged == 0 {
+			return true // Same memory reached by the same path, so cycles close alike.
+		}
+		depth := len(e.pathA)
+		e.pathA[ka], e.pathB[kb] = depth, depth
+		if ka != kb {
+			e.diverged++
+		}
+		defer func() {
+			delete(e.pathA, ka)
+			delete(e.pathB, kb)
+			if ka != kb {
+				e.diverged--
+			}
+		}()
+
+		switch a.Kind() {
+		case reflect.Ptr:
+			return e.equal(a.Elem(), b.Elem())
+		case reflect.Map:
+			if a.Len() != b.Len() {
+				return false
+			}
+			for iter := a.MapRange(); iter.Next(); {
+				bv := b.MapIndex(iter.Key())
+				if !bv.IsValid() || !e.equal(iter.Value(), bv) {
+					return false
+				}
+			}
+			return true
+		default:
+			return e.equalElements(a, b)
+		}
+	case reflect.Array:
+		return e.equalElements(a, b)
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return e.equal(a.Elem(), b.Elem())
+	case reflect.Struct:
+		if opaqueStruct(a.Type()) {
+			return equalOpaque(a, b)
+		}
+		for _, i := range equalFields(a.Type(), e.options) {
+			if !e.equal(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Func:
+		return a.IsNil() && b.IsNil()
+	case reflect.Chan, reflect.UnsafePointer:
+		return a.Pointer() == b.Pointer()
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() == b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() == b.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return a.Complex() == b.Complex()
+	case reflect.String:
+		return a.String() == b.String()
+	default:
+		return false
+	}
+}
+
+// equalElements reports whether the elements of two slices or arrays of the
+// same length are deeply equal.
+func (e *equalState) equalElements(a, b reflect.Value) bool {
+	for i := range a.Len() {
+		if !e.equal(a.Index(i), b.Index(i)) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalOpaque compares two structs whose fields are all unexported, with
+// their Equal(T) bool method if they have one, e.g. time.Time, and as whole
+// values otherwise.
+func equalOpaque(a, b reflect.Value) bool {
+	recv := a
+	if a.CanAddr() {
+		recv = a.Addr() // Finds methods with pointer receivers too.
+	}
+	if m := recv.MethodByName("Equal"); m.IsValid() {
+		t := m.Type()
+		if t.NumIn() == 1 && t.In(0) == a.Type() && t.NumOut() == 1 && t.Out(0).Kind() == reflect.Bool {
+			return m.Call([]reflect.Value{b})[0].Bool()
+		}
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// hashState holds the state of a single HashCode call.
+type hashState struct {
+	options  equalOptions
+	hash     hash.Hash64
+	visiting map[refKey]int // Depth of the pointers, maps and slices on the current path, to end cycles.
+	buf      [8]byte
+}
+
+// writeUint64 adds a number to the hash.
+func (h *hashState) writeUint64(n uint64) {
+	binary.BigEndian.PutUint64(h.buf[:], n)
+	h.hash.Write(h.buf[:])
+}
+
+// write adds a value to the hash, consistently with equalState.equal.
+func (h *hashState) write(v reflect.Value) {
+	if !v.IsValid() {
+		h.writeUint64(0)
+		return
+	}
+	if hasher, ok := implementation[Hasher](v, hasherType); ok {
+		h.writeUint64(hasher.HashCode())
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			h.writeUint64(0)
+			return
+		}
+		// References back up the current path are hashed by how far they go
+		// back, as Equals compares them.
+		key := newRefKey(v)
+		if depth, ok := h.visiting[key]; ok {
+			h.writeUint64(uint64(depth))
+			return
+		}
+		h.visiting[key] = len(h.visiting)
+		h.writeReferenced(v)
+		delete(h.visiting, key)
+	case reflect.Interface:
+		if v.IsNil() {
+			h.writeUint64(0)
+			return
+		}
+		h.hash.Write([]byte(v.Elem().Type().String()))
+		h.write(v.Elem())
+	case reflect.Struct:
+		if opaqueStruct(v.Type()) {
+			h.writeOpaque(v)
+			return
+		}
+		for _, i := range equalFields(v.Type(), h.options) {
+			h.write(v.Field(i))
+		}
+	case reflect.Array:
+		h.writeElements(v)
+	case reflect.Bool:
+		if v.Bool() {
+			h.writeUint64(1)
+		} else {
+			h.writeUint64(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		h.writeUint64(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		h.writeUint64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		h.writeUint64(hashFloat(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		h.writeUint64(hashFloat(real(v.Complex())))
+		h.writeUint64(hashFloat(imag(v.Complex())))
+	case reflect.String:
+		h.hash.Write([]byte(v.String()))
+	case reflect.Chan, reflect.UnsafePointer:
+		h.writeUint64(uint64(v.Pointer()))
+	default:
+		h.writeUint64(0) // Functions are only equal when nil.
+	}
+}
+
+// writeOpaque adds a struct whose fields are all unexported to the hash by its
+// marshaled form, see CanonicalBytes, or by its type if it has none. Times are
+// hashed in UTC, as equal instants in other locations marshal differently.
+func (h *hashState) writeOpaque(v reflect.Value) {
+	h.hash.Write([]byte(v.Type().String()))
+	if t, ok := v.Interface().(time.Time); ok {
+		v = reflect.ValueOf(t.UTC())
+	}
+	if data, ok, err := marshaledForm(v); ok && err == nil {
+		h.hash.Write(data)
+	}
+}
+
+// writeReferenced adds the target of a non-nil pointer, map or slice to the hash.
+func (h *hashState) writeReferenced(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		h.write(v.Elem())
+	case reflect.Map:
+		// Entries are hashed separately and summed, so the order does not matter.
+		var sum uint64
+		for iter := v.MapRange(); iter.Next(); {
+			entry := &hashState{options: h.options, hash: fnv.New64a(), visiting: h.visiting}
+			entry.write(iter.Key())
+			entry.write(iter.Value())
+			sum += entry.hash.Sum64()
+		}
+		h.writeUint64(uint64(v.Len()))
+		h.writeUint64(sum)
+	default:
+		h.writeElements(v)
+	}
+}
+
+// writeElements adds the length and elements of a slice or array to the hash.
+func (h *hashState) writeElements(v reflect.Value) {
+	h.writeUint64(uint64(v.Len()))
+	for i := range v.Len() {
+		h.write(v.Index(i))
+	}
+}
+
+// hashFloat returns the bits of a float, with -0 normalized to 0 since they are equal.
+func hashFloat(f float64) uint64 {
+	if f == 0 {
+		return 0
+	}
+	return math.Float64bits(f)
+}
+
+// implementation returns the value as an I if its type implements the
+// interface and the value can be used, i.e. it is exported and not a nil pointer.
+// Pointers whose element type implements the interface are left to be
+// dereferenced, so value receivers see values of their own type.
+func implementation[I any](v reflect.Value, iface reflect.Type) (I, bool) {
+	var zero I
+	if v.Kind() == reflect.Interface || !v.CanInterface() || !implements(v.Type(), iface) {
+		return zero, false
+	}
+	if v.Kind() == reflect.Ptr && (v.IsNil() || implements(v.Type().Elem(), iface)) {
+		return zero, false // Value receivers are called on the dereferenced value.
+	}
+	return v.Interface().(I), true
+}
@@ -0,0 +1,205 @@
+package oop
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMoney is a test class implementing Equaler and Hasher
+type TestMoney struct {
+	Amount   int
+	Currency string
+}
+
+// Equals implements Equaler, comparing currencies case-insensitively
+func (m TestMoney) Equals(other any) bool {
+	o, ok := other.(TestMoney)
+	return ok && m.Amount == o.Amount && strings.EqualFold(m.Currency, o.Currency)
+}
+
+// HashCode implements Hasher consistently with Equals
+func (m TestMoney) HashCode() uint64 {
+	return HashCode(strings.ToUpper(m.Currency)) ^ uint64(m.Amount)
+}
+
+// TestLedger is a test class compared field by field
+type TestLedger struct {
+	Owner   string
+	Balance TestMoney
+	Tags    map[string]int
+	History []float64
+	Parent  *TestLedger
+	Cache   string `equals:"-"`
+	note    string
+}
+
+// TestEquals tests the Equals function
+func TestEquals(t *testing.T) {
+	newLedger := func() *TestLedger {
+		return &TestLedger{
+			Owner:   "Alice",
+			Balance: TestMoney{Amount: 10, Currency: "EUR"},
+			Tags:    map[string]int{"a": 1, "b": 2},
+			History: []float64{1.5, 0},
+		}
+	}
+
+	a, b := newLedger(), newLedger()
+	a.note, b.note = "x", "y"
+	if !Equals(a, b) {
+		t.Error("ledgers with the same exported fields should be equal")
+	}
+	if !Equals(*a, *b) {
+		t.Error("ledger values with the same exported fields should be equal")
+	}
+
+	// Test Equaler
+	b.Balance.Currency = "eur"
+	b.History[1] = math.Copysign(0, -1)
+	if !Equals(a, b) {
+		t.Error("Equals should use Equaler and treat -0 as 0")
+	}
+
+	// Test differences
+	b.Tags["b"] = 3
+	if Equals(a, b) {
+		t.Error("ledgers with different maps should not be equal")
+	}
+	b.Tags["b"] = 2
+	b.History = append(b.History, 1)
+	if Equals(a, b) {
+		t.Error("ledgers with different slices should not be equal")
+	}
+	if !Equals(&TestMoney{1, "usd"}, &TestMoney{1, "USD"}) {
+		t.Error("Equals should call value receivers through pointers")
+	}
+	if Equals(a, &TestDog{}) || Equals(a, nil) || !Equals(nil, nil) {
+		t.Error("Equals should compare types and nil")
+	}
+
+	// Test ignored fields
+	b = newLedger()
+	b.Cache = "stale"
+	if Equals(a, b) {
+		t.Error("Equals should compare tagged fields by default")
+	}
+	if !Equals(a, b, WithIgnoreTag("equals")) {
+		t.Error("Equals should skip fields ignored by tag")
+	}
+
+	// Test cycles
+	a.Parent, b.Parent = a, b
+	b.Cache = ""
+	if !Equals(a, b) {
+		t.Error("Equals should compare cyclic graphs")
+	}
+}
+
+// TestHashCode tests the HashCode function
+func TestHashCode(t *testing.T) {
+	a := &TestLedger{Owner: "Alice", Balance: TestMoney{10, "EUR"}, Tags: map[string]int{"a": 1, "b": 2, "c": 3}}
+	b := &TestLedger{Owner: "Alice", Balance: TestMoney{10, "eur"}, Tags: map[string]int{"c": 3, "b": 2, "a": 1}}
+	a.Parent, b.Parent = a, b
+
+	if HashCode(a) != HashCode(b) {
+		t.Error("equal objects should have the same hash code")
+	}
+	if HashCode(a) != HashCode(a) {
+		t.Error("HashCode should be deterministic")
+	}
+
+	b.Owner = "Bob"
+	if HashCode(a) == HashCode(b) {
+		t.Error("different objects should have different hash codes")
+	}
+
+	b.Owner, b.Cache = "Alice", "stale"
+	if HashCode(a) == HashCode(b) {
+		t.Error("HashCode should hash tagged fields by default")
+	}
+	if HashCode(a, WithIgnoreTag("equals")) != HashCode(b, WithIgnoreTag("equals")) {
+		t.Error("HashCode should skip fields ignored by tag")
+	}
+
+	// Test using objects as set members
+	set := map[uint64][]any{}
+	for _, obj := range []any{TestMoney{1, "usd"}, TestMoney{1, "USD"}, TestMoney{2, "USD"}} {
+		key := HashCode(obj)
+		found := false
+		for _, member := range set[key] {
+			found = found || Equals(member, obj)
+		}
+		if !found {
+			set[key] = append(set[key], obj)
+		}
+	}
+	if len(set) != 2 {
+		t.Errorf("set has %d members, want 2", len(set))
+	}
+}
+
+// TestEqualsCycles tests that Equals and HashCode agree on cyclic graphs
+func TestEqualsCycles(t *testing.T) {
+	// A ring of one ledger and a ring of two have the same fields
+	one := &TestLedger{Owner: "Alice"}
+	one.Parent = one
+	two := &TestLedger{Owner: "Alice", Parent: &TestLedger{Owner: "Alice"}}
+	two.Parent.Parent = two
+	if Equals(one, two) || Equals(two, one) {
+		t.Error("Equals should tell cycles of different lengths apart")
+	}
+
+	other := &TestLedger{Owner: "Alice", Parent: &TestLedger{Owner: "Alice"}}
+	other.Parent.Parent = other
+	if !Equals(two, other) || HashCode(two) != HashCode(other) {
+		t.Error("cycles of the same shape should be equal and have the same hash code")
+	}
+
+	// Test cycles through maps and slices
+	a := []any{1, nil}
+	a[1] = a
+	b := []any{1, nil}
+	b[1] = b
+	if !Equals(a, b) || HashCode(a) != HashCode(b) {
+		t.Error("cyclic slices of the same shape should be equal and have the same hash code")
+	}
+	m := map[string]any{"n": 1}
+	m["self"] = m
+	n := map[string]any{"n": 1}
+	n["self"] = n
+	if !Equals(m, n) || HashCode(m) != HashCode(n) {
+		t.Error("cyclic maps of the same shape should be equal and have the same hash code")
+	}
+}
+
+// TestEqualsVisit is a test class holding a timestamp
+type TestEqualsVisit struct {
+	Who string
+	At  time.Time
+}
+
+// TestEqualsTimestamps tests structs whose fields are all unexported
+func TestEqualsTimestamps(t *testing.T) {
+	at := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	a := TestEqualsVisit{Who: "vet", At: at}
+	b := TestEqualsVisit{Who: "vet", At: at.Add(time.Hour)}
+	if Equals(a, b) || HashCode(a) == HashCode(b) {
+		t.Error("Equals and HashCode should tell timestamps apart")
+	}
+
+	// Test that the same instant in another location is equal, through Equal
+	b.At = at.In(time.FixedZone("CEST", 2*60*60))
+	if !Equals(a, b) || HashCode(a) != HashCode(b) {
+		t.Error("the same instant should be equal and have the same hash code")
+	}
+
+	// Test comparing whole values without an Equal method
+	if Equals(TestCanonicalSecret{value: 1}, TestCanonicalSecret{value: 2}) {
+		t.Error("Equals should compare unexported fields of opaque structs")
+	}
+	if !Equals(TestCanonicalSecret{value: 1}, TestCanonicalSecret{value: 1}) {
+		t.Error("Equals should find equal opaque structs equal")
+	}
+}
@@ -0,0 +1,137 @@
+package oop
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// EventBus delivers events to the subscribers of their type, decoupling the
+// objects that publish events from those that handle them. Unlike signals,
+// see DefineSignal, publishers and subscribers only share the event type.
+// The zero value is ready to use.
+type EventBus struct {
+	mu          sync.Mutex
+	subscribers map[reflect.Type][]*subscriber // Subscribers keyed by event type.
+	next        uint64
+	closed      bool // Whether the bus was closed, see ObjectWrapper.Events.
+}
+
+// subscriber is a handler subscribed to an EventBus.
+type subscriber struct {
+	order uint64        // When the handler was subscribed, for delivery order.
+	fn    reflect.Value // func(E)
+}
+
+// Subscription is a handler subscribed to an EventBus, see Subscribe.
+type Subscription struct {
+	bus       *EventBus
+	eventType reflect.Type
+	sub       *subscriber
+}
+
+// DefaultEventBus is the bus used by Subscribe and Publish.
+var DefaultEventBus = &EventBus{}
+
+// Subscribe subscribes a handler to the events of type E published on
+// DefaultEventBus, see SubscribeTo.
+// Example: sub := oop.Subscribe(func(e UserCreated) { sendWelcome(e.Email) })
+func Subscribe[E any](fn func(E)) Subscription {
+	return SubscribeTo(DefaultEventBus, fn)
+}
+
+// Publish publishes an event on DefaultEventBus, see PublishTo.
+// Example: oop.Publish(UserCreated{Email: "ann@example.com"})
+func Publish[E any](event E) {
+	PublishTo(DefaultEventBus, event)
+}
+
+// SubscribeTo subscribes a handler to the events of type E published on a
+// bus. If E is an interface type, the handler also receives the events of the
+// types implementing it. Subscribing to a closed bus does nothing.
+// Example: sub := oop.SubscribeTo(userObj.Events(), func(e Renamed) { log.Println(e.Name) })
+func SubscribeTo[E any](bus *EventBus, fn func(E)) Subscription {
+	if bus == nil || fn == nil {
+		return Subscription{}
+	}
+
+	eventType := reflect.TypeFor[E]()
+
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	if bus.closed {
+		return Subscription{}
+	}
+	if bus.subscribers == nil {
+		bus.subscribers = map[reflect.Type][]*subscriber{}
+	}
+	bus.next++
+	sub := &subscriber{order: bus.next, fn: reflect.ValueOf(fn)}
+	bus.subscribers[eventType] = append(bus.subscribers[eventType], sub)
+	return Subscription{bus: bus, eventType: eventType, sub: sub}
+}
+
+// PublishTo calls the handlers subscribed to events of type E on a bus, and
+// those subscribed to interface types E implements, in the order they
+// subscribed. Handlers run synchronously, without holding the bus lock, so
+// they may publish events and subscribe themselves.
+// Example: oop.PublishTo(userObj.Events(), Renamed{Name: "Ann"})
+func PublishTo[E any](bus *EventBus, event E) {
+	if bus == nil {
+		return
+	}
+
+	eventType := reflect.TypeFor[E]()
+
+	bus.mu.Lock()
+	var subs []*subscriber
+	for t, handlers := range bus.subscribers {
+		if t == eventType || (t.Kind() == reflect.Interface && implements(eventType, t)) {
+			subs = append(subs, handlers...)
+		}
+	}
+	bus.mu.Unlock()
+
+	slices.SortFunc(subs, func(a, b *subscriber) int { return cmp.Compare(a.order, b.order) })
+	in := reflect.ValueOf(&event).Elem()
+	for _, sub := range subs {
+		sub.fn.Call([]reflect.Value{in})
+	}
+}
+
+// Unsubscribe removes the handler from the bus. Unsubscribing again does nothing.
+// Example: sub.Unsubscribe()
+func (s Subscription) Unsubscribe() {
+	if s.bus == nil {
+		return
+	}
+
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	if s.bus.subscribers == nil {
+		return // Closed.
+	}
+	s.bus.subscribers[s.eventType] = slices.DeleteFunc(s.bus.subscribers[s.eventType], func(other *subscriber) bool { return other == s.sub })
+}
+
+// close unsubscribes all handlers and ignores later subscriptions.
+func (bus *EventBus) close() {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	bus.subscribers = nil
+	bus.closed = true
+}
+
+// Events returns the event bus of the object, created on first use, for
+// events scoped to the object. The bus is closed when the object is
+// destroyed: its handlers are unsubscribed, and later subscriptions do nothing.
+// Example: oop.SubscribeTo(orderObj.Events(), func(e Shipped) { notify(e) })
+func (o *ObjectWrapper) Events() *EventBus {
+	o.hooksMu.Lock()
+	defer o.hooksMu.Unlock()
+	if o.events == nil {
+		o.events = &EventBus{closed: o.State() == StateDestroyed}
+	}
+	return o.events
+}
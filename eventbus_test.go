@@ -0,0 +1,86 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// TestShipped is a test event
+type TestShipped struct {
+	Order string
+}
+
+// String implements fmt.Stringer
+func (e TestShipped) String() string {
+	return "shipped " + e.Order
+}
+
+// TestCancelled is another test event
+type TestCancelled struct {
+	Order string
+}
+
+// TestEventBus tests subscribing to and publishing typed events
+func TestEventBus(t *testing.T) {
+	bus := &EventBus{}
+
+	var got []string
+	shipped := SubscribeTo(bus, func(e TestShipped) { got = append(got, "shipped:"+e.Order) })
+	SubscribeTo(bus, func(e fmt.Stringer) { got = append(got, "stringer:"+e.String()) })
+	SubscribeTo(bus, func(e TestCancelled) { got = append(got, "cancelled:"+e.Order) })
+
+	PublishTo(bus, TestShipped{Order: "o1"})
+	PublishTo(bus, TestCancelled{Order: "o2"})
+	want := []string{"shipped:o1", "stringer:shipped o1", "cancelled:o2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("the handlers received %v, want %v", got, want)
+	}
+
+	// Test that unsubscribed handlers are not called
+	shipped.Unsubscribe()
+	shipped.Unsubscribe()
+	got = nil
+	PublishTo(bus, TestShipped{Order: "o3"})
+	if want := []string{"stringer:shipped o3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("the handlers received %v, want %v", got, want)
+	}
+
+	// Test the default bus
+	var received []TestCancelled
+	sub := Subscribe(func(e TestCancelled) { received = append(received, e) })
+	Publish(TestCancelled{Order: "o4"})
+	sub.Unsubscribe()
+	Publish(TestCancelled{Order: "o5"})
+	if len(received) != 1 || received[0].Order != "o4" {
+		t.Errorf("the default bus delivered %v", received)
+	}
+}
+
+// TestObjectEvents tests the event bus scoped to an object
+func TestObjectEvents(t *testing.T) {
+	factory := NewObjectFactory()
+	order := factory.CreateObject(&TestDog{Name: "Rex"})
+	other := factory.CreateObject(&TestDog{Name: "Max"})
+	defer other.Destroy()
+
+	if order.Events() != order.Events() {
+		t.Error("Events returned different buses")
+	}
+	calls := 0
+	sub := SubscribeTo(order.Events(), func(e TestShipped) { calls++ })
+	PublishTo(other.Events(), TestShipped{Order: "o1"})
+	PublishTo(order.Events(), TestShipped{Order: "o1"})
+	if calls != 1 {
+		t.Errorf("the handler was called %d times, want 1", calls)
+	}
+
+	// Test that the bus is closed on Destroy
+	order.Destroy()
+	sub.Unsubscribe()
+	SubscribeTo(order.Events(), func(e TestShipped) { calls++ })
+	PublishTo(order.Events(), TestShipped{Order: "o2"})
+	if calls != 1 {
+		t.Errorf("the handler was called %d times after Destroy, want 1", calls)
+	}
+}
@@ -0,0 +1,140 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// FieldIter iterates over the exported fields of an object.
+// Example: for field := range dogObj.Fields() { fmt.Println(field.Name, field.Object()) }
+type FieldIter func(yield func(FieldRef) bool)
+
+// FieldRef is a reference to an exported field of a wrapped object.
+// Its typed accessors read and write the field without looking it up by name again.
+type FieldRef struct {
+	Name  string       // Name of the field.
+	Type  reflect.Type // Type of the field.
+	Index int          // Index of the field in the struct.
+
+	value reflect.Value  // The settable field value.
+	obj   *ObjectWrapper // The object the field belongs to, locked by the setters.
+}
+
+// fieldCache caches the exported fields of struct types.
+var fieldCache = struct {
+	mu     sync.RWMutex
+	fields map[reflect.Type][]reflect.StructField
+}{
+	fields: map[reflect.Type][]reflect.StructField{},
+}
+
+// Fields returns an iterator over the exported fields of the object, in declaration order.
+// It yields nothing if the object is not a pointer to a struct or is soft-deleted.
+func (o *ObjectWrapper) Fields() FieldIter {
+	return func(yield func(FieldRef) bool) {
+		if o.IsSoftDeleted() {
+			return
+		}
+		target, err := o.structValue()
+		if err != nil {
+			return
+		}
+
+		for _, field := range exportedFields(target.Type()) {
+			ref := FieldRef{
+				Name:  field.Name,
+				Type:  field.Type,
+				Index: field.Index[0],
+				value: target.Field(field.Index[0]),
+				obj:   o,
+			}
+			if !yield(ref) {
+				return
+			}
+		}
+	}
+}
+
+// Int returns the value of an integer field, or 0 if the field is not an integer.
+func (f FieldRef) Int() int64 {
+	switch f.value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return f.value.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return int64(f.value.Uint())
+	default:
+		return 0
+	}
+}
+
+// SetInt sets the value of a numeric field.
+func (f FieldRef) SetInt(value int64) error {
+	return f.SetObject(value)
+}
+
+// String returns the value of a string field. Other fields are formatted with fmt.
+func (f FieldRef) String() string {
+	if f.value.Kind() == reflect.String {
+		return f.value.String()
+	}
+	return fmt.Sprint(f.value.Interface())
+}
+
+// SetString sets the value of a string field.
+func (f FieldRef) SetString(value string) error {
+	return f.SetObject(value)
+}
+
+// Object returns the value of the field.
+func (f FieldRef) Object() interface{} {
+	return f.value.Interface()
+}
+
+// SetObject sets the value of the field.
+// Values are converted like SetFields values: nil becomes the zero value and
+// numeric values are converted between numeric types. A value that violates a
+// unique index, see CreateIndex, is reported as a *UniqueViolationError.
+func (f FieldRef) SetObject(value interface{}) error {
+	converted, err := fieldValue(f.Type, value)
+	if err != nil {
+		return fmt.Errorf("field %s: %w", f.Name, err)
+	}
+
+	f.obj.mu.Lock()
+	defer f.obj.mu.Unlock()
+	if err := f.obj.checkMutable(); err != nil {
+		return err
+	}
+	target, err := f.obj.structValue()
+	if err != nil {
+		return err
+	}
+	return f.obj.indexedWrite(target, pendingField([]int{f.Index}, converted), func() error {
+		setField(target, []int{f.Index}, converted)
+		return nil
+	})
+}
+
+// exportedFields returns the exported fields of a struct type.
+// The fields are computed once per type.
+func exportedFields(structType reflect.Type) []reflect.StructField {
+	fieldCache.mu.RLock()
+	fields, ok := fieldCache.fields[structType]
+	fieldCache.mu.RUnlock()
+	if ok {
+		return fields
+	}
+
+	fields = make([]reflect.StructField, 0, structType.NumField())
+	for i := range structType.NumField() {
+		if field := structType.Field(i); field.IsExported() {
+			fields = append(fields, field)
+		}
+	}
+
+	fieldCache.mu.Lock()
+	fieldCache.fields[structType] = fields
+	fieldCache.mu.Unlock()
+	return fields
+}
@@ -0,0 +1,79 @@
+package oop
+
+import (
+	"testing"
+)
+
+// TestFieldsStruct is a test class with fields of several types
+type TestFieldsStruct struct {
+	Name   string
+	Age    uint8
+	Tags   []string
+	hidden int
+}
+
+// TestFields tests the Fields method of ObjectWrapper
+func TestFields(t *testing.T) {
+	obj := NewObjectFactory().CreateObject(&TestFieldsStruct{Name: "Rex", Age: 3, hidden: 1})
+	defer obj.Destroy()
+
+	var names []string
+	for field := range obj.Fields() {
+		names = append(names, field.Name)
+
+		switch field.Name {
+		case "Name":
+			if field.String() != "Rex" {
+				t.Errorf("Name is %q, want %q", field.String(), "Rex")
+			}
+			if err := field.SetString("Max"); err != nil {
+				t.Errorf("SetString returned error: %v", err)
+			}
+			if err := field.SetInt(1); err == nil {
+				t.Error("SetInt should return error for a string field")
+			}
+		case "Age":
+			if field.Int() != 3 || field.String() != "3" {
+				t.Errorf("Age is %d (%q), want 3", field.Int(), field.String())
+			}
+			if err := field.SetInt(4); err != nil {
+				t.Errorf("SetInt returned error: %v", err)
+			}
+		case "Tags":
+			if field.Int() != 0 {
+				t.Errorf("Int returned %d for a slice field, want 0", field.Int())
+			}
+			if err := field.SetObject([]string{"good"}); err != nil {
+				t.Errorf("SetObject returned error: %v", err)
+			}
+			if tags, ok := field.Object().([]string); !ok || len(tags) != 1 {
+				t.Errorf("Object returned %v, want [good]", field.Object())
+			}
+		}
+	}
+
+	if len(names) != 3 || names[0] != "Name" || names[1] != "Age" || names[2] != "Tags" {
+		t.Errorf("Fields yielded %v, want [Name Age Tags]", names)
+	}
+
+	s := obj.GetUnderlyingObject().(*TestFieldsStruct)
+	if s.Name != "Max" || s.Age != 4 || s.Tags[0] != "good" || s.hidden != 1 {
+		t.Errorf("Fields were not updated correctly, got %+v", s)
+	}
+
+	// Test stopping early
+	count := 0
+	for range obj.Fields() {
+		count++
+		break
+	}
+	if count != 1 {
+		t.Errorf("Fields yielded %d fields after break, want 1", count)
+	}
+
+	// Test with a destroyed object
+	obj.Destroy()
+	for field := range obj.Fields() {
+		t.Errorf("Fields yielded %s for a destroyed object", field.Name)
+	}
+}
@@ -0,0 +1,79 @@
+package oop
+
+import (
+	"log"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// leakDebug reports whether leaked objects are logged, see SetLeakDebug.
+var leakDebug atomic.Bool
+
+// SetLeakDebug turns the leak debug mode on or off. In debug mode, objects
+// created with CreateObjectWithFinalizer record the stack that created them,
+// and those collected without being destroyed are logged with the standard
+// logger along with that stack. It is off by default, as recording the stack
+// slows down creation.
+// Example: oop.SetLeakDebug(true)
+func SetLeakDebug(enabled bool) {
+	leakDebug.Store(enabled)
+}
+
+// finalizer releases the resources of an object that was collected without
+// being destroyed. It must not refer to the wrapper of the object, or the
+// wrapper would never be collected.
+type finalizer struct {
+	klass     *Klass
+	classType reflect.Type
+	budget    *memoryBudget // Budget of the factory that created the object.
+	reserved  int64         // Bytes reserved for the object in budget.
+	stack     []byte        // Stack that created the object, nil outside debug mode.
+}
+
+// CreateObjectWithFinalizer creates a new object like CreateObjectE that is
+// deinitialized, see Klass.Deinit, when the garbage collector finds it
+// unreachable before Destroy was called, so leaked objects still run their
+// destroy hooks and deinitializers and give their memory back to the budget.
+// Unlike Destroy, the finalizer runs on a goroutine of the runtime, does not
+// stop started objects, detach relations or fire transition hooks. Objects the
+// factory or the package refers to, as soft-deleted, interned or indexed
+// objects, are not collected, and the wrapper must be kept while its
+// underlying object is in use. See SetLeakDebug to find leaked objects.
+// Example: conn, err := factory.CreateObjectWithFinalizer(&Conn{Addr: "localhost:5432"})
+func (f *ObjectFactory) CreateObjectWithFinalizer(initializer interface{}) (*ObjectWrapper, error) {
+	var stack []byte
+	if leakDebug.Load() {
+		stack = debug.Stack()
+	}
+
+	obj, err := f.CreateObjectE(initializer)
+	if err != nil {
+		return nil, err
+	}
+
+	fin := &finalizer{
+		klass:     obj.klass,
+		classType: classElem(reflect.TypeOf(obj.class())),
+		budget:    obj.budget,
+		reserved:  obj.reserved,
+		stack:     stack,
+	}
+	obj.cleanup = runtime.AddCleanup(obj, (*finalizer).release, fin)
+	return obj, nil
+}
+
+// release deinitializes the leaked object and gives its memory back.
+func (fin *finalizer) release() {
+	if leakDebug.Load() {
+		if fin.stack != nil {
+			log.Printf("oop: %s was collected without being destroyed, created at:\n%s", fin.classType, fin.stack)
+		} else {
+			log.Printf("oop: %s was collected without being destroyed", fin.classType)
+		}
+	}
+
+	fin.klass.Deinit()
+	fin.budget.release(fin.reserved)
+}
@@ -0,0 +1,117 @@
+package oop
+
+import (
+	"bytes"
+	"log"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestLeakyConn is a test class whose objects are leaked on purpose
+type TestLeakyConn struct {
+	Addr string
+}
+
+// collectUntil runs the garbage collector until done returns true
+func collectUntil(t *testing.T, done func() bool) {
+	t.Helper()
+	for range 100 {
+		runtime.GC()
+		if done() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("the object was not finalized")
+}
+
+// TestCreateObjectWithFinalizer tests that leaked objects are deinitialized
+func TestCreateObjectWithFinalizer(t *testing.T) {
+	info, err := registeredClass(reflect.TypeOf(TestLeakyConn{}))
+	if err != nil {
+		t.Fatalf("registeredClass returned error: %v", err)
+	}
+	var destroyed atomic.Int32
+	info.AddHook(HookDestroy, func(obj any) { destroyed.Add(1) })
+
+	factory := NewObjectFactory()
+	func() {
+		obj, err := factory.CreateObjectWithFinalizer(&TestLeakyConn{Addr: "leaked"})
+		if err != nil {
+			t.Fatalf("CreateObjectWithFinalizer returned error: %v", err)
+		}
+		if obj.GetUnderlyingObject().(*TestLeakyConn).Addr != "leaked" {
+			t.Errorf("CreateObjectWithFinalizer returned %v", obj.GetUnderlyingObject())
+		}
+	}()
+
+	collectUntil(t, func() bool {
+		used, _ := factory.MemoryUsage()
+		return destroyed.Load() == 1 && used == 0
+	})
+	if objs := factory.live.list(); len(objs) != 0 {
+		t.Errorf("the factory still holds %d live objects", len(objs))
+	}
+
+	// Test that destroyed objects are not deinitialized again
+	func() {
+		obj, _ := factory.CreateObjectWithFinalizer(&TestLeakyConn{Addr: "destroyed"})
+		obj.Destroy()
+	}()
+	for range 3 {
+		runtime.GC()
+	}
+	if n := destroyed.Load(); n != 2 {
+		t.Errorf("the destroy hook ran %d times, want 2", n)
+	}
+	if used, _ := factory.MemoryUsage(); used != 0 {
+		t.Errorf("MemoryUsage = %d, want 0", used)
+	}
+
+	// Test with invalid arguments
+	if _, err := factory.CreateObjectWithFinalizer(nil); err == nil {
+		t.Error("CreateObjectWithFinalizer with a nil initializer should return an error")
+	}
+}
+
+// TestSetLeakDebug tests that leaked objects are logged with their creation stack
+func TestSetLeakDebug(t *testing.T) {
+	var buf syncBuffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&buf)
+	SetLeakDebug(true)
+	defer SetLeakDebug(false)
+
+	factory := NewObjectFactory()
+	func() {
+		factory.CreateObjectWithFinalizer(&TestLeakyConn{Addr: "logged"})
+	}()
+
+	collectUntil(t, func() bool { return strings.Contains(buf.String(), "TestLeakyConn") })
+	if out := buf.String(); !strings.Contains(out, "TestSetLeakDebug") {
+		t.Errorf("the log does not show the creation stack:\n%s", out)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
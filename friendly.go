@@ -2,28 +2,139 @@ package oop
 
 import (
 	"fmt"
+	"math"
 	"reflect"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"weak"
 )
 
 // ObjectFactory provides a user-friendly way to create and manage objects.
 // It abstracts away the complexity of the underlying OOP implementation.
 type ObjectFactory struct {
-	allocator interface{}
+	allocator Allocator
+	budget    memoryBudget // Memory used by the factory's objects, see ReserveMemory.
+	interned  internTable  // Live objects by natural key, see Intern.
+	trash     objectSet    // Soft-deleted objects, see Sweep.
+	live      objectSet    // Objects that are not destroyed, held weakly, see Backup.
+}
+
+// objectSet holds objects of a factory in the order they were added.
+// A weak set does not keep its objects alive: collected objects leave it.
+type objectSet struct {
+	mu      sync.Mutex
+	weak    bool
+	objects map[weak.Pointer[ObjectWrapper]]setEntry
+	next    uint64
+}
+
+// setEntry is an object of an objectSet.
+type setEntry struct {
+	order uint64         // When the object was added.
+	obj   *ObjectWrapper // The object, nil in weak sets.
+}
+
+// add records an object.
+func (s *objectSet) add(obj *ObjectWrapper) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.objects == nil {
+		s.objects = map[weak.Pointer[ObjectWrapper]]setEntry{}
+	}
+	key := weak.Make(obj)
+	if _, ok := s.objects[key]; ok {
+		return
+	}
+
+	s.next++
+	entry := setEntry{order: s.next, obj: obj}
+	if s.weak {
+		entry.obj = nil
+		runtime.AddCleanup(obj, s.forget, key)
+	}
+	s.objects[key] = entry
+}
+
+// remove forgets an object.
+func (s *objectSet) remove(obj *ObjectWrapper) {
+	if s == nil {
+		return
+	}
+	s.forget(weak.Make(obj))
+}
+
+// forget removes the entry of an object, which may have been collected.
+func (s *objectSet) forget(key weak.Pointer[ObjectWrapper]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+}
+
+// list returns the objects in the order they were added.
+func (s *objectSet) list() []*ObjectWrapper {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type ordered struct {
+		obj   *ObjectWrapper
+		order uint64
+	}
+	entries := make([]ordered, 0, len(s.objects))
+	for key, entry := range s.objects {
+		if obj := key.Value(); obj != nil {
+			entries = append(entries, ordered{obj: obj, order: entry.order})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].order < entries[j].order })
+
+	objs := make([]*ObjectWrapper, len(entries))
+	for i, entry := range entries {
+		objs[i] = entry.obj
+	}
+	return objs
 }
 
 // NewObjectFactory creates a new ObjectFactory.
 func NewObjectFactory() *ObjectFactory {
 	return &ObjectFactory{
 		allocator: nil, // Using nil allocator for simplicity
+		live:      objectSet{weak: true},
+	}
+}
+
+// NewObjectFactoryWithAllocator creates a new ObjectFactory that allocates objects
+// through the given allocator.
+// Example: factory := oop.NewObjectFactoryWithAllocator(oop.NewPoolAllocator())
+func NewObjectFactoryWithAllocator(allocator Allocator) *ObjectFactory {
+	return &ObjectFactory{
+		allocator: allocator,
+		live:      objectSet{weak: true},
 	}
 }
 
 // CreateObject creates a new object of the specified type with the given initializer.
 // It simplifies the object creation process by hiding the reflection details.
+// It returns nil if the object would exceed the memory budget, see ReserveMemory,
+// or violate a unique index, see CreateIndex; CreateObjectE tells why.
 // Example: factory.CreateObject(&Dog{Name: "Buddy"})
 func (f *ObjectFactory) CreateObject(initializer interface{}) *ObjectWrapper {
+	obj, _ := f.CreateObjectE(initializer)
+	return obj
+}
+
+// CreateObjectE creates a new object like CreateObject, and returns an error if
+// the initializer is nil, the object would exceed the memory budget, the
+// allocator fails, or it violates a unique index, as a *UniqueViolationError.
+// Example: userObj, err := factory.CreateObjectE(&User{Email: "ann@example.com"})
+func (f *ObjectFactory) CreateObjectE(initializer interface{}) (*ObjectWrapper, error) {
 	if initializer == nil {
-		return nil
+		return nil, fmt.Errorf("initializer cannot be nil")
 	}
 
 	// Get the type of the initializer
@@ -32,24 +143,76 @@ func (f *ObjectFactory) CreateObject(initializer interface{}) *ObjectWrapper {
 		objType = objType.Elem()
 	}
 
-	// Create a new object using the underlying OOP implementation
-	klass := New(f.allocator, objType, initializer)
+	// Report the use of deprecated classes
+	warnDeprecated(objType, "")
+
+	// Create a new object using the underlying OOP implementation,
+	// and wrap it for easier use
+	return f.wrap(objType, func() (*Klass, error) {
+		return newKlass(f.allocator, makeClassInfo(objType), objType, initializer)
+	})
+}
+
+// NewObject creates a new zero-valued object of the specified class type.
+// The object is allocated through the factory's allocator and freed when it is destroyed.
+// It returns nil if the object would exceed the memory budget, see ReserveMemory,
+// or the allocator fails.
+// Example: factory.NewObject(reflect.TypeOf(Dog{}))
+func (f *ObjectFactory) NewObject(classType reflect.Type) *ObjectWrapper {
+	obj, _ := f.newObject(classType)
+	return obj
+}
 
-	// Wrap the object for easier use
-	return &ObjectWrapper{
-		klass: klass,
+// newObject implements NewObject, returning why the object cannot be created.
+func (f *ObjectFactory) newObject(classType reflect.Type) (*ObjectWrapper, error) {
+	if classType == nil {
+		return nil, fmt.Errorf("classType cannot be nil")
 	}
+	classType = classElem(classType)
+
+	// Report the use of deprecated classes
+	warnDeprecated(classType, "")
+
+	return f.wrap(classType, func() (*Klass, error) {
+		return newKlass(f.allocator, makeClassInfo(classType), classType, nil)
+	})
 }
 
 // ObjectWrapper provides a user-friendly wrapper around a Klass object.
 // It simplifies common operations like casting and type checking.
 type ObjectWrapper struct {
-	klass *Klass
+	mu      sync.Mutex   // Guards mutations of the object, see ApplyAtomic.
+	klassMu sync.RWMutex // Guards klass, so the object can be read while another goroutine destroys it.
+	klass   *Klass
+
+	lifecycleMu        sync.Mutex                        // Serializes lifecycle transitions.
+	pendingTransitions []transitionEvent                 // State changes to report once lifecycleMu is released; guarded by lifecycleMu.
+	state              atomic.Int32                      // Lifecycle state, see State.
+	hooksMu            sync.Mutex                        // Guards transitionHooks, propertyHooks, slots and events.
+	transitionHooks    []func(from, to State)            // Hooks registered with OnTransition.
+	propertyHooks      []func(name string, old, new any) // Hooks registered with OnPropertyChanged.
+	slots              map[string][]*Connection          // Slots connected with Connect, keyed by signal name.
+	events             *EventBus                         // Bus returned by Events, nil until first used.
+
+	parent   *ObjectWrapper   // Parent set with SetParent, nil if none; guarded by treeMu.
+	children []*ObjectWrapper // Children in the order they were added; guarded by treeMu.
+
+	budget   *memoryBudget // Budget of the factory that created the object, nil if none.
+	reserved int64         // Bytes reserved for the object in budget.
+
+	deletedAt atomic.Int64 // Unix time in nanoseconds of SoftDestroy, 0 if not soft-deleted.
+	trash     *objectSet   // Soft-deleted objects of the factory that created the object, nil if none.
+	live      *objectSet   // Live objects of the factory that created the object, nil if none.
+
+	refs atomic.Int64 // References taken with Retain beyond the creator's, -1 once the last is released.
+
+	cleanup runtime.Cleanup // Deinitializes the object if it is collected, see CreateObjectWithFinalizer.
 }
 
 // As casts the object to the specified interface type.
 // It simplifies the casting process by hiding the reflection details.
-// Example: dogObj.As((*IAnimal)(nil))
+// The interface type is given as a pointer to the interface or as a reflect.Type.
+// Example: dogObj.As((*IAnimal)(nil)) or dogObj.As(oop.Iface[IAnimal]())
 // Returns the casted object or an error if the cast is not possible.
 func (o *ObjectWrapper) As(interfacePtr interface{}) (interface{}, error) {
 	// Check if the input is a valid interface pointer
@@ -65,10 +228,19 @@ func (o *ObjectWrapper) As(interfacePtr interface{}) (interface{}, error) {
 	// 	return interfacePtr, nil
 	// }
 
-	if o.klass == nil || o.klass.Class == nil {
+	class := o.class()
+	if class == nil {
 		return nil, fmt.Errorf("object is not initialized")
 	}
 
+	// Accept interface types given as reflect.Type, e.g. from Iface
+	if t, ok := interfacePtr.(reflect.Type); ok {
+		if t.Kind() != reflect.Interface {
+			return nil, fmt.Errorf("%s is not an interface type", t)
+		}
+		return Cast(class, t), nil
+	}
+
 	// Get the interface type
 	interfaceType := reflect.TypeOf(interfacePtr)
 	interfaceTypeKind := interfaceType.Kind()
@@ -86,21 +258,293 @@ func (o *ObjectWrapper) As(interfacePtr interface{}) (interface{}, error) {
 	interfaceType = interfaceType.Elem()
 
 	// Cast the object to the interface type
-	return Cast(o.klass.Class, interfaceType), nil
+	return Cast(class, interfaceType), nil
 }
 
 // Destroy deinitializes and destroys the object.
+// An object retained by other owners, see Retain, is not destroyed: Destroy
+// then drops a reference like Release, and the last owner destroys it.
+// A started object is stopped first; the object is destroyed even if stopping fails.
+// Related objects are detached, or destroyed as well for relations defined
+// with WithCascade(CascadeDestroy), see DefineRelation. The children of the
+// object are destroyed after it, see SetParent.
 func (o *ObjectWrapper) Destroy() {
+	if o.dropRetained() {
+		return
+	}
+
+	o.lifecycleMu.Lock()
+	cascade, children := o.destroy()
+	o.unlockLifecycle()
+
+	destroyRelated(cascade)
+	destroyChildren(children)
+}
+
+// destroy implements Destroy and returns the related objects and the children
+// to destroy once the lifecycle lock is released. The caller must hold the
+// lifecycle lock and release it with unlockLifecycle.
+func (o *ObjectWrapper) destroy() ([]reflect.Value, []*ObjectWrapper) {
+	if o.State() == StateStarted {
+		o.stop()
+	}
+
+	var cascade []reflect.Value
+	if class := o.class(); class != nil {
+		cascade = detachRelations(reflect.ValueOf(class))
+	}
+
+	o.mu.Lock()
+	if class := o.class(); class != nil {
+		o.unindex(class)
+	}
+	o.klassMu.Lock()
 	if o.klass != nil {
 		o.klass.Deinit()
 		o.klass = nil
+		if o.budget != nil {
+			o.budget.release(o.reserved)
+		}
 	}
+	o.klassMu.Unlock()
+	o.mu.Unlock()
+	o.trash.remove(o)
+	o.live.remove(o)
+	o.cleanup.Stop()
+
+	if from := o.State(); from != StateDestroyed {
+		o.state.Store(int32(StateDestroyed))
+		o.pendingTransitions = append(o.pendingTransitions, transitionEvent{from: from, to: StateDestroyed})
+	}
+	o.disconnectAll()
+	return cascade, o.detachTree()
 }
 
 // GetUnderlyingObject returns the underlying object.
 func (o *ObjectWrapper) GetUnderlyingObject() interface{} {
+	return o.class()
+}
+
+// class returns the underlying object, or nil if the object was destroyed.
+func (o *ObjectWrapper) class() interface{} {
+	o.klassMu.RLock()
+	defer o.klassMu.RUnlock()
+
 	if o.klass == nil {
 		return nil
 	}
 	return o.klass.Class
 }
+
+// SetFields sets several exported fields of the object in a single pass.
+// All values are validated before any field is written, so either every field
+// is updated or, on error, none is. A value that violates a unique index, see
+// CreateIndex, is reported as a *UniqueViolationError.
+// Example: dogObj.SetFields(map[string]interface{}{"Name": "Rex"})
+func (o *ObjectWrapper) SetFields(values map[string]interface{}) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.setFields(values)
+}
+
+// setFields implements SetFields. The caller must hold the object's lock.
+func (o *ObjectWrapper) setFields(values map[string]interface{}) error {
+	target, assignments, err := o.fieldAssignments(values)
+	if err != nil {
+		return err
+	}
+
+	pending := map[int]reflect.Value{}
+	for _, a := range assignments {
+		if len(a.index) == 1 {
+			pending[a.index[0]] = a.value
+		}
+	}
+	return o.indexedWrite(target, pending, func() error {
+		for _, a := range assignments {
+			setField(target, a.index, a.value)
+		}
+		return nil
+	})
+}
+
+// assignment is a validated value for a field of an object.
+type assignment struct {
+	index []int // Index sequence of the field.
+	value reflect.Value
+}
+
+// fieldAssignments validates the values of SetFields and returns the struct
+// value of the object with the assignments. The caller must hold the object's lock.
+func (o *ObjectWrapper) fieldAssignments(values map[string]interface{}) (reflect.Value, []assignment, error) {
+	if err := o.checkMutable(); err != nil {
+		return reflect.Value{}, nil, err
+	}
+
+	target, err := o.structValue()
+	if err != nil {
+		return reflect.Value{}, nil, err
+	}
+
+	assignments := make([]assignment, 0, len(values))
+	for name, value := range values {
+		sf, ok := target.Type().FieldByName(name)
+		if !ok {
+			return reflect.Value{}, nil, fmt.Errorf("field %s not found on %s", name, target.Type())
+		}
+		field, err := target.FieldByIndexErr(sf.Index)
+		if err != nil {
+			return reflect.Value{}, nil, fmt.Errorf("field %s on %s cannot be set: %w", name, target.Type(), err)
+		}
+		if !field.CanSet() {
+			return reflect.Value{}, nil, fmt.Errorf("field %s on %s cannot be set", name, target.Type())
+		}
+
+		converted, err := fieldValue(field.Type(), value)
+		if err != nil {
+			return reflect.Value{}, nil, fmt.Errorf("field %s: %w", name, err)
+		}
+		assignments = append(assignments, assignment{index: sf.Index, value: converted})
+	}
+
+	return target, assignments, nil
+}
+
+// SetFieldsFrom copies the exported fields of a source struct onto the object.
+// Every exported field of the source must exist on the object with a compatible type.
+// Example: dogObj.SetFieldsFrom(DogUpdate{Name: "Rex"})
+func (o *ObjectWrapper) SetFieldsFrom(src interface{}) error {
+	srcValue := reflect.ValueOf(src)
+	if srcValue.Kind() == reflect.Ptr {
+		if srcValue.IsNil() {
+			return fmt.Errorf("src cannot be nil")
+		}
+		srcValue = srcValue.Elem()
+	}
+	if srcValue.Kind() != reflect.Struct {
+		return fmt.Errorf("src must be a struct or a pointer to a struct")
+	}
+
+	values := map[string]interface{}{}
+	srcType := srcValue.Type()
+	for i := range srcType.NumField() {
+		if srcType.Field(i).IsExported() {
+			values[srcType.Field(i).Name] = srcValue.Field(i).Interface()
+		}
+	}
+
+	return o.SetFields(values)
+}
+
+// structValue returns the settable struct value of the underlying object.
+func (o *ObjectWrapper) structValue() (reflect.Value, error) {
+	class := o.class()
+	if class == nil {
+		return reflect.Value{}, fmt.Errorf("object is not initialized")
+	}
+
+	v := reflect.ValueOf(class)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("object must be a pointer to a struct, got %T", class)
+	}
+
+	return v.Elem(), nil
+}
+
+// fieldValue converts a value so that it can be assigned to a field of the given type.
+// Nil becomes the zero value, and numeric values are converted between numeric
+// types if the field type can hold them exactly, see convertNumeric.
+func fieldValue(fieldType reflect.Type, value interface{}) (reflect.Value, error) {
+	if value == nil {
+		switch fieldType.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+			return reflect.Zero(fieldType), nil
+		default:
+			return reflect.Value{}, fmt.Errorf("cannot assign nil to %s", fieldType)
+		}
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Type().AssignableTo(fieldType) {
+		return v, nil
+	}
+	if isNumericKind(v.Kind()) && isNumericKind(fieldType.Kind()) {
+		return convertNumeric(v, fieldType)
+	}
+
+	return reflect.Value{}, fmt.Errorf("cannot assign %s to %s", v.Type(), fieldType)
+}
+
+// convertNumeric converts a numeric value to another numeric type. It returns
+// an error if the value overflows the type, is a float with a fractional part,
+// or not finite, converted to an integer type, or loses precision, i.e. does
+// not convert back to the same value.
+func convertNumeric(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+	zero := reflect.Zero(t)
+	overflows := false
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := v.Int()
+		switch {
+		case zero.CanInt():
+			overflows = zero.OverflowInt(n)
+		case zero.CanUint():
+			overflows = n < 0 || zero.OverflowUint(uint64(n))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n := v.Uint()
+		switch {
+		case zero.CanInt():
+			overflows = n > math.MaxInt64 || zero.OverflowInt(int64(n))
+		case zero.CanUint():
+			overflows = zero.OverflowUint(n)
+		}
+	default:
+		f := v.Float()
+		if zero.CanFloat() {
+			overflows = zero.OverflowFloat(f)
+			break
+		}
+		if f != math.Trunc(f) || math.IsInf(f, 0) {
+			return reflect.Value{}, fmt.Errorf("%v is not an integer", f)
+		}
+		if zero.CanInt() {
+			overflows = f < math.MinInt64 || f >= math.MaxInt64 || zero.OverflowInt(int64(f))
+		} else {
+			overflows = f < 0 || f >= math.MaxUint64 || zero.OverflowUint(uint64(f))
+		}
+	}
+	if overflows {
+		return reflect.Value{}, fmt.Errorf("%v overflows %s", v.Interface(), t)
+	}
+
+	// Values in range can still lose precision, e.g. large integers as floats
+	// or 0.1 as a float32, so the value must survive the way back.
+	converted := v.Convert(t)
+	back := converted.Convert(v.Type())
+	exact := false
+	switch {
+	case v.CanInt():
+		exact = back.Int() == v.Int()
+	case v.CanUint():
+		exact = back.Uint() == v.Uint()
+	default:
+		exact = back.Float() == v.Float() || math.IsNaN(back.Float()) && math.IsNaN(v.Float())
+	}
+	if !exact {
+		return reflect.Value{}, fmt.Errorf("%v cannot be represented exactly as %s", v.Interface(), t)
+	}
+	return converted, nil
+}
+
+// isNumericKind reports whether k is an integer or floating point kind.
+func isNumericKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
@@ -1,6 +1,9 @@
 package oop
 
 import (
+	"math"
+	"reflect"
+	"sync"
 	"testing"
 )
 
@@ -42,6 +45,75 @@ func TestNewObjectFactory(t *testing.T) {
 	}
 }
 
+// TestNewObject tests the NewObject method of ObjectFactory
+func TestNewObject(t *testing.T) {
+	allocator := NewPoolAllocator()
+	factory := NewObjectFactoryWithAllocator(allocator)
+
+	dogObj := factory.NewObject(reflect.TypeOf(&TestDog{}))
+	if dogObj == nil {
+		t.Fatal("NewObject returned nil")
+	}
+	if _, ok := dogObj.GetUnderlyingObject().(*TestDog); !ok {
+		t.Errorf("NewObject created %T, want *TestDog", dogObj.GetUnderlyingObject())
+	}
+
+	dogObj.Destroy()
+	if stats := allocator.Stats(); stats.Allocs != 1 || stats.Frees != 1 {
+		t.Errorf("Stats are %+v, want 1 alloc and 1 free", stats)
+	}
+
+	if factory.NewObject(nil) != nil {
+		t.Error("NewObject should return nil for a nil type")
+	}
+}
+
+// TestConcurrentObjects tests creating, casting and destroying objects from many goroutines
+func TestConcurrentObjects(t *testing.T) {
+	factory := NewObjectFactory()
+	animalType := reflect.TypeOf((*TestAnimal)(nil)).Elem()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				dogObj := factory.CreateObject(&TestDog{Name: "Buddy"})
+				if _, err := dogObj.As((*TestAnimal)(nil)); err != nil {
+					t.Errorf("As returned error: %v", err)
+				}
+				if Cast(dogObj.GetUnderlyingObject(), animalType) == nil {
+					t.Error("Cast returned nil")
+				}
+				dogObj.Destroy()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Test reading and destroying the same object concurrently
+	dogObj := factory.CreateObject(&TestDog{Name: "Buddy"})
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			dogObj.As((*TestAnimal)(nil))
+			dogObj.GetUnderlyingObject()
+			dogObj.SetFields(map[string]interface{}{"Name": "Max"})
+		}()
+		go func() {
+			defer wg.Done()
+			dogObj.Destroy()
+		}()
+	}
+	wg.Wait()
+
+	if dogObj.GetUnderlyingObject() != nil {
+		t.Error("Object was not destroyed")
+	}
+}
+
 // TestCreateObject tests the CreateObject method of ObjectFactory
 func TestCreateObject(t *testing.T) {
 	factory := NewObjectFactory()
@@ -250,3 +322,133 @@ func TestIntegration(t *testing.T) {
 		t.Error("Destroy did not set cat klass to nil")
 	}
 }
+
+// TestDogUpdate is a test struct used as a source for SetFieldsFrom
+type TestDogUpdate struct {
+	Name string
+}
+
+// TestObjectWrapperSetFields tests the SetFields method of ObjectWrapper
+func TestObjectWrapperSetFields(t *testing.T) {
+	factory := NewObjectFactory()
+
+	dog := &TestDog{Name: "Buddy"}
+	obj := factory.CreateObject(dog)
+
+	if err := obj.SetFields(map[string]interface{}{"Name": "Rex"}); err != nil {
+		t.Fatalf("SetFields returned error: %v", err)
+	}
+	if dog.Name != "Rex" {
+		t.Errorf("Name is %q, want %q", dog.Name, "Rex")
+	}
+
+	// Test with unknown field, nothing should be written
+	err := obj.SetFields(map[string]interface{}{"Name": "Max", "Age": 3})
+	if err == nil {
+		t.Error("SetFields should return error for unknown field")
+	}
+	if dog.Name != "Rex" {
+		t.Errorf("SetFields partially applied changes, Name is %q", dog.Name)
+	}
+
+	// Test with incompatible type
+	if err := obj.SetFields(map[string]interface{}{"Name": 42}); err == nil {
+		t.Error("SetFields should return error for incompatible type")
+	}
+
+	// Test with nil klass
+	obj = &ObjectWrapper{klass: nil}
+	if err := obj.SetFields(map[string]interface{}{"Name": "Rex"}); err == nil {
+		t.Error("SetFields should return error for nil klass")
+	}
+}
+
+// TestGaugeMeta is embedded by pointer in TestGauge
+type TestGaugeMeta struct {
+	Label string
+}
+
+// TestGauge is a test struct with numeric fields of several sizes
+type TestGauge struct {
+	Small int8
+	Count int
+	Size  uint16
+	Ratio float32
+	Total float64
+	*TestGaugeMeta
+}
+
+// TestObjectWrapperSetFieldsNumeric tests that numeric conversions are exact
+func TestObjectWrapperSetFieldsNumeric(t *testing.T) {
+	gauge := &TestGauge{}
+	obj := NewObjectFactory().CreateObject(gauge)
+
+	if err := obj.SetFields(map[string]interface{}{"Small": 100, "Count": 3.0, "Size": int64(65535), "Ratio": 0.5}); err != nil {
+		t.Fatalf("SetFields returned error: %v", err)
+	}
+	if gauge.Small != 100 || gauge.Count != 3 || gauge.Size != 65535 || gauge.Ratio != 0.5 {
+		t.Errorf("SetFields stored %+v", gauge)
+	}
+
+	for name, value := range map[string]interface{}{
+		"Small": 300,
+		"Count": 3.7,
+		"Size":  -1,
+		"Ratio": 1e300,
+	} {
+		if err := obj.SetFields(map[string]interface{}{name: value}); err == nil {
+			t.Errorf("SetFields should return error for %v in %s", value, name)
+		}
+	}
+
+	// Test values in range that would lose precision
+	for name, value := range map[string]interface{}{
+		"Ratio": 0.1,
+		"Total": int64(1<<53 + 1),
+	} {
+		if err := obj.SetFields(map[string]interface{}{name: value}); err == nil {
+			t.Errorf("SetFields should return error for %v in %s", value, name)
+		}
+	}
+	for _, value := range []interface{}{math.NaN(), math.Inf(1), 1e20, uint64(math.MaxUint64)} {
+		if err := obj.SetFields(map[string]interface{}{"Count": value}); err == nil {
+			t.Errorf("SetFields should return error for %v in an int", value)
+		}
+	}
+	if gauge.Small != 100 || gauge.Count != 3 || gauge.Size != 65535 || gauge.Ratio != 0.5 {
+		t.Errorf("SetFields stored %+v after failed conversions", gauge)
+	}
+
+	if err := obj.SetFields(map[string]interface{}{"Total": int64(1 << 53)}); err != nil || gauge.Total != 1<<53 {
+		t.Errorf("SetFields returned %v, Total is %v", err, gauge.Total)
+	}
+
+	// Test promoted fields behind a nil embedded pointer
+	if err := obj.SetFields(map[string]interface{}{"Label": "x"}); err == nil {
+		t.Error("SetFields should return error for a field behind a nil pointer")
+	}
+	gauge.TestGaugeMeta = &TestGaugeMeta{}
+	if err := obj.SetFields(map[string]interface{}{"Label": "x"}); err != nil || gauge.Label != "x" {
+		t.Errorf("SetFields returned %v, Label is %q", err, gauge.Label)
+	}
+}
+
+// TestObjectWrapperSetFieldsFrom tests the SetFieldsFrom method of ObjectWrapper
+func TestObjectWrapperSetFieldsFrom(t *testing.T) {
+	factory := NewObjectFactory()
+
+	dog := &TestDog{Name: "Buddy"}
+	obj := factory.CreateObject(dog)
+
+	if err := obj.SetFieldsFrom(TestDogUpdate{Name: "Rex"}); err != nil {
+		t.Fatalf("SetFieldsFrom returned error: %v", err)
+	}
+	if dog.Name != "Rex" {
+		t.Errorf("Name is %q, want %q", dog.Name, "Rex")
+	}
+
+	// Test with non-struct source
+	if err := obj.SetFieldsFrom(42); err == nil {
+		t.Error("SetFieldsFrom should return error for non-struct source")
+	}
+}
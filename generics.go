@@ -0,0 +1,138 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Iface returns the reflect.Type of the interface type T.
+// It replaces the reflect.TypeOf((*IAnimal)(nil)).Elem() sentinel pattern and
+// can be passed wherever an interface type is expected, e.g. to Cast or ObjectWrapper.As.
+// reflect.Type values are canonical, so every call returns the same value.
+// It panics if T is not an interface type.
+// Example: animal := oop.Cast(dog, oop.Iface[IAnimal]())
+func Iface[T any]() reflect.Type {
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("%s is not an interface type", t))
+	}
+	return t
+}
+
+// ObjectWrapperT is a type-safe ObjectWrapper for objects of type *T.
+type ObjectWrapperT[T any] struct {
+	obj *ObjectWrapper
+}
+
+// CreateObjectT creates a new object of type T with the given initializer.
+// A nil initializer creates a zero-valued object.
+// Example: dogObj := oop.CreateObjectT(&Dog{Name: "Buddy"})
+func CreateObjectT[T any](init *T) *ObjectWrapperT[T] {
+	factory := NewObjectFactory()
+	if init == nil {
+		return &ObjectWrapperT[T]{obj: factory.NewObject(reflect.TypeFor[T]())}
+	}
+	return &ObjectWrapperT[T]{obj: factory.CreateObject(init)}
+}
+
+// Get returns the underlying object, or nil if the object was destroyed.
+func (o *ObjectWrapperT[T]) Get() *T {
+	t, _ := o.obj.GetUnderlyingObject().(*T)
+	return t
+}
+
+// Destroy deinitializes and destroys the object.
+func (o *ObjectWrapperT[T]) Destroy() {
+	o.obj.Destroy()
+}
+
+// Wrapper returns the untyped ObjectWrapper of the object.
+func (o *ObjectWrapperT[T]) Wrapper() *ObjectWrapper {
+	return o.obj
+}
+
+// AsT casts a typed object to the interface type I.
+// It is the type-safe counterpart of ObjectWrapper.As; Go methods cannot have
+// type parameters, so it is a function rather than a method.
+// Example: animal, err := oop.AsT[IAnimal](dogObj)
+func AsT[I any, T any](o *ObjectWrapperT[T]) (I, error) {
+	var zero I
+	obj := o.Get()
+	if obj == nil {
+		return zero, fmt.Errorf("object is not initialized")
+	}
+
+	i, ok := CastTo[I](obj)
+	if !ok {
+		return zero, fmt.Errorf("cannot cast %T to %s", obj, reflect.TypeFor[I]())
+	}
+	return i, nil
+}
+
+// CastTo casts an object to the type T.
+// It is the type-safe counterpart of Cast: the target type is given as a type
+// parameter and the result needs no further type assertion. Like Cast, a
+// successful cast fires the HookCast hooks of the object's class.
+// Example: animal, ok := oop.CastTo[IAnimal](dog)
+func CastTo[T any](obj any) (T, bool) {
+	var zero T
+	if obj == nil {
+		return zero, false
+	}
+
+	if t, ok := obj.(T); ok {
+		// Skips the cast plan, but fires the hooks like Cast.
+		objType := reflect.TypeOf(obj)
+		castTargetsFor(objType).fireCast(objType, t)
+		return t, true
+	}
+
+	result := Cast(obj, reflect.TypeOf((*T)(nil)).Elem())
+	if result == nil {
+		return zero, false
+	}
+
+	t, ok := result.(T)
+	return t, ok
+}
+
+// IsInstanceOfT reports whether obj is an instance of the class or interface
+// type T, see IsInstanceOf.
+// Example: oop.IsInstanceOfT[IAnimal](dog) or oop.IsInstanceOfT[Mammal](beagle)
+func IsInstanceOfT[T any](obj any) bool {
+	return IsInstanceOf(obj, reflect.TypeFor[T]())
+}
+
+// ImplementsT reports whether instances of a class type, or pointers to them,
+// implement the interface type I, see Implements.
+// Example: oop.ImplementsT[IAnimal](reflect.TypeOf(Dog{}))
+func ImplementsT[I any](classType reflect.Type) bool {
+	return Implements(classType, reflect.TypeFor[I]())
+}
+
+// MustCast casts an object to the type T and panics if the cast is not possible.
+// Example: animal := oop.MustCast[IAnimal](dog)
+func MustCast[T any](obj any) T {
+	t, ok := CastTo[T](obj)
+	if !ok {
+		panic(fmt.Sprintf("cannot cast %T to %s", obj, reflect.TypeOf((*T)(nil)).Elem()))
+	}
+	return t
+}
+
+// ResolveT resolves a reference, see Ref.Resolve, and casts the object to the
+// type T. It returns an error if the object cannot be cast.
+// Example: customer, err := oop.ResolveT[*Customer](order.Customer)
+func ResolveT[T any](r Ref) (T, error) {
+	var zero T
+	obj, err := r.Resolve()
+	if err != nil {
+		return zero, err
+	}
+
+	t, ok := CastTo[T](obj)
+	if !ok {
+		return zero, fmt.Errorf("cannot cast %T to %s", obj, reflect.TypeFor[T]())
+	}
+	return t, nil
+}
@@ -0,0 +1,182 @@
+package oop
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestCastTo tests the CastTo function
+func TestCastTo(t *testing.T) {
+	// Test with pointer implementing the interface
+	ti, ok := CastTo[TestInterface](&TestStruct{Value: 42})
+	if !ok {
+		t.Fatal("CastTo returned false")
+	}
+	if ti.GetValue() != 42 {
+		t.Errorf("GetValue returned %d, want 42", ti.GetValue())
+	}
+
+	// Test with value whose pointer implements the interface
+	ti, ok = CastTo[TestInterface](TestStruct{Value: 84})
+	if !ok {
+		t.Fatal("CastTo returned false for value type")
+	}
+	if ti.GetValue() != 84 {
+		t.Errorf("GetValue returned %d, want 84", ti.GetValue())
+	}
+
+	// Test with non-implemented interface
+	if _, ok := CastTo[TestAnimal](&TestStruct{Value: 42}); ok {
+		t.Error("CastTo should return false for non-implemented interface")
+	}
+
+	// Test with nil
+	if _, ok := CastTo[TestInterface](nil); ok {
+		t.Error("CastTo should return false for nil")
+	}
+}
+
+// TestMustCast tests the MustCast function
+func TestMustCast(t *testing.T) {
+	animal := MustCast[TestAnimal](&TestDog{Name: "Buddy"})
+	if animal.Sound() != "Buddy: Woof!" {
+		t.Errorf("Sound returned %q, want %q", animal.Sound(), "Buddy: Woof!")
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("MustCast should panic for impossible cast")
+		}
+	}()
+	MustCast[TestAnimal](&TestStruct{Value: 42})
+}
+
+// TestIsInstanceOfT tests the IsInstanceOfT function
+func TestIsInstanceOfT(t *testing.T) {
+	if !IsInstanceOfT[TestAnimal](&TestDog{}) {
+		t.Error("TestDog should be an instance of TestAnimal")
+	}
+	if !IsInstanceOfT[TestInterface](TestStruct{}) {
+		t.Error("TestStruct value should be an instance of TestInterface")
+	}
+	if IsInstanceOfT[TestAnimal](&TestStruct{}) {
+		t.Error("TestStruct should not be an instance of TestAnimal")
+	}
+	if !IsInstanceOfT[TestDog](&TestDog{}) || IsInstanceOfT[TestDog](&TestStruct{}) {
+		t.Error("IsInstanceOfT should check class types")
+	}
+	if IsInstanceOfT[TestAnimal](nil) {
+		t.Error("nil should not be an instance of TestAnimal")
+	}
+}
+
+// TestImplementsT tests the ImplementsT and Implements functions
+func TestImplementsT(t *testing.T) {
+	if !ImplementsT[TestAnimal](reflect.TypeOf(TestDog{})) {
+		t.Error("TestDog should implement TestAnimal through its pointer")
+	}
+	if !ImplementsT[TestAnimal](reflect.TypeOf(&TestDog{})) {
+		t.Error("*TestDog should implement TestAnimal")
+	}
+	if ImplementsT[TestAnimal](reflect.TypeOf(TestStruct{})) {
+		t.Error("TestStruct should not implement TestAnimal")
+	}
+	if ImplementsT[TestAnimal](nil) {
+		t.Error("a nil type should not implement TestAnimal")
+	}
+	if Implements(reflect.TypeOf(TestDog{}), reflect.TypeOf(TestStruct{})) {
+		t.Error("Implements with a non-interface type should return false")
+	}
+}
+
+// TestIface tests the Iface function
+func TestIface(t *testing.T) {
+	animalType := Iface[TestAnimal]()
+	if animalType != reflect.TypeOf((*TestAnimal)(nil)).Elem() {
+		t.Errorf("Iface returned %v, want TestAnimal", animalType)
+	}
+	if Iface[TestAnimal]() != animalType {
+		t.Error("Iface should return the same type on every call")
+	}
+
+	// Test with Cast and ObjectWrapper.As
+	if Cast(&TestDog{Name: "Buddy"}, animalType) == nil {
+		t.Error("Cast failed with an Iface type")
+	}
+	dogObj := NewObjectFactory().CreateObject(&TestDog{Name: "Buddy"})
+	defer dogObj.Destroy()
+	animal, err := dogObj.As(animalType)
+	if err != nil {
+		t.Fatalf("As returned error: %v", err)
+	}
+	if _, ok := animal.(TestAnimal); !ok {
+		t.Errorf("As returned %T, want TestAnimal", animal)
+	}
+	if _, err := dogObj.As(reflect.TypeOf(TestDog{})); err == nil {
+		t.Error("As should return error for a non-interface reflect.Type")
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Iface should panic for non-interface types")
+		}
+	}()
+	Iface[TestDog]()
+}
+
+// TestObjectWrapperT tests the CreateObjectT function and the ObjectWrapperT type
+func TestObjectWrapperT(t *testing.T) {
+	dogObj := CreateObjectT(&TestDog{Name: "Buddy"})
+	if dogObj.Get().Name != "Buddy" {
+		t.Errorf("Get returned %v, want Buddy", dogObj.Get())
+	}
+	if dogObj.Wrapper().GetUnderlyingObject() != dogObj.Get() {
+		t.Error("Wrapper does not wrap the same object")
+	}
+
+	animal, err := AsT[TestAnimal](dogObj)
+	if err != nil {
+		t.Fatalf("AsT returned error: %v", err)
+	}
+	if animal.Sound() != "Buddy: Woof!" {
+		t.Errorf("Sound returned %q, want %q", animal.Sound(), "Buddy: Woof!")
+	}
+	if _, err := AsT[TestInterface](dogObj); err == nil {
+		t.Error("AsT should return error for impossible cast")
+	}
+
+	dogObj.Destroy()
+	if dogObj.Get() != nil {
+		t.Error("Get should return nil after Destroy")
+	}
+	if _, err := AsT[TestAnimal](dogObj); err == nil {
+		t.Error("AsT should return error for a destroyed object")
+	}
+
+	// Test with a nil initializer
+	zeroObj := CreateObjectT[TestDog](nil)
+	defer zeroObj.Destroy()
+	if zeroObj.Get() == nil || zeroObj.Get().Name != "" {
+		t.Errorf("CreateObjectT(nil) created %v, want a zero TestDog", zeroObj.Get())
+	}
+}
+
+// TestResolveT tests the ResolveT function
+func TestResolveT(t *testing.T) {
+	dog := &TestDog{Name: "Rex"}
+	if err := RegisterResolver("kennel", func(objectID string) (any, error) { return dog, nil }); err != nil {
+		t.Fatalf("RegisterResolver returned error: %v", err)
+	}
+	defer UnregisterResolver("kennel")
+
+	ref := Ref{FactoryID: "kennel", ObjectID: "rex"}
+	if got, err := ResolveT[*TestDog](ref); err != nil || got != dog {
+		t.Errorf("ResolveT returned %v, %v", got, err)
+	}
+	if _, err := ResolveT[*TestCat](ref); err == nil {
+		t.Error("ResolveT to another class should return an error")
+	}
+	if _, err := ResolveT[*TestDog](Ref{}); err == nil {
+		t.Error("ResolveT of a zero Ref should return an error")
+	}
+}
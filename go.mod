@@ -1,3 +1,3 @@
 module github.com/dracory/oop
 
-go 1.23.6
+go 1.24
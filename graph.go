@@ -0,0 +1,202 @@
+package oop
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// GraphFormat is the output format of Container.Graph.
+type GraphFormat int
+
+const (
+	GraphDOT     GraphFormat = iota // Graphviz DOT.
+	GraphMermaid                    // Mermaid flowchart.
+)
+
+// graphNode is a provider or an unresolved dependency in a container graph.
+type graphNode struct {
+	id       string   // Identifier of the node in the output.
+	label    string   // Type the node stands for.
+	lifetime Lifetime // Lifetime of the provider.
+	built    bool     // Whether the instance of a singleton was built.
+	missing  bool     // Whether the node is a dependency without a usable provider.
+}
+
+// graphEdge is a dependency between two nodes in a container graph.
+type graphEdge struct {
+	from, to string // Identifiers of the dependent and the dependency.
+	label    string // Parameter type, if it differs from the type of the provider.
+}
+
+// Graph writes the provider and dependency graph of the container in DOT or
+// Mermaid format. Every provider is a node labeled with its type and lifetime,
+// scoped providers are grouped, singletons that were already built are marked,
+// and dependencies without a usable provider are drawn as missing nodes.
+// Edges point from a provider to its dependencies and are labeled with the
+// parameter type when it is resolved through an interface or a subclass.
+// Example: c.Graph(os.Stdout, oop.GraphMermaid)
+func (c *Container) Graph(w io.Writer, format GraphFormat) error {
+	if format != GraphDOT && format != GraphMermaid {
+		return fmt.Errorf("unknown graph format %d", format)
+	}
+
+	nodes, edges := c.graph()
+
+	var b strings.Builder
+	if format == GraphDOT {
+		writeDOT(&b, nodes, edges)
+	} else {
+		writeMermaid(&b, nodes, edges)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// graph collects the nodes and edges of the container graph, in a stable order.
+func (c *Container) graph() ([]graphNode, []graphEdge) {
+	root := c.root
+	root.mu.Lock()
+	providers := make([]*provider, 0, len(root.providers))
+	for _, p := range root.providers {
+		providers = append(providers, p)
+	}
+	root.mu.Unlock()
+
+	slices.SortFunc(providers, func(a, b *provider) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	var nodes []graphNode
+	ids := map[*provider]string{}
+	for i, p := range providers {
+		p.mu.Lock()
+		built := p.built
+		p.mu.Unlock()
+
+		ids[p] = fmt.Sprintf("n%d", i)
+		nodes = append(nodes, graphNode{id: ids[p], label: p.String(), lifetime: p.lifetime, built: built})
+	}
+
+	var edges []graphEdge
+	missing := map[reflect.Type]string{}
+	for _, p := range providers {
+		for _, dep := range p.deps {
+			target, err := root.provider(dep, "")
+			if err != nil {
+				id, ok := missing[dep]
+				if !ok {
+					id = fmt.Sprintf("m%d", len(missing))
+					missing[dep] = id
+					nodes = append(nodes, graphNode{id: id, label: dep.String(), missing: true})
+				}
+				edges = append(edges, graphEdge{from: ids[p], to: id})
+				continue
+			}
+
+			edge := graphEdge{from: ids[p], to: ids[target]}
+			if target.out != dep {
+				edge.label = dep.String()
+			}
+			edges = append(edges, edge)
+		}
+	}
+
+	return nodes, edges
+}
+
+// description returns the second line of a node label.
+func (n graphNode) description() string {
+	switch {
+	case n.missing:
+		return "missing"
+	case n.built:
+		return n.lifetime.String() + ", built"
+	default:
+		return n.lifetime.String()
+	}
+}
+
+// writeDOT writes a container graph in Graphviz DOT format.
+func writeDOT(b *strings.Builder, nodes []graphNode, edges []graphEdge) {
+	quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	writeNode := func(indent string, n graphNode) {
+		fmt.Fprintf(b, "%s%s [label=\"%s\\n%s\"", indent, n.id, quote.Replace(n.label), n.description())
+		switch {
+		case n.missing:
+			b.WriteString(", style=dashed, color=red")
+		case n.lifetime == LifetimeSingleton:
+			b.WriteString(", style=bold")
+		}
+		b.WriteString("];\n")
+	}
+
+	b.WriteString("digraph container {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	var scoped []graphNode
+	for _, n := range nodes {
+		if !n.missing && n.lifetime == LifetimeScoped {
+			scoped = append(scoped, n)
+			continue
+		}
+		writeNode("\t", n)
+	}
+	if len(scoped) > 0 {
+		b.WriteString("\tsubgraph cluster_scoped {\n\t\tlabel=\"scoped\";\n")
+		for _, n := range scoped {
+			writeNode("\t\t", n)
+		}
+		b.WriteString("\t}\n")
+	}
+	for _, e := range edges {
+		fmt.Fprintf(b, "\t%s -> %s", e.from, e.to)
+		if e.label != "" {
+			fmt.Fprintf(b, " [label=\"%s\"]", quote.Replace(e.label))
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+}
+
+// writeMermaid writes a container graph as a Mermaid flowchart.
+func writeMermaid(b *strings.Builder, nodes []graphNode, edges []graphEdge) {
+	quote := strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;", "|", "#124;")
+	writeNode := func(indent string, n graphNode) {
+		fmt.Fprintf(b, "%s%s[\"%s<br/>%s\"]", indent, n.id, quote.Replace(n.label), n.description())
+		switch {
+		case n.missing:
+			b.WriteString(":::missing")
+		case n.lifetime == LifetimeSingleton:
+			b.WriteString(":::singleton")
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("flowchart LR\n")
+	b.WriteString("\tclassDef singleton stroke-width:3px\n")
+	b.WriteString("\tclassDef missing stroke:red,stroke-dasharray:5 5\n")
+	var scoped []graphNode
+	for _, n := range nodes {
+		if !n.missing && n.lifetime == LifetimeScoped {
+			scoped = append(scoped, n)
+			continue
+		}
+		writeNode("\t", n)
+	}
+	if len(scoped) > 0 {
+		b.WriteString("\tsubgraph scoped\n")
+		for _, n := range scoped {
+			writeNode("\t\t", n)
+		}
+		b.WriteString("\tend\n")
+	}
+	for _, e := range edges {
+		if e.label != "" {
+			fmt.Fprintf(b, "\t%s -->|\"%s\"| %s\n", e.from, quote.Replace(e.label), e.to)
+		} else {
+			fmt.Fprintf(b, "\t%s --> %s\n", e.from, e.to)
+		}
+	}
+}
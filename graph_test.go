@@ -0,0 +1,103 @@
+package oop
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// newTestGraphContainer returns a container with providers of every lifetime
+func newTestGraphContainer(t *testing.T) *Container {
+	t.Helper()
+
+	c := NewContainer()
+	for _, err := range []error{
+		c.Singleton(func() *TestDB { return &TestDB{} }),
+		c.Provide(func(db *TestDB) *TestRepo { return &TestRepo{DB: db} }),
+		c.Provide(func(store TestStore) *TestUserService { return &TestUserService{Store: store} }),
+		c.Scoped(func(db *TestDB, animal TestAnimal) *TestRequest { return &TestRequest{DB: db} }),
+	} {
+		if err != nil {
+			t.Fatalf("registering a provider returned error: %v", err)
+		}
+	}
+	if _, err := Resolve[*TestDB](c); err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	return c
+}
+
+// TestContainerGraphDOT tests the Graph method in DOT format
+func TestContainerGraphDOT(t *testing.T) {
+	var b strings.Builder
+	if err := newTestGraphContainer(t).Graph(&b, GraphDOT); err != nil {
+		t.Fatalf("Graph returned error: %v", err)
+	}
+
+	want := `digraph container {
+	rankdir=LR;
+	node [shape=box];
+	n0 [label="*oop.TestDB\nsingleton, built", style=bold];
+	n1 [label="*oop.TestRepo\ntransient"];
+	n3 [label="*oop.TestUserService\ntransient"];
+	m0 [label="oop.TestAnimal\nmissing", style=dashed, color=red];
+	subgraph cluster_scoped {
+		label="scoped";
+		n2 [label="*oop.TestRequest\nscoped"];
+	}
+	n1 -> n0;
+	n2 -> n0;
+	n2 -> m0;
+	n3 -> n1 [label="oop.TestStore"];
+}
+`
+	if b.String() != want {
+		t.Errorf("Graph wrote\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+// TestContainerGraphMermaid tests the Graph method in Mermaid format
+func TestContainerGraphMermaid(t *testing.T) {
+	var b strings.Builder
+	if err := newTestGraphContainer(t).Graph(&b, GraphMermaid); err != nil {
+		t.Fatalf("Graph returned error: %v", err)
+	}
+
+	want := `flowchart LR
+	classDef singleton stroke-width:3px
+	classDef missing stroke:red,stroke-dasharray:5 5
+	n0["*oop.TestDB<br/>singleton, built"]:::singleton
+	n1["*oop.TestRepo<br/>transient"]
+	n3["*oop.TestUserService<br/>transient"]
+	m0["oop.TestAnimal<br/>missing"]:::missing
+	subgraph scoped
+		n2["*oop.TestRequest<br/>scoped"]
+	end
+	n1 --> n0
+	n2 --> n0
+	n2 --> m0
+	n3 -->|"oop.TestStore"| n1
+`
+	if b.String() != want {
+		t.Errorf("Graph wrote\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+// failingWriter is an io.Writer that always fails
+type failingWriter struct{}
+
+// Write implements io.Writer
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+// TestContainerGraphErrors tests the errors returned by the Graph method
+func TestContainerGraphErrors(t *testing.T) {
+	c := NewContainer()
+	if err := c.Graph(&strings.Builder{}, GraphFormat(42)); err == nil {
+		t.Error("Graph should return error for unknown formats")
+	}
+	if err := c.Graph(failingWriter{}, GraphDOT); err == nil {
+		t.Error("Graph should return the error of the writer")
+	}
+}
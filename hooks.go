@@ -0,0 +1,54 @@
+package oop
+
+// HookEvent identifies a point in the lifecycle of a class instance.
+type HookEvent int
+
+const (
+	HookCreate  HookEvent = iota // Fired by New, and so by CreateObject, after the instance is initialized.
+	HookDestroy                  // Fired by Deinit before the instance is deinitialized.
+	HookCast                     // Fired by Cast with the cast object after a successful cast.
+)
+
+// String returns the name of the event.
+func (e HookEvent) String() string {
+	switch e {
+	case HookCreate:
+		return "create"
+	case HookDestroy:
+		return "destroy"
+	case HookCast:
+		return "cast"
+	default:
+		return "unknown"
+	}
+}
+
+// AddHook registers a function that is called with the instance when the event
+// occurs for an instance of the class. Hooks run in registration order.
+// Register the class first, so that all its instances share the same ClassInfo.
+// Example: info.AddHook(oop.HookCreate, func(obj any) { created.Add(1) })
+func (c *ClassInfo) AddHook(event HookEvent, fn func(obj any)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.hooks == nil {
+		c.hooks = map[HookEvent][]func(obj any){}
+	}
+	c.hooks[event] = append(c.hooks[event], fn)
+}
+
+// fireHook calls the hooks registered for the event with the instance.
+// The hooks are called without holding the lock, so they may register more hooks.
+func (c *ClassInfo) fireHook(event HookEvent, obj any) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	hooks := c.hooks[event]
+	c.mu.Unlock()
+
+	for _, fn := range hooks {
+		fn(obj)
+	}
+}
@@ -0,0 +1,54 @@
+package oop
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestHookedStruct is a test class with lifecycle hooks
+type TestHookedStruct struct {
+	Value int
+}
+
+// GetValue implements TestInterface
+func (h *TestHookedStruct) GetValue() int {
+	return h.Value
+}
+
+// TestHooks tests the AddHook method and the lifecycle events
+func TestHooks(t *testing.T) {
+	hookedType := reflect.TypeOf(TestHookedStruct{})
+	info, err := Register(hookedType)
+	if err != nil {
+		t.Fatalf("Register returned error: %v", err)
+	}
+
+	var events []string
+	for _, event := range []HookEvent{HookCreate, HookDestroy, HookCast} {
+		info.AddHook(event, func(obj any) {
+			if _, ok := obj.(*TestHookedStruct); !ok {
+				t.Errorf("%s hook got %T, want *TestHookedStruct", event, obj)
+			}
+			events = append(events, event.String())
+		})
+	}
+
+	klass := New(nil, hookedType, nil)
+	Cast(klass.Class, reflect.TypeOf((*TestInterface)(nil)).Elem())
+	Cast(klass.Class, reflect.TypeOf((*TestAnimal)(nil)).Elem()) // Fails, no hook
+	CastTo[TestInterface](klass.Class)                           // Direct type assertion, same hook
+	CastTo[TestAnimal](klass.Class)                              // Fails, no hook
+	klass.Deinit()
+
+	obj := NewObjectFactory().CreateObject(&TestHookedStruct{Value: 1})
+	obj.Destroy()
+
+	want := []string{"create", "cast", "cast", "destroy", "create", "destroy"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("Events are %v, want %v", events, want)
+	}
+
+	if HookEvent(42).String() != "unknown" {
+		t.Errorf("HookEvent(42).String() = %s, want unknown", HookEvent(42))
+	}
+}
@@ -0,0 +1,132 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// IdempotencyStore stores method results keyed by idempotency key.
+// Implementations must be safe for concurrent use.
+type IdempotencyStore interface {
+	Get(key string) ([]interface{}, bool)  // Returns the stored results for the key.
+	Put(key string, results []interface{}) // Stores the results for the key.
+}
+
+// MemoryIdempotencyStore is an in-memory IdempotencyStore.
+type MemoryIdempotencyStore struct {
+	mu      sync.RWMutex
+	results map[string][]interface{}
+}
+
+// NewMemoryIdempotencyStore creates a new, empty MemoryIdempotencyStore.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		results: map[string][]interface{}{},
+	}
+}
+
+// Get returns the stored results for the key.
+func (s *MemoryIdempotencyStore) Get(key string) ([]interface{}, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	results, ok := s.results[key]
+	return results, ok
+}
+
+// Put stores the results for the key.
+func (s *MemoryIdempotencyStore) Put(key string, results []interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[key] = results
+}
+
+var (
+	idempotencyMu    sync.Mutex
+	idempotencyStore IdempotencyStore = NewMemoryIdempotencyStore()
+	idempotencyKeys                   = map[string]*idempotencyCall{}
+)
+
+// idempotencyCall tracks an in-flight call for a key, so concurrent retries wait for it.
+type idempotencyCall struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// SetIdempotencyStore replaces the store used by CallIdempotent.
+// Passing nil restores a fresh in-memory store.
+func SetIdempotencyStore(store IdempotencyStore) {
+	if store == nil {
+		store = NewMemoryIdempotencyStore()
+	}
+
+	idempotencyMu.Lock()
+	defer idempotencyMu.Unlock()
+	idempotencyStore = store
+}
+
+// CallIdempotent calls the named method of obj at most once per idempotency key.
+// The results of the first successful call are stored, and later calls with the
+// same key return them without invoking the method again. Calls whose last
+// result is a non-nil error are not stored, so they can be retried. Concurrent
+// calls with the same key are serialized.
+// Example: oop.CallIdempotent(requestID, account, "Withdraw", 10)
+func CallIdempotent(key string, obj interface{}, method string, args ...interface{}) ([]interface{}, error) {
+	if key == "" {
+		return nil, fmt.Errorf("key cannot be empty")
+	}
+
+	m, err := methodByName(obj, method)
+	if err != nil {
+		return nil, err
+	}
+
+	idempotencyMu.Lock()
+	store := idempotencyStore
+	call, ok := idempotencyKeys[key]
+	if !ok {
+		call = &idempotencyCall{}
+		idempotencyKeys[key] = call
+	}
+	call.refs++
+	idempotencyMu.Unlock()
+
+	defer func() {
+		idempotencyMu.Lock()
+		call.refs--
+		if call.refs == 0 {
+			delete(idempotencyKeys, key)
+		}
+		idempotencyMu.Unlock()
+	}()
+
+	call.mu.Lock()
+	defer call.mu.Unlock()
+
+	if results, ok := store.Get(key); ok {
+		return results, nil
+	}
+
+	results, err := invokeMethod(obj, method, m, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isErrorResult(m.Type(), results) {
+		store.Put(key, results)
+	}
+
+	return results, nil
+}
+
+// isErrorResult reports whether the last result of a method is a non-nil error.
+func isErrorResult(mt reflect.Type, results []interface{}) bool {
+	if mt.NumOut() == 0 {
+		return false
+	}
+	if mt.Out(mt.NumOut()-1) != errorType {
+		return false
+	}
+	return results[len(results)-1] != nil
+}
@@ -0,0 +1,91 @@
+package oop
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestAccount is a test struct with a non-idempotent method
+type TestAccount struct {
+	Balance int
+	Fail    bool
+}
+
+// Withdraw decreases the balance
+func (a *TestAccount) Withdraw(amount int) (int, error) {
+	if a.Fail {
+		return a.Balance, errors.New("withdraw failed")
+	}
+	a.Balance -= amount
+	return a.Balance, nil
+}
+
+// TestCallIdempotent tests the CallIdempotent function
+func TestCallIdempotent(t *testing.T) {
+	SetIdempotencyStore(nil)
+	defer SetIdempotencyStore(nil)
+
+	account := &TestAccount{Balance: 100}
+
+	results, err := CallIdempotent("req-1", account, "Withdraw", 10)
+	if err != nil {
+		t.Fatalf("CallIdempotent returned error: %v", err)
+	}
+	if results[0] != 90 {
+		t.Errorf("CallIdempotent returned %v, want 90", results[0])
+	}
+
+	// Test that a retry returns the stored result
+	results, err = CallIdempotent("req-1", account, "Withdraw", 10)
+	if err != nil {
+		t.Fatalf("CallIdempotent returned error: %v", err)
+	}
+	if results[0] != 90 || account.Balance != 90 {
+		t.Errorf("CallIdempotent executed the method twice, balance is %d", account.Balance)
+	}
+
+	// Test that a new key executes the method
+	if _, err := CallIdempotent("req-2", account, "Withdraw", 10); err != nil {
+		t.Fatalf("CallIdempotent returned error: %v", err)
+	}
+	if account.Balance != 80 {
+		t.Errorf("Balance is %d, want 80", account.Balance)
+	}
+
+	// Test that failed calls are not stored
+	account.Fail = true
+	results, _ = CallIdempotent("req-3", account, "Withdraw", 10)
+	if results[1] == nil {
+		t.Fatal("Withdraw should have failed")
+	}
+	account.Fail = false
+	if _, err := CallIdempotent("req-3", account, "Withdraw", 10); err != nil {
+		t.Fatalf("CallIdempotent returned error: %v", err)
+	}
+	if account.Balance != 70 {
+		t.Errorf("CallIdempotent did not retry failed call, balance is %d", account.Balance)
+	}
+
+	// Test with empty key
+	if _, err := CallIdempotent("", account, "Withdraw", 10); err == nil {
+		t.Error("CallIdempotent should return error for empty key")
+	}
+}
+
+// TestSetIdempotencyStore tests that a custom store is used
+func TestSetIdempotencyStore(t *testing.T) {
+	store := NewMemoryIdempotencyStore()
+	store.Put("req-1", []interface{}{42, nil})
+
+	SetIdempotencyStore(store)
+	defer SetIdempotencyStore(nil)
+
+	account := &TestAccount{Balance: 100}
+	results, err := CallIdempotent("req-1", account, "Withdraw", 10)
+	if err != nil {
+		t.Fatalf("CallIdempotent returned error: %v", err)
+	}
+	if results[0] != 42 || account.Balance != 100 {
+		t.Errorf("CallIdempotent did not use the custom store, got %v", results[0])
+	}
+}
@@ -0,0 +1,72 @@
+package oop
+
+import (
+	"reflect"
+)
+
+// MethodChange describes a method whose signature differs between two interface versions.
+type MethodChange struct {
+	Name string       // Name of the method.
+	Old  reflect.Type // Signature in the old interface.
+	New  reflect.Type // Signature in the new interface.
+}
+
+// InterfaceDiff describes the differences between two versions of an interface.
+// Methods are sorted by name, as reflect returns interface methods in that order.
+type InterfaceDiff struct {
+	Added   []reflect.Method // Methods only in the new interface.
+	Removed []reflect.Method // Methods only in the old interface.
+	Changed []MethodChange   // Methods whose signature changed.
+}
+
+// IsEmpty reports whether the two interfaces have the same method set.
+func (d InterfaceDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// BreaksCallers reports whether code calling the old interface may no longer compile,
+// i.e. whether methods were removed or changed.
+func (d InterfaceDiff) BreaksCallers() bool {
+	return len(d.Removed) > 0 || len(d.Changed) > 0
+}
+
+// BreaksImplementations reports whether types implementing the old interface may no
+// longer implement the new one, i.e. whether methods were added or changed.
+func (d InterfaceDiff) BreaksImplementations() bool {
+	return len(d.Added) > 0 || len(d.Changed) > 0
+}
+
+// DiffInterfaces compares the method sets of two interface types.
+// It panics if either type is not an interface type.
+// Example: diff := oop.DiffInterfaces(reflect.TypeOf((*AnimalV1)(nil)).Elem(), reflect.TypeOf((*AnimalV2)(nil)).Elem())
+func DiffInterfaces(oldIface, newIface reflect.Type) InterfaceDiff {
+	if oldIface == nil || oldIface.Kind() != reflect.Interface || newIface == nil || newIface.Kind() != reflect.Interface {
+		panic("not an interface type")
+	}
+
+	diff := InterfaceDiff{}
+
+	for i := range oldIface.NumMethod() {
+		oldMethod := oldIface.Method(i)
+		newMethod, ok := newIface.MethodByName(oldMethod.Name)
+		switch {
+		case !ok:
+			diff.Removed = append(diff.Removed, oldMethod)
+		case oldMethod.Type != newMethod.Type:
+			diff.Changed = append(diff.Changed, MethodChange{
+				Name: oldMethod.Name,
+				Old:  oldMethod.Type,
+				New:  newMethod.Type,
+			})
+		}
+	}
+
+	for i := range newIface.NumMethod() {
+		newMethod := newIface.Method(i)
+		if _, ok := oldIface.MethodByName(newMethod.Name); !ok {
+			diff.Added = append(diff.Added, newMethod)
+		}
+	}
+
+	return diff
+}
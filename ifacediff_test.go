@@ -0,0 +1,67 @@
+package oop
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestAnimalV1 is the first version of a test interface
+type TestAnimalV1 interface {
+	Sound() string
+	Name() string
+	Age() int
+}
+
+// TestAnimalV2 is the second version of a test interface
+type TestAnimalV2 interface {
+	Sound() string
+	Age() int64
+	Legs() int
+}
+
+// TestDiffInterfaces tests the DiffInterfaces function
+func TestDiffInterfaces(t *testing.T) {
+	v1 := reflect.TypeOf((*TestAnimalV1)(nil)).Elem()
+	v2 := reflect.TypeOf((*TestAnimalV2)(nil)).Elem()
+
+	diff := DiffInterfaces(v1, v2)
+
+	if len(diff.Added) != 1 || diff.Added[0].Name != "Legs" {
+		t.Errorf("Added is %v, want [Legs]", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Name != "Name" {
+		t.Errorf("Removed is %v, want [Name]", diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Name != "Age" {
+		t.Fatalf("Changed is %v, want [Age]", diff.Changed)
+	}
+	if diff.Changed[0].Old.Out(0).Kind() != reflect.Int || diff.Changed[0].New.Out(0).Kind() != reflect.Int64 {
+		t.Errorf("Changed signatures are %v and %v", diff.Changed[0].Old, diff.Changed[0].New)
+	}
+	if diff.IsEmpty() || !diff.BreaksCallers() || !diff.BreaksImplementations() {
+		t.Error("diff should be breaking")
+	}
+
+	// Test with identical interfaces
+	diff = DiffInterfaces(v1, v1)
+	if !diff.IsEmpty() {
+		t.Errorf("DiffInterfaces returned %+v for identical interfaces", diff)
+	}
+
+	// Test that adding a method does not break callers
+	diff = DiffInterfaces(reflect.TypeOf((*TestAnimal)(nil)).Elem(), reflect.TypeOf((*interface {
+		Sound() string
+		Legs() int
+	})(nil)).Elem())
+	if diff.BreaksCallers() || !diff.BreaksImplementations() {
+		t.Error("adding a method should only break implementations")
+	}
+
+	// Test with non-interface type
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("DiffInterfaces should panic for non-interface type")
+		}
+	}()
+	DiffInterfaces(v1, reflect.TypeOf(TestDog{}))
+}
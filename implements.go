@@ -0,0 +1,67 @@
+package oop
+
+import (
+	"reflect"
+	"sync/atomic"
+)
+
+// implementsKey identifies a (concrete type, interface type) pair.
+type implementsKey struct {
+	typ   reflect.Type
+	iface reflect.Type
+}
+
+// implementsCache caches whether types implement interfaces. The map is shared
+// by every caller and never modified once published: new results are added to
+// a copy that replaces the snapshot atomically, so lookups take no locks.
+// The set of pairs a program checks is bounded by its types, so the cache stops
+// growing after warm-up.
+var implementsCache atomic.Pointer[map[implementsKey]bool]
+
+// implements reports whether t implements the interface type iface, like
+// t.Implements(iface), caching the result.
+func implements(t, iface reflect.Type) bool {
+	key := implementsKey{typ: t, iface: iface}
+	snapshot := implementsCache.Load()
+	if snapshot != nil {
+		if ok, found := (*snapshot)[key]; found {
+			return ok
+		}
+	}
+
+	ok := t.Implements(iface)
+	for {
+		var current map[implementsKey]bool
+		if snapshot != nil {
+			current = *snapshot
+		}
+		if _, found := current[key]; found {
+			return ok // Added by another goroutine.
+		}
+
+		next := make(map[implementsKey]bool, len(current)+1)
+		for k, v := range current {
+			next[k] = v
+		}
+		next[key] = ok
+
+		if implementsCache.CompareAndSwap(snapshot, &next) {
+			return ok
+		}
+		snapshot = implementsCache.Load()
+	}
+}
+
+// Implements reports whether instances of a class type, or pointers to them,
+// implement the interface type iface. It returns false if either type is nil
+// or iface is not an interface type.
+// Example: oop.Implements(reflect.TypeOf(Dog{}), reflect.TypeOf((*io.Reader)(nil)).Elem())
+func Implements(classType, iface reflect.Type) bool {
+	if classType == nil || iface == nil || iface.Kind() != reflect.Interface {
+		return false
+	}
+	if implements(classType, iface) {
+		return true
+	}
+	return classType.Kind() != reflect.Ptr && classType.Kind() != reflect.Interface && implements(reflect.PointerTo(classType), iface)
+}
@@ -0,0 +1,56 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// TestImplementsCache tests the shared implements cache
+func TestImplementsCache(t *testing.T) {
+	animalType := reflect.TypeOf((*TestAnimal)(nil)).Elem()
+	stringerType := reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	dogType := reflect.TypeOf(&TestDog{})
+
+	// Test that cached results match reflect
+	for range 2 {
+		if !implements(dogType, animalType) {
+			t.Error("*TestDog should implement TestAnimal")
+		}
+		if implements(dogType, stringerType) {
+			t.Error("*TestDog should not implement fmt.Stringer")
+		}
+	}
+
+	snapshot := implementsCache.Load()
+	if snapshot == nil {
+		t.Fatal("implements did not publish a snapshot")
+	}
+	if ok, found := (*snapshot)[implementsKey{typ: dogType, iface: stringerType}]; !found || ok {
+		t.Errorf("cached result is %v, %v, want false, true", ok, found)
+	}
+
+	// Test that published snapshots are not modified
+	size := len(*snapshot)
+	implements(reflect.TypeOf(TestDog{}), stringerType)
+	if len(*snapshot) != size {
+		t.Error("implements should not modify a published snapshot")
+	}
+
+	// Test concurrent use
+	types := []reflect.Type{dogType, reflect.TypeOf(TestDog{}), reflect.TypeOf(&TestMammal{}), reflect.TypeOf(0)}
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, typ := range types {
+				if implements(typ, animalType) != typ.Implements(animalType) {
+					t.Errorf("implements(%s) does not match reflect", typ)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
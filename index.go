@@ -0,0 +1,416 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// IndexKind is whether an index created with CreateIndex allows several
+// objects with the same value.
+type IndexKind int
+
+const (
+	NonUnique IndexKind = iota // Several objects can have the same value.
+	Unique                     // At most one object can have each non-zero value.
+)
+
+// String returns the name of the index kind.
+func (k IndexKind) String() string {
+	if k == Unique {
+		return "Unique"
+	}
+	return "NonUnique"
+}
+
+// UniqueViolationError is returned when a mutation or the creation of an
+// object would give it the value of a unique index that another object has.
+type UniqueViolationError struct {
+	Class    reflect.Type   // Class of the index.
+	Field    string         // Indexed field.
+	Value    any            // The duplicate value.
+	Existing *ObjectWrapper // The object that has the value.
+}
+
+// Error returns a description of the violation.
+func (e *UniqueViolationError) Error() string {
+	return fmt.Sprintf("unique index %s on %s already has value %v", e.Field, e.Class, e.Value)
+}
+
+// fieldIndex is an index on a field of a class.
+type fieldIndex struct {
+	field   string
+	index   int // Index of the field in the struct.
+	kind    IndexKind
+	entries map[any][]*ObjectWrapper // Objects by field value, in the order they got it.
+}
+
+// classIndexes holds the indexes of a class and the indexed values of its objects.
+type classIndexes struct {
+	mu      sync.Mutex
+	typeID  uintptr // Orders the locks of several classes, see reindex.
+	indexes []*fieldIndex
+	search  *searchIndex // Full-text index enabled with EnableSearch, nil if none.
+	objects map[*ObjectWrapper]indexEntry
+}
+
+// indexEntry holds the indexed values of an object.
+type indexEntry struct {
+	keys  []any          // Values of the indexed fields, in the order of indexes.
+	terms map[string]int // Occurrences of the search terms, nil without search.
+}
+
+// CreateIndex creates an index on an exported, comparable field of a class,
+// so FindByIndex finds objects by the value of the field. Indexes are
+// maintained when objects are created by a factory, changed through SetFields,
+// SetProp, FieldRef setters, Intern and ApplyAtomic, and destroyed; fields
+// written directly are indexed on the next such change. Objects created before
+// the index are added on their next change. A Unique index rejects creations
+// and changes that would give an object a value another object has with a
+// *UniqueViolationError; zero values are not checked. Soft-deleted objects keep
+// their values. Creating an index that exists again does nothing.
+// Example: err := oop.CreateIndex(reflect.TypeOf(User{}), "Email", oop.Unique)
+func CreateIndex(classType reflect.Type, field string, kind IndexKind) error {
+	if classType == nil {
+		return fmt.Errorf("classType cannot be nil")
+	}
+
+	classType = classElem(classType)
+	if classType.Kind() != reflect.Struct {
+		return fmt.Errorf("class %s must be a struct", classType)
+	}
+	sf, ok := classType.FieldByName(field)
+	if !ok || !sf.IsExported() || len(sf.Index) != 1 {
+		return fmt.Errorf("field %s not found on %s", field, classType)
+	}
+	if !sf.Type.Comparable() || sf.Type.Kind() == reflect.Interface {
+		return fmt.Errorf("field %s on %s is not comparable and cannot be indexed", field, classType)
+	}
+
+	c, err := classIndexesFor(classType)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, idx := range c.indexes {
+		if idx.field != field {
+			continue
+		}
+		if idx.kind != kind {
+			return fmt.Errorf("index on %s.%s already exists as %s", classType, field, idx.kind)
+		}
+		return nil
+	}
+	c.indexes = append(c.indexes, &fieldIndex{field: field, index: sf.Index[0], kind: kind, entries: map[any][]*ObjectWrapper{}})
+
+	// Objects indexed by the other indexes get no entry in the new one until
+	// their next change, marked by an untyped nil.
+	for obj, entry := range c.objects {
+		entry.keys = append(entry.keys, nil)
+		c.objects[obj] = entry
+	}
+	return nil
+}
+
+// classIndexesFor returns the indexes of a class, registering the class and
+// creating them if needed.
+func classIndexesFor(classType reflect.Type) (*classIndexes, error) {
+	info, err := registeredClass(classType)
+	if err != nil {
+		return nil, err
+	}
+
+	info.mu.Lock()
+	defer info.mu.Unlock()
+	if info.indexes == nil {
+		info.indexes = &classIndexes{typeID: typeIDOf(classType), objects: map[*ObjectWrapper]indexEntry{}}
+	}
+	return info.indexes, nil
+}
+
+// FindByIndex returns the live objects of a class whose indexed field has the
+// given value, in the order they got it. The value is converted like SetFields
+// values. Soft-deleted objects are left out.
+// Example: users, err := oop.FindByIndex(reflect.TypeOf(User{}), "Email", "ann@example.com")
+func FindByIndex(classType reflect.Type, field string, value any) ([]*ObjectWrapper, error) {
+	if classType == nil {
+		return nil, fmt.Errorf("classType cannot be nil")
+	}
+
+	classType = classElem(classType)
+	c := indexesOf(classType)
+	if c == nil {
+		return nil, fmt.Errorf("index on %s.%s not found", classType, field)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := slices.IndexFunc(c.indexes, func(idx *fieldIndex) bool { return idx.field == field })
+	if i < 0 {
+		return nil, fmt.Errorf("index on %s.%s not found", classType, field)
+	}
+	idx := c.indexes[i]
+	key, err := fieldValue(classType.Field(idx.index).Type, value)
+	if err != nil {
+		return nil, fmt.Errorf("field %s: %w", field, err)
+	}
+
+	var objs []*ObjectWrapper
+	for _, obj := range idx.entries[key.Interface()] {
+		if !obj.IsSoftDeleted() {
+			objs = append(objs, obj)
+		}
+	}
+	return objs, nil
+}
+
+// indexesOf returns the indexes of a class, or nil if it has none.
+func indexesOf(classType reflect.Type) *classIndexes {
+	info, ok := LookupType(classType)
+	if !ok {
+		return nil
+	}
+
+	info.mu.Lock()
+	defer info.mu.Unlock()
+	return info.indexes
+}
+
+// keysOf returns the indexed values of target, taking the values of the
+// fields in pending, keyed by field index, instead of the current ones.
+func (c *classIndexes) keysOf(target reflect.Value, pending map[int]reflect.Value) []any {
+	keys := make([]any, len(c.indexes))
+	for i, idx := range c.indexes {
+		v, ok := pending[idx.index]
+		if !ok {
+			v = target.Field(idx.index)
+		}
+		keys[i] = v.Interface()
+	}
+	return keys
+}
+
+// conflict returns an error if another object has one of the non-zero values
+// of the unique indexes in keys.
+func (c *classIndexes) conflict(obj *ObjectWrapper, classType reflect.Type, keys []any) error {
+	for i, idx := range c.indexes {
+		if idx.kind != Unique || reflect.ValueOf(keys[i]).IsZero() {
+			continue
+		}
+		for _, existing := range idx.entries[keys[i]] {
+			if existing != obj {
+				return &UniqueViolationError{Class: classType, Field: idx.field, Value: keys[i], Existing: existing}
+			}
+		}
+	}
+	return nil
+}
+
+// entryOf returns the values of target to index.
+func (c *classIndexes) entryOf(target reflect.Value) indexEntry {
+	entry := indexEntry{keys: c.keysOf(target, nil)}
+	if c.search != nil {
+		entry.terms = c.search.termsOf(target)
+	}
+	return entry
+}
+
+// insert indexes the values of an object.
+func (c *classIndexes) insert(obj *ObjectWrapper, entry indexEntry) {
+	for i, idx := range c.indexes {
+		if entry.keys[i] != nil {
+			idx.entries[entry.keys[i]] = append(idx.entries[entry.keys[i]], obj)
+		}
+	}
+	if c.search != nil {
+		c.search.add(obj, entry.terms)
+	}
+	c.objects[obj] = entry
+}
+
+// remove forgets the indexed values of an object.
+func (c *classIndexes) remove(obj *ObjectWrapper) {
+	entry, ok := c.objects[obj]
+	if !ok {
+		return
+	}
+	if c.search != nil {
+		c.search.remove(obj, entry.terms)
+	}
+
+	keys := entry.keys
+	for i, idx := range c.indexes {
+		if keys[i] == nil {
+			continue
+		}
+		objs := slices.DeleteFunc(idx.entries[keys[i]], func(o *ObjectWrapper) bool { return o == obj })
+		if len(objs) == 0 {
+			delete(idx.entries, keys[i])
+		} else {
+			idx.entries[keys[i]] = objs
+		}
+	}
+	delete(c.objects, obj)
+}
+
+// indexedWrite calls write to change fields of the object, whose struct value
+// is target, and updates the indexes of its class. The unique indexes are
+// checked before write with the values in pending, keyed by field index, and
+// after write with the values it left. The values are rolled back, and the
+// indexes left as they were, if write fails or they violate a unique index.
+// A nil write only indexes the current values. The caller must hold the
+// object's lock, or own the object exclusively.
+func (o *ObjectWrapper) indexedWrite(target reflect.Value, pending map[int]reflect.Value, write func() error) error {
+	c := indexesOf(target.Type())
+	if c == nil {
+		if write != nil {
+			return rollbackWrite(target, write)
+		}
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.conflict(o, target.Type(), c.keysOf(target, pending)); err != nil {
+		return err
+	}
+	if write != nil {
+		err := rollbackWrite(target, func() error {
+			if err := write(); err != nil {
+				return err
+			}
+			return c.conflict(o, target.Type(), c.keysOf(target, nil))
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	c.remove(o)
+	c.insert(o, c.entryOf(target))
+	return nil
+}
+
+// rollbackWrite calls write to change target, and restores its values if write fails.
+func rollbackWrite(target reflect.Value, write func() error) error {
+	snapshot := reflect.New(target.Type()).Elem()
+	snapshot.Set(target)
+	if err := write(); err != nil {
+		target.Set(snapshot)
+		return err
+	}
+	return nil
+}
+
+// pendingField returns the pending value of a field for indexedWrite.
+func pendingField(index []int, value reflect.Value) map[int]reflect.Value {
+	if len(index) != 1 {
+		return nil // Promoted fields are not indexed.
+	}
+	return map[int]reflect.Value{index[0]: value}
+}
+
+// indexNew adds a new object to the indexes of its class. On a violation of a
+// unique index, the object is deinitialized and its memory is given back to
+// the budget.
+func (o *ObjectWrapper) indexNew() error {
+	target, err := o.structValue()
+	if err != nil {
+		return nil // Only structs are indexed.
+	}
+	if err := o.indexedWrite(target, nil, nil); err != nil {
+		o.klass.Deinit()
+		o.klass = nil
+		if o.budget != nil {
+			o.budget.release(o.reserved)
+		}
+		return err
+	}
+	return nil
+}
+
+// unindex removes a destroyed object from the indexes of its class.
+func (o *ObjectWrapper) unindex(class any) {
+	t := reflect.TypeOf(class)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return
+	}
+	c := indexesOf(t.Elem())
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(o)
+	if c.search != nil {
+		delete(c.search.order, o)
+	}
+}
+
+// reindex indexes the current values of objects changed by ApplyAtomic as a
+// single unit: the indexes of all their classes are locked together, and on a
+// violation they are restored to the values the objects had before, which the
+// caller rolls back. The caller must hold the objects' locks.
+func reindex(objs []*ObjectWrapper) error {
+	type change struct {
+		obj    *ObjectWrapper
+		target reflect.Value
+		c      *classIndexes
+		entry  indexEntry // Values indexed before the change.
+		had    bool       // Whether the object was indexed.
+	}
+
+	var changes []change
+	var locked []*classIndexes
+	for _, obj := range objs {
+		target, err := obj.structValue()
+		if err != nil {
+			continue
+		}
+		c := indexesOf(target.Type())
+		if c == nil {
+			continue
+		}
+		changes = append(changes, change{obj: obj, target: target, c: c})
+		if !slices.Contains(locked, c) {
+			locked = append(locked, c)
+		}
+	}
+
+	// Lock in type ID order, so concurrent transactions cannot deadlock
+	sort.Slice(locked, func(i, j int) bool { return locked[i].typeID < locked[j].typeID })
+	for _, c := range locked {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+	}
+
+	for i := range changes {
+		ch := &changes[i]
+		ch.entry, ch.had = ch.c.objects[ch.obj]
+		ch.c.remove(ch.obj)
+	}
+	for i, ch := range changes {
+		entry := ch.c.entryOf(ch.target)
+		if err := ch.c.conflict(ch.obj, ch.target.Type(), entry.keys); err != nil {
+			for _, done := range changes[:i] {
+				done.c.remove(done.obj)
+			}
+			for _, undo := range changes {
+				if undo.had {
+					undo.c.insert(undo.obj, undo.entry)
+				}
+			}
+			return err
+		}
+		ch.c.insert(ch.obj, entry)
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package oop
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestSubscriber is a test class with indexed fields
+type TestSubscriber struct {
+	Email string
+	Plan  string
+	Tags  []string
+}
+
+// TestTitledSubscriber is a test class with an indexed field set through a property
+type TestTitledSubscriber struct {
+	Handle string
+}
+
+// newTestSubscriberIndexes creates the indexes of TestSubscriber
+func newTestSubscriberIndexes(t *testing.T) reflect.Type {
+	t.Helper()
+	subscriberType := reflect.TypeOf(TestSubscriber{})
+	if err := CreateIndex(subscriberType, "Email", Unique); err != nil {
+		t.Fatalf("CreateIndex returned error: %v", err)
+	}
+	if err := CreateIndex(subscriberType, "Plan", NonUnique); err != nil {
+		t.Fatalf("CreateIndex returned error: %v", err)
+	}
+	return subscriberType
+}
+
+// TestCreateIndex tests the CreateIndex function
+func TestCreateIndex(t *testing.T) {
+	subscriberType := newTestSubscriberIndexes(t)
+
+	// Test that creating an index again does nothing
+	if err := CreateIndex(subscriberType, "Email", Unique); err != nil {
+		t.Errorf("CreateIndex of an existing index returned error: %v", err)
+	}
+
+	// Test with invalid arguments
+	if err := CreateIndex(subscriberType, "Email", NonUnique); err == nil {
+		t.Error("CreateIndex of an existing index with another kind should return an error")
+	}
+	if err := CreateIndex(subscriberType, "Tags", NonUnique); err == nil {
+		t.Error("CreateIndex on a slice field should return an error")
+	}
+	if err := CreateIndex(subscriberType, "Missing", NonUnique); err == nil {
+		t.Error("CreateIndex on a missing field should return an error")
+	}
+	if err := CreateIndex(nil, "Email", Unique); err == nil {
+		t.Error("CreateIndex with a nil type should return an error")
+	}
+}
+
+// TestFindByIndex tests that indexes follow creations, mutations and destruction
+func TestFindByIndex(t *testing.T) {
+	subscriberType := newTestSubscriberIndexes(t)
+	factory := NewObjectFactory()
+
+	ann, err := factory.CreateObjectE(&TestSubscriber{Email: "ann@example.com", Plan: "pro"})
+	if err != nil {
+		t.Fatalf("CreateObjectE returned error: %v", err)
+	}
+	bob := factory.CreateObject(&TestSubscriber{Email: "bob@example.com", Plan: "pro"})
+	defer bob.Destroy()
+
+	find := func(field string, value any) []*ObjectWrapper {
+		t.Helper()
+		objs, err := FindByIndex(subscriberType, field, value)
+		if err != nil {
+			t.Fatalf("FindByIndex returned error: %v", err)
+		}
+		return objs
+	}
+
+	if objs := find("Email", "ann@example.com"); len(objs) != 1 || objs[0] != ann {
+		t.Errorf("FindByIndex returned %v, want ann", objs)
+	}
+	if objs := find("Plan", "pro"); len(objs) != 2 || objs[0] != ann || objs[1] != bob {
+		t.Errorf("FindByIndex returned %v, want ann and bob", objs)
+	}
+
+	// Test that mutations move the object in the index
+	if err := ann.SetFields(map[string]interface{}{"Email": "ann@example.org"}); err != nil {
+		t.Fatalf("SetFields returned error: %v", err)
+	}
+	if err := ann.SetProp("Plan", "free"); err != nil {
+		t.Fatalf("SetProp returned error: %v", err)
+	}
+	if objs := find("Email", "ann@example.com"); len(objs) != 0 {
+		t.Errorf("FindByIndex returned %v for the old value", objs)
+	}
+	if objs := find("Plan", "free"); len(objs) != 1 || objs[0] != ann {
+		t.Errorf("FindByIndex returned %v, want ann", objs)
+	}
+
+	// Test that soft-deleted objects are left out and destroyed objects removed
+	ann.SoftDestroy()
+	if objs := find("Plan", "free"); len(objs) != 0 {
+		t.Errorf("FindByIndex returned %v for a soft-deleted object", objs)
+	}
+	ann.Destroy()
+	if obj, err := factory.CreateObjectE(&TestSubscriber{Email: "ann@example.org"}); err != nil {
+		t.Errorf("CreateObjectE with the value of a destroyed object returned error: %v", err)
+	} else {
+		obj.Destroy()
+	}
+
+	// Test with invalid arguments
+	if _, err := FindByIndex(subscriberType, "Tags", nil); err == nil {
+		t.Error("FindByIndex without an index should return an error")
+	}
+	if _, err := FindByIndex(subscriberType, "Email", 42); err == nil {
+		t.Error("FindByIndex with a value of the wrong type should return an error")
+	}
+	if _, err := FindByIndex(reflect.TypeOf(TestPassport{}), "Number", ""); err == nil {
+		t.Error("FindByIndex on a class without indexes should return an error")
+	}
+}
+
+// TestUniqueViolation tests that unique indexes reject duplicate values
+func TestUniqueViolation(t *testing.T) {
+	subscriberType := newTestSubscriberIndexes(t)
+	factory := NewObjectFactory()
+	cat := factory.CreateObject(&TestSubscriber{Email: "cat@example.com"})
+	dan := factory.CreateObject(&TestSubscriber{Email: "dan@example.com", Plan: "pro"})
+	defer cat.Destroy()
+	defer dan.Destroy()
+
+	assertViolation := func(what string, err error) {
+		t.Helper()
+		var violation *UniqueViolationError
+		if !errors.As(err, &violation) {
+			t.Fatalf("%s returned %v, want a *UniqueViolationError", what, err)
+		}
+		if violation.Field != "Email" || violation.Value != "cat@example.com" || violation.Existing != cat || violation.Class != subscriberType {
+			t.Errorf("%s returned %+v", what, violation)
+		}
+	}
+
+	// Test creation
+	_, err := factory.CreateObjectE(&TestSubscriber{Email: "cat@example.com"})
+	assertViolation("CreateObjectE", err)
+	if used, _ := factory.MemoryUsage(); used != 2*int64(subscriberType.Size()) {
+		t.Errorf("MemoryUsage = %d, the rejected object should be released", used)
+	}
+	if cat.Clone() != nil {
+		t.Error("Clone of an object with a unique value should return nil")
+	}
+
+	// Test that rejected mutations leave the object unchanged
+	err = dan.SetFields(map[string]interface{}{"Email": "cat@example.com", "Plan": "free"})
+	assertViolation("SetFields", err)
+	if s := dan.GetUnderlyingObject().(*TestSubscriber); s.Email != "dan@example.com" || s.Plan != "pro" {
+		t.Errorf("SetFields changed the object to %+v", s)
+	}
+	assertViolation("SetProp", dan.SetProp("Email", "cat@example.com"))
+	for ref := range dan.Fields() {
+		if ref.Name == "Email" {
+			assertViolation("SetString", ref.SetString("cat@example.com"))
+		}
+	}
+
+	// Test transactions, which may swap unique values
+	err = ApplyAtomic(func(tx Access) error {
+		return tx.SetFields(dan, map[string]interface{}{"Email": "cat@example.com"})
+	}, dan)
+	assertViolation("ApplyAtomic", err)
+	if s := dan.GetUnderlyingObject().(*TestSubscriber); s.Email != "dan@example.com" {
+		t.Errorf("ApplyAtomic did not roll back, Email = %s", s.Email)
+	}
+	err = ApplyAtomic(func(tx Access) error {
+		tx.SetFields(cat, map[string]interface{}{"Email": "dan@example.com"})
+		return tx.SetFields(dan, map[string]interface{}{"Email": "cat@example.com"})
+	}, cat, dan)
+	if err != nil {
+		t.Fatalf("ApplyAtomic swapping values returned error: %v", err)
+	}
+	if objs, _ := FindByIndex(subscriberType, "Email", "dan@example.com"); len(objs) != 1 || objs[0] != cat {
+		t.Errorf("FindByIndex returned %v after the swap, want cat", objs)
+	}
+
+	// Test that zero values are not checked
+	for range 2 {
+		obj, err := factory.CreateObjectE(&TestSubscriber{})
+		if err != nil {
+			t.Fatalf("CreateObjectE with a zero value returned error: %v", err)
+		}
+		defer obj.Destroy()
+	}
+}
+
+// TestUniqueViolationProperty tests that changes through property setters are undone
+func TestUniqueViolationProperty(t *testing.T) {
+	titledType := reflect.TypeOf(TestTitledSubscriber{})
+	if err := CreateIndex(titledType, "Handle", Unique); err != nil {
+		t.Fatalf("CreateIndex returned error: %v", err)
+	}
+	getter := func(s *TestTitledSubscriber) string { return s.Handle }
+	setter := func(s *TestTitledSubscriber, handle string) { s.Handle = "@" + handle }
+	if err := DefineProperty(titledType, "At", getter, setter); err != nil {
+		t.Fatalf("DefineProperty returned error: %v", err)
+	}
+
+	factory := NewObjectFactory()
+	ann := factory.CreateObject(&TestTitledSubscriber{Handle: "@ann"})
+	bob := factory.CreateObject(&TestTitledSubscriber{Handle: "@bob"})
+	defer ann.Destroy()
+	defer bob.Destroy()
+
+	var violation *UniqueViolationError
+	if err := bob.SetProp("At", "ann"); !errors.As(err, &violation) {
+		t.Fatalf("SetProp returned %v, want a *UniqueViolationError", err)
+	}
+	if handle := bob.GetUnderlyingObject().(*TestTitledSubscriber).Handle; handle != "@bob" {
+		t.Errorf("SetProp left Handle = %s, want @bob", handle)
+	}
+}
+
+// TestVettedSubscriber is a test class with an indexed field set through a
+// property whose setter can fail
+type TestVettedSubscriber struct {
+	Handle string
+}
+
+// TestFailingSetterProperty tests that SetProp undoes a failing setter and
+// leaves the indexes as they were
+func TestFailingSetterProperty(t *testing.T) {
+	vettedType := reflect.TypeOf(TestVettedSubscriber{})
+	if err := CreateIndex(vettedType, "Handle", Unique); err != nil {
+		t.Fatalf("CreateIndex returned error: %v", err)
+	}
+	getter := func(s *TestVettedSubscriber) string { return s.Handle }
+	setter := func(s *TestVettedSubscriber, handle string) error {
+		s.Handle = handle
+		if handle == "admin" {
+			return errors.New("handle is reserved")
+		}
+		return nil
+	}
+	if err := DefineProperty(vettedType, "Name", getter, setter); err != nil {
+		t.Fatalf("DefineProperty returned error: %v", err)
+	}
+
+	obj := NewObjectFactory().CreateObject(&TestVettedSubscriber{Handle: "ann"})
+	defer obj.Destroy()
+
+	if err := obj.SetProp("Name", "admin"); err == nil {
+		t.Fatal("SetProp should return the error of the setter")
+	}
+	if handle := obj.GetUnderlyingObject().(*TestVettedSubscriber).Handle; handle != "ann" {
+		t.Errorf("SetProp left Handle = %s, want ann", handle)
+	}
+	if objs, err := FindByIndex(vettedType, "Handle", "admin"); err != nil || len(objs) != 0 {
+		t.Errorf("FindByIndex returned %v, %v for the rejected value, want nothing", objs, err)
+	}
+	if objs, err := FindByIndex(vettedType, "Handle", "ann"); err != nil || len(objs) != 1 || objs[0] != obj {
+		t.Errorf("FindByIndex returned %v, %v, want the object", objs, err)
+	}
+}
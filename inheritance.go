@@ -0,0 +1,239 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Extend declares that the child class inherits from the parent class.
+// Both classes are registered under their default name if they are not
+// already; classes registered with WithName keep their name. A class can have a
+// single parent, and a class cannot extend one of its own subclasses.
+// Example: oop.Extend(reflect.TypeOf(Dog{}), reflect.TypeOf(Animal{}))
+func Extend(child, parent reflect.Type) error {
+	childInfo, err := registeredClass(child)
+	if err != nil {
+		return err
+	}
+	parentInfo, err := registeredClass(parent)
+	if err != nil {
+		return err
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if childInfo == parentInfo {
+		return fmt.Errorf("class %s cannot extend itself", childInfo.Name)
+	}
+	if childInfo.Parent != nil {
+		if childInfo.Parent == parentInfo {
+			return nil
+		}
+		return fmt.Errorf("class %s already extends %s", childInfo.Name, childInfo.Parent.Name)
+	}
+	for p := parentInfo; p != nil; p = p.Parent {
+		if p == childInfo {
+			return fmt.Errorf("class %s cannot extend its subclass %s", childInfo.Name, parentInfo.Name)
+		}
+	}
+
+	childInfo.Parent = parentInfo
+	return nil
+}
+
+// IsSubclassOf reports whether child is parent or inherits from it, directly or indirectly.
+// Embedding a struct is an implicit inheritance: a class is also a subclass of
+// the structs it embeds, and of the structs its Extend ancestors embed.
+func IsSubclassOf(child, parent reflect.Type) bool {
+	parent = classElem(parent)
+	if embeds(child, parent) {
+		return true
+	}
+
+	childInfo, ok := LookupType(child)
+	if !ok {
+		return classElem(child) == parent
+	}
+
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	for c := childInfo; c != nil; c = c.Parent {
+		if c.Type == parent || embeds(c.Type, parent) {
+			return true
+		}
+	}
+	return false
+}
+
+// SuperOf returns the ClassInfo of the parent class of a class instance.
+// It returns nil if the class has no parent.
+func SuperOf(klass *Klass) *ClassInfo {
+	if klass == nil || klass.Header.Info == nil {
+		return nil
+	}
+
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	return klass.Header.Info.Parent
+}
+
+// LookupMethod finds a method of the class, walking up the parent chain when the
+// class does not define it. It returns the method and the class that defines it.
+func (c *ClassInfo) LookupMethod(name string) (reflect.Method, *ClassInfo, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	for info := c; info != nil; info = info.Parent {
+		if m, ok := info.methods[name]; ok {
+			return m, info, true
+		}
+	}
+	return reflect.Method{}, nil, false
+}
+
+// IsInstanceOf reports whether obj is an instance of the given type.
+// For class types it checks the object's class and all of its ancestors,
+// using the registered inheritance graph. For interface types it checks
+// whether the object implements the interface.
+// Example: oop.IsInstanceOf(beagle, reflect.TypeOf(Mammal{}))
+func IsInstanceOf(obj interface{}, classType reflect.Type) bool {
+	if obj == nil || classType == nil {
+		return false
+	}
+
+	if classType.Kind() == reflect.Interface {
+		objType := reflect.TypeOf(obj)
+		return implements(objType, classType) ||
+			(objType.Kind() != reflect.Ptr && implements(reflect.PointerTo(objType), classType))
+	}
+
+	if klass, ok := obj.(*Klass); ok {
+		obj = klass.Class
+		if obj == nil {
+			return false
+		}
+	}
+
+	target := makeClassInfo(classElem(classType))
+	return makeClassInfo(classElem(reflect.TypeOf(obj))).IsClass(target.TypeInfo.TypeID)
+}
+
+// embeddedTypes returns the struct types embedded in t, directly or through
+// other embedded structs, in breadth-first order. Like superReceiver, it only
+// follows exported embedded fields, by value or by pointer.
+func embeddedTypes(t reflect.Type) []reflect.Type {
+	if t == nil {
+		return nil
+	}
+
+	var types []reflect.Type
+	seen := map[reflect.Type]bool{}
+	queue := []reflect.Type{classElem(t)}
+	for len(queue) > 0 {
+		st := queue[0]
+		queue = queue[1:]
+		if st.Kind() != reflect.Struct {
+			continue
+		}
+
+		for i := range st.NumField() {
+			sf := st.Field(i)
+			if !sf.Anonymous || !sf.IsExported() {
+				continue
+			}
+			ft := classElem(sf.Type)
+			if ft.Kind() != reflect.Struct || seen[ft] {
+				continue
+			}
+			seen[ft] = true
+			types = append(types, ft)
+			queue = append(queue, ft)
+		}
+	}
+	return types
+}
+
+// implicitParent returns the struct type embedded in t if t embeds exactly one
+// exported struct directly, which makes it the implicit parent class of t.
+func implicitParent(t reflect.Type) (reflect.Type, bool) {
+	t = classElem(t)
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	var parent reflect.Type
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.Anonymous || !sf.IsExported() || classElem(sf.Type).Kind() != reflect.Struct {
+			continue
+		}
+		if parent != nil {
+			return nil, false // Ambiguous, like a promoted method from two embedded structs.
+		}
+		parent = classElem(sf.Type)
+	}
+	return parent, parent != nil
+}
+
+// embeds reports whether the struct type t embeds parent, directly or indirectly.
+func embeds(t, parent reflect.Type) bool {
+	for _, embedded := range embeddedTypes(t) {
+		if embedded == parent {
+			return true
+		}
+	}
+	return false
+}
+
+// promoted returns a pointer to the value of the parent type embedded in v,
+// which is a pointer to a struct or a struct. Values are copied first, so the
+// result never aliases the caller's struct.
+func promoted(v reflect.Value, parent reflect.Type) (reflect.Value, bool) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		return superReceiver(v, parent)
+	case reflect.Struct:
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		return superReceiver(ptr, parent)
+	}
+	return reflect.Value{}, false
+}
+
+// superReceiver returns a pointer to the value of the parent type embedded in
+// the struct v points to, searching embedded structs breadth-first.
+func superReceiver(v reflect.Value, parent reflect.Type) (reflect.Value, bool) {
+	queue := []reflect.Value{v}
+	for len(queue) > 0 {
+		ptr := queue[0]
+		queue = queue[1:]
+
+		elem := ptr.Elem()
+		if elem.Kind() != reflect.Struct {
+			continue
+		}
+		for i := range elem.NumField() {
+			if sf := elem.Type().Field(i); !sf.Anonymous || !sf.IsExported() {
+				continue // Methods cannot be called through unexported fields.
+			}
+
+			field := elem.Field(i)
+			switch {
+			case field.Type() == parent:
+				return field.Addr(), true
+			case field.Type() == reflect.PointerTo(parent) && !field.IsNil():
+				return field, true
+			case field.Kind() == reflect.Struct:
+				queue = append(queue, field.Addr())
+			case field.Kind() == reflect.Ptr && !field.IsNil():
+				queue = append(queue, field)
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
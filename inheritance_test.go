@@ -0,0 +1,271 @@
+package oop
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestMammal is a test base class
+type TestMammal struct {
+	Legs int
+}
+
+// Breathe is defined on the base class only
+func (m *TestMammal) Breathe() string {
+	return "breathing"
+}
+
+// TestCanine is a test class extending TestMammal
+type TestCanine struct {
+	TestMammal
+}
+
+// TestBeagle is a test class extending TestCanine
+type TestBeagle struct {
+	Name string
+}
+
+// TestInheritance tests the Extend, IsSubclassOf and SuperOf functions
+func TestInheritance(t *testing.T) {
+	mammalType := reflect.TypeOf(TestMammal{})
+	canineType := reflect.TypeOf(TestCanine{})
+	beagleType := reflect.TypeOf(TestBeagle{})
+
+	if err := Extend(canineType, mammalType); err != nil {
+		t.Fatalf("Extend returned error: %v", err)
+	}
+	if err := Extend(beagleType, canineType); err != nil {
+		t.Fatalf("Extend returned error: %v", err)
+	}
+
+	// Test that extending again with the same parent is a no-op
+	if err := Extend(beagleType, canineType); err != nil {
+		t.Errorf("Extend returned error for the same parent: %v", err)
+	}
+
+	// Test subclass relationships
+	if !IsSubclassOf(beagleType, mammalType) {
+		t.Error("TestBeagle should be a subclass of TestMammal")
+	}
+	if !IsSubclassOf(beagleType, beagleType) {
+		t.Error("TestBeagle should be a subclass of itself")
+	}
+	if IsSubclassOf(mammalType, beagleType) {
+		t.Error("TestMammal should not be a subclass of TestBeagle")
+	}
+	if IsSubclassOf(beagleType, reflect.TypeOf(TestDog{})) {
+		t.Error("TestBeagle should not be a subclass of an unregistered class")
+	}
+
+	// Test SuperOf
+	klass := New(nil, beagleType, nil)
+	super := SuperOf(klass)
+	if super == nil || super.Type != canineType {
+		t.Errorf("SuperOf returned %v, want TestCanine", super)
+	}
+	if SuperOf(New(nil, mammalType, nil)) != nil {
+		t.Error("SuperOf should return nil for a root class")
+	}
+
+	// Test inherited method lookup
+	beagleInfo, _ := LookupType(beagleType)
+	method, owner, ok := beagleInfo.LookupMethod("Breathe")
+	if !ok {
+		t.Fatal("LookupMethod did not find inherited method")
+	}
+	if method.Name != "Breathe" || owner.Type != canineType {
+		t.Errorf("LookupMethod returned %s from %v, want Breathe from TestCanine", method.Name, owner.Type)
+	}
+	if _, _, ok := beagleInfo.LookupMethod("Unknown"); ok {
+		t.Error("LookupMethod should return false for unknown method")
+	}
+
+	// Test invalid hierarchies
+	if err := Extend(mammalType, beagleType); err == nil {
+		t.Error("Extend should return error for cycles")
+	}
+	if err := Extend(beagleType, mammalType); err == nil {
+		t.Error("Extend should return error when the class already has another parent")
+	}
+	if err := Extend(mammalType, mammalType); err == nil {
+		t.Error("Extend should return error when a class extends itself")
+	}
+}
+
+// TestIsInstanceOf tests the IsInstanceOf function and ClassInfo.IsClass
+func TestIsInstanceOf(t *testing.T) {
+	mammalType := reflect.TypeOf(TestMammal{})
+	canineType := reflect.TypeOf(TestCanine{})
+	beagleType := reflect.TypeOf(TestBeagle{})
+
+	if err := Extend(canineType, mammalType); err != nil {
+		t.Fatalf("Extend returned error: %v", err)
+	}
+	if err := Extend(beagleType, canineType); err != nil {
+		t.Fatalf("Extend returned error: %v", err)
+	}
+
+	beagle := &TestBeagle{Name: "Snoopy"}
+	if !IsInstanceOf(beagle, mammalType) {
+		t.Error("TestBeagle should be an instance of TestMammal")
+	}
+	if !IsInstanceOf(*beagle, reflect.TypeOf(&TestCanine{})) {
+		t.Error("TestBeagle value should be an instance of *TestCanine")
+	}
+	if !IsInstanceOf(beagle, beagleType) {
+		t.Error("TestBeagle should be an instance of itself")
+	}
+	if IsInstanceOf(&TestMammal{}, beagleType) {
+		t.Error("TestMammal should not be an instance of TestBeagle")
+	}
+	if IsInstanceOf(&TestDog{}, mammalType) {
+		t.Error("TestDog should not be an instance of TestMammal")
+	}
+
+	// Test with Klass
+	if !IsInstanceOf(New(nil, beagleType, nil), canineType) {
+		t.Error("Klass of TestBeagle should be an instance of TestCanine")
+	}
+
+	// Test with interface types
+	if !IsInstanceOf(&TestDog{}, reflect.TypeOf((*TestAnimal)(nil)).Elem()) {
+		t.Error("TestDog should be an instance of TestAnimal")
+	}
+	if IsInstanceOf(beagle, reflect.TypeOf((*TestAnimal)(nil)).Elem()) {
+		t.Error("TestBeagle should not be an instance of TestAnimal")
+	}
+
+	// Test IsClass directly
+	info, _ := LookupType(beagleType)
+	mammalInfo, _ := LookupType(mammalType)
+	if !info.IsClass(mammalInfo.TypeInfo.TypeID) {
+		t.Error("IsClass should return true for an ancestor")
+	}
+	if mammalInfo.IsClass(info.TypeInfo.TypeID) {
+		t.Error("IsClass should return false for a subclass")
+	}
+
+	// Test with nil
+	if IsInstanceOf(nil, mammalType) {
+		t.Error("IsInstanceOf should return false for nil")
+	}
+}
+
+// TestKennel embeds TestCanine without being registered
+type TestKennel struct {
+	TestCanine
+	Name string
+}
+
+// TestToy is embedded by TestPuppy
+type TestToy struct {
+	Squeaks bool
+}
+
+// TestPuppy embeds TestToy and extends TestBeagle
+type TestPuppy struct {
+	*TestToy
+	Age int
+}
+
+// TestEmbedding tests that embedded structs are treated as implicit parents
+func TestEmbedding(t *testing.T) {
+	mammalType := reflect.TypeOf(TestMammal{})
+	canineType := reflect.TypeOf(TestCanine{})
+	beagleType := reflect.TypeOf(TestBeagle{})
+	kennelType := reflect.TypeOf(TestKennel{})
+	puppyType := reflect.TypeOf(TestPuppy{})
+
+	if err := Extend(canineType, mammalType); err != nil {
+		t.Fatalf("Extend returned error: %v", err)
+	}
+	if err := Extend(beagleType, canineType); err != nil {
+		t.Fatalf("Extend returned error: %v", err)
+	}
+	if err := Extend(puppyType, beagleType); err != nil {
+		t.Fatalf("Extend returned error: %v", err)
+	}
+
+	// Test subclass relationships through embedding
+	if !IsSubclassOf(kennelType, mammalType) {
+		t.Error("TestKennel should be a subclass of the embedded TestMammal")
+	}
+	if !IsSubclassOf(puppyType, reflect.TypeOf(&TestToy{})) {
+		t.Error("TestPuppy should be a subclass of the embedded TestToy")
+	}
+	if !IsSubclassOf(puppyType, mammalType) {
+		t.Error("TestPuppy should be a subclass of TestMammal through Extend")
+	}
+	if IsSubclassOf(mammalType, kennelType) {
+		t.Error("TestMammal should not be a subclass of TestKennel")
+	}
+
+	// Test instances through embedding
+	kennel := &TestKennel{Name: "Home"}
+	if !IsInstanceOf(kennel, mammalType) {
+		t.Error("TestKennel should be an instance of the embedded TestMammal")
+	}
+	if !IsInstanceOf(New(nil, puppyType, nil), reflect.TypeOf(TestToy{})) {
+		t.Error("Klass of TestPuppy should be an instance of TestToy")
+	}
+
+	// Test Cast to embedded structs
+	mammal, ok := Cast(kennel, reflect.TypeOf(&TestMammal{})).(*TestMammal)
+	if !ok || mammal != &kennel.TestMammal {
+		t.Fatalf("Cast returned %v, want a pointer to the embedded TestMammal", mammal)
+	}
+	mammal.Legs = 4
+	if kennel.Legs != 4 {
+		t.Error("Cast should return a pointer into the original object")
+	}
+	if canine, ok := Cast(*kennel, canineType).(TestCanine); !ok || canine.Legs != 4 {
+		t.Errorf("Cast returned %v, want a copy of the embedded TestCanine", canine)
+	}
+	if Cast(kennel, reflect.TypeOf(&TestDog{})) != nil {
+		t.Error("Cast should return nil for structs that are not embedded")
+	}
+	if Cast(&TestPuppy{}, reflect.TypeOf(&TestToy{})) != nil {
+		t.Error("Cast should return nil for nil embedded pointers")
+	}
+
+	// Test As with embedded structs
+	if got := As(kennel, mammalType); got != &kennel.TestMammal {
+		t.Errorf("As returned %v, want a pointer to the embedded TestMammal", got)
+	}
+	toy := &TestToy{}
+	if got := As(TestPuppy{TestToy: toy}, reflect.TypeOf(TestToy{})); got != toy {
+		t.Errorf("As returned %v, want the embedded *TestToy", got)
+	}
+}
+
+// TestNamedParent is a test base class registered under a custom name
+type TestNamedParent struct {
+	Name string
+}
+
+// TestNamedChild is a test class registered under a custom name
+type TestNamedChild struct {
+	TestNamedParent
+}
+
+// TestExtendNamed tests extending classes registered with WithName
+func TestExtendNamed(t *testing.T) {
+	parentType := reflect.TypeOf(TestNamedParent{})
+	childType := reflect.TypeOf(TestNamedChild{})
+	if _, err := Register(parentType, WithName("zoo.Parent")); err != nil {
+		t.Fatalf("Register returned error: %v", err)
+	}
+	if _, err := Register(childType, WithName("zoo.Child")); err != nil {
+		t.Fatalf("Register returned error: %v", err)
+	}
+
+	if err := Extend(childType, parentType); err != nil {
+		t.Fatalf("Extend returned error: %v", err)
+	}
+	if !IsSubclassOf(childType, parentType) {
+		t.Error("TestNamedChild should be a subclass of TestNamedParent")
+	}
+	if info, _ := LookupType(childType); info.Name != "zoo.Child" || info.Parent.Name != "zoo.Parent" {
+		t.Errorf("Extend changed the names to %s and %s", info.Name, info.Parent.Name)
+	}
+}
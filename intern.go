@@ -0,0 +1,213 @@
+package oop
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// MergePolicy controls how Intern updates an existing object with the
+// fields of a new initializer that has the same natural key.
+type MergePolicy int
+
+const (
+	MergeNonZero MergePolicy = iota // Copy the exported fields of the initializer that are not zero.
+	MergeReplace                    // Copy all exported fields of the initializer.
+	MergeKeep                       // Keep the existing object unchanged.
+)
+
+// InternOption configures a single Intern call.
+type InternOption func(*internOptions)
+
+// internOptions holds the options of a single Intern call.
+type internOptions struct {
+	policy MergePolicy
+}
+
+// WithMergePolicy sets how Intern updates an existing object, MergeNonZero by default.
+// Example: factory.Intern(&User{Email: "a@b.c"}, oop.WithMergePolicy(oop.MergeKeep))
+func WithMergePolicy(policy MergePolicy) InternOption {
+	return func(o *internOptions) {
+		o.policy = policy
+	}
+}
+
+// internKey identifies an interned object by class and natural key.
+type internKey struct {
+	classType reflect.Type
+	key       string // Canonical encoding of the natural key fields.
+}
+
+// internTable holds the live interned objects of a factory.
+type internTable struct {
+	mu      sync.Mutex
+	objects map[internKey]*ObjectWrapper
+}
+
+// RegisterNaturalKey declares the exported fields that identify instances of a
+// class in the domain, e.g. an email address, so Intern can reuse existing
+// objects. The class is registered if it is not already.
+// Example: oop.RegisterNaturalKey(reflect.TypeOf(User{}), "Email")
+func RegisterNaturalKey(classType reflect.Type, fieldNames ...string) error {
+	if classType == nil {
+		return fmt.Errorf("classType cannot be nil")
+	}
+	if len(fieldNames) == 0 {
+		return fmt.Errorf("natural key needs at least one field")
+	}
+
+	classType = classElem(classType)
+	if classType.Kind() != reflect.Struct {
+		return fmt.Errorf("class %s must be a struct", classType)
+	}
+
+	indexes := make([]int, len(fieldNames))
+	for i, name := range fieldNames {
+		field, ok := classType.FieldByName(name)
+		if !ok || !field.IsExported() || len(field.Index) != 1 {
+			return fmt.Errorf("field %s not found on %s", name, classType)
+		}
+		indexes[i] = field.Index[0]
+	}
+
+	info, err := registeredClass(classType)
+	if err != nil {
+		return err
+	}
+
+	info.mu.Lock()
+	defer info.mu.Unlock()
+	info.naturalKey = indexes
+	return nil
+}
+
+// Intern returns the live object of the factory with the same natural key as
+// init, see RegisterNaturalKey, updating its fields according to the merge
+// policy. If there is none, it creates and returns a new object like
+// CreateObject. Destroyed and soft-deleted objects are forgotten, so the next
+// Intern with their key creates a new object.
+// Example: user, err := factory.Intern(&User{Email: "a@b.c", Name: "Ann"})
+func (f *ObjectFactory) Intern(init interface{}, opts ...InternOption) (*ObjectWrapper, error) {
+	v := reflect.ValueOf(init)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("init must be a pointer to a struct, got %T", init)
+	}
+
+	options := internOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	key, err := naturalKeyOf(v.Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	f.interned.mu.Lock()
+	defer f.interned.mu.Unlock()
+
+	if existing, ok := f.interned.objects[key]; ok {
+		err := existing.merge(v.Elem(), options.policy)
+		if err == nil {
+			return existing, nil
+		}
+		var violation *UniqueViolationError
+		if errors.As(err, &violation) {
+			return nil, err
+		}
+		delete(f.interned.objects, key) // Destroyed but not yet forgotten, or soft-deleted.
+	}
+
+	obj, err := f.CreateObjectE(init)
+	if err != nil {
+		return nil, err
+	}
+	f.intern(key, obj)
+	return obj, nil
+}
+
+// naturalKeyOf returns the intern key of a struct value of a class with a
+// natural key.
+func naturalKeyOf(v reflect.Value) (internKey, error) {
+	classType := v.Type()
+	info, ok := LookupType(classType)
+	if !ok {
+		return internKey{}, fmt.Errorf("class %s has no natural key", classType)
+	}
+	info.mu.Lock()
+	indexes := info.naturalKey
+	info.mu.Unlock()
+	if len(indexes) == 0 {
+		return internKey{}, fmt.Errorf("class %s has no natural key", classType)
+	}
+
+	values := make([]interface{}, len(indexes))
+	for i, index := range indexes {
+		values[i] = v.Field(index).Interface()
+	}
+	encoded, err := CanonicalBytes(values)
+	if err != nil {
+		return internKey{}, fmt.Errorf("natural key of %s: %w", classType, err)
+	}
+	return internKey{classType: classType, key: string(encoded)}, nil
+}
+
+// intern adds an object to the intern table under key, until it is destroyed.
+// The caller must hold the intern table lock.
+func (f *ObjectFactory) intern(key internKey, obj *ObjectWrapper) {
+	obj.OnTransition(func(from, to State) {
+		if to == StateDestroyed {
+			f.forget(key, obj)
+		}
+	})
+
+	if f.interned.objects == nil {
+		f.interned.objects = map[internKey]*ObjectWrapper{}
+	}
+	f.interned.objects[key] = obj
+}
+
+// forget removes a destroyed object from the intern table.
+func (f *ObjectFactory) forget(key internKey, obj *ObjectWrapper) {
+	f.interned.mu.Lock()
+	defer f.interned.mu.Unlock()
+
+	if f.interned.objects[key] == obj {
+		delete(f.interned.objects, key)
+	}
+}
+
+// merge copies the exported fields of src into the object according to the policy.
+func (o *ObjectWrapper) merge(src reflect.Value, policy MergePolicy) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if err := o.checkMutable(); err != nil {
+		return err
+	}
+	target, err := o.structValue()
+	if err != nil {
+		return err
+	}
+	if policy == MergeKeep || target.Addr().Pointer() == src.Addr().Pointer() {
+		return nil
+	}
+
+	var assignments []assignment
+	pending := map[int]reflect.Value{}
+	for _, field := range exportedFields(target.Type()) {
+		value := src.Field(field.Index[0])
+		if policy == MergeNonZero && value.IsZero() {
+			continue
+		}
+		assignments = append(assignments, assignment{index: field.Index, value: value})
+		pending[field.Index[0]] = value
+	}
+	return o.indexedWrite(target, pending, func() error {
+		for _, a := range assignments {
+			setField(target, a.index, a.value)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,110 @@
+package oop
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// TestCustomer is a test class identified by its email address
+type TestCustomer struct {
+	Email string
+	Name  string
+	Age   int
+}
+
+// TestIntern tests the RegisterNaturalKey function and the Intern method
+func TestIntern(t *testing.T) {
+	customerType := reflect.TypeOf(TestCustomer{})
+	if err := RegisterNaturalKey(customerType, "Email"); err != nil {
+		t.Fatalf("RegisterNaturalKey returned error: %v", err)
+	}
+	factory := NewObjectFactory()
+
+	ann, err := factory.Intern(&TestCustomer{Email: "ann@example.com", Name: "Ann", Age: 30})
+	if err != nil {
+		t.Fatalf("Intern returned error: %v", err)
+	}
+	defer ann.Destroy()
+
+	// Test the default merge policy
+	again, err := factory.Intern(&TestCustomer{Email: "ann@example.com", Age: 31})
+	if err != nil || again != ann {
+		t.Fatalf("Intern returned %v, %v, want the existing object", again, err)
+	}
+	if got := ann.GetUnderlyingObject().(*TestCustomer); got.Name != "Ann" || got.Age != 31 {
+		t.Errorf("Intern merged %+v, want the non-zero fields updated", got)
+	}
+
+	// Test the other merge policies
+	factory.Intern(&TestCustomer{Email: "ann@example.com", Name: "Annie"}, WithMergePolicy(MergeKeep))
+	if got := ann.GetUnderlyingObject().(*TestCustomer); got.Name != "Ann" {
+		t.Errorf("MergeKeep changed the object to %+v", got)
+	}
+	factory.Intern(&TestCustomer{Email: "ann@example.com", Name: "Annie"}, WithMergePolicy(MergeReplace))
+	if got := ann.GetUnderlyingObject().(*TestCustomer); got.Name != "Annie" || got.Age != 0 {
+		t.Errorf("MergeReplace changed the object to %+v, want all fields replaced", got)
+	}
+
+	// Test other keys and factories
+	bob, _ := factory.Intern(&TestCustomer{Email: "bob@example.com"})
+	defer bob.Destroy()
+	if bob == ann {
+		t.Error("Intern should create a new object for another key")
+	}
+	other, _ := NewObjectFactory().Intern(&TestCustomer{Email: "ann@example.com"})
+	defer other.Destroy()
+	if other == ann {
+		t.Error("Intern should not share objects between factories")
+	}
+
+	// Test that destroyed objects are forgotten
+	ann.Destroy()
+	fresh, _ := factory.Intern(&TestCustomer{Email: "ann@example.com"})
+	defer fresh.Destroy()
+	if fresh == ann || fresh.GetUnderlyingObject() == nil {
+		t.Error("Intern should create a new object after the old one was destroyed")
+	}
+
+	// Test concurrent interning
+	var wg sync.WaitGroup
+	results := make([]*ObjectWrapper, 8)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = factory.Intern(&TestCustomer{Email: "carol@example.com", Age: i})
+		}()
+	}
+	wg.Wait()
+	for _, obj := range results {
+		if obj != results[0] {
+			t.Fatal("concurrent Intern calls should return the same object")
+		}
+	}
+	results[0].Destroy()
+}
+
+// TestInternErrors tests the errors of RegisterNaturalKey and Intern
+func TestInternErrors(t *testing.T) {
+	if err := RegisterNaturalKey(nil, "Email"); err == nil {
+		t.Error("RegisterNaturalKey should return error for nil classType")
+	}
+	if err := RegisterNaturalKey(reflect.TypeOf(TestCustomer{})); err == nil {
+		t.Error("RegisterNaturalKey should return error without fields")
+	}
+	if err := RegisterNaturalKey(reflect.TypeOf(TestCustomer{}), "Unknown"); err == nil {
+		t.Error("RegisterNaturalKey should return error for unknown fields")
+	}
+	if err := RegisterNaturalKey(reflect.TypeOf(0), "Email"); err == nil {
+		t.Error("RegisterNaturalKey should return error for non-structs")
+	}
+
+	factory := NewObjectFactory()
+	if _, err := factory.Intern(TestCustomer{}); err == nil {
+		t.Error("Intern should return error for non-pointers")
+	}
+	if _, err := factory.Intern(&TestGreeter{}); err == nil {
+		t.Error("Intern should return error for classes without a natural key")
+	}
+}
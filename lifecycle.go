@@ -0,0 +1,173 @@
+package oop
+
+import (
+	"fmt"
+)
+
+// State is the lifecycle state of a wrapped object.
+type State int32
+
+const (
+	StateCreated     State = iota // Created by the factory, not yet initialized.
+	StateInitialized              // Initialized, or stopped and ready to start again.
+	StateStarted                  // Started and running.
+	StateStopping                 // Stopping; a failed Stop leaves the object here.
+	StateDestroyed                // Destroyed; no further transitions are possible.
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case StateCreated:
+		return "created"
+	case StateInitialized:
+		return "initialized"
+	case StateStarted:
+		return "started"
+	case StateStopping:
+		return "stopping"
+	case StateDestroyed:
+		return "destroyed"
+	default:
+		return "unknown"
+	}
+}
+
+// transitions lists the valid target states of each state.
+var transitions = map[State][]State{
+	StateCreated:     {StateInitialized, StateDestroyed},
+	StateInitialized: {StateStarted, StateDestroyed},
+	StateStarted:     {StateStopping},
+	StateStopping:    {StateInitialized, StateDestroyed},
+}
+
+// canTransition reports whether an object can move from one state to another.
+func canTransition(from, to State) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// State returns the lifecycle state of the object.
+func (o *ObjectWrapper) State() State {
+	return State(o.state.Load())
+}
+
+// OnTransition registers a function that is called after every state change of the object.
+// Hooks run once the lifecycle lock is released, so they may change the state
+// of the object themselves, e.g. destroy it when it stops.
+// Example: dogObj.OnTransition(func(from, to oop.State) { log.Println(from, "->", to) })
+func (o *ObjectWrapper) OnTransition(fn func(from, to State)) {
+	o.hooksMu.Lock()
+	defer o.hooksMu.Unlock()
+	o.transitionHooks = append(o.transitionHooks, fn)
+}
+
+// Initialize moves the object from created to initialized.
+// It calls the object's Init() error method, if it has one; on error the
+// object stays in the created state.
+func (o *ObjectWrapper) Initialize() error {
+	o.lifecycleMu.Lock()
+	defer o.unlockLifecycle()
+
+	return o.transition(StateInitialized, func(obj interface{}) error {
+		if initer, ok := obj.(interface{ Init() error }); ok {
+			return initer.Init()
+		}
+		return nil
+	})
+}
+
+// Start moves the object from initialized to started.
+// It calls the object's Start() error method, if it has one; on error the
+// object stays in the initialized state.
+func (o *ObjectWrapper) Start() error {
+	o.lifecycleMu.Lock()
+	defer o.unlockLifecycle()
+
+	return o.transition(StateStarted, func(obj interface{}) error {
+		if starter, ok := obj.(interface{ Start() error }); ok {
+			return starter.Start()
+		}
+		return nil
+	})
+}
+
+// Stop moves the object from started through stopping back to initialized,
+// so it can be started again. It calls the object's Stop() error method, if it
+// has one; on error the object stays in the stopping state.
+func (o *ObjectWrapper) Stop() error {
+	o.lifecycleMu.Lock()
+	defer o.unlockLifecycle()
+	return o.stop()
+}
+
+// stop implements Stop. The caller must hold the lifecycle lock.
+func (o *ObjectWrapper) stop() error {
+	if err := o.transition(StateStopping, nil); err != nil {
+		return err
+	}
+
+	if stopper, ok := o.class().(interface{ Stop() error }); ok {
+		if err := stopper.Stop(); err != nil {
+			return err
+		}
+	}
+
+	return o.transition(StateInitialized, nil)
+}
+
+// transition validates and performs a state change, calling action with the
+// underlying object first. The caller must hold the lifecycle lock and release
+// it with unlockLifecycle, which reports the change to the hooks.
+func (o *ObjectWrapper) transition(to State, action func(obj interface{}) error) error {
+	from := o.State()
+	if !canTransition(from, to) {
+		return fmt.Errorf("cannot transition from %s to %s", from, to)
+	}
+
+	if action != nil {
+		obj := o.class()
+		if obj == nil {
+			return fmt.Errorf("object is not initialized")
+		}
+		if err := action(obj); err != nil {
+			return err
+		}
+	}
+
+	o.state.Store(int32(to))
+	o.pendingTransitions = append(o.pendingTransitions, transitionEvent{from: from, to: to})
+	return nil
+}
+
+// transitionEvent is a state change not yet reported to the transition hooks.
+type transitionEvent struct {
+	from, to State
+}
+
+// unlockLifecycle releases the lifecycle lock, then calls the transition hooks
+// for the state changes made while it was held.
+func (o *ObjectWrapper) unlockLifecycle() {
+	events := o.pendingTransitions
+	o.pendingTransitions = nil
+	o.lifecycleMu.Unlock()
+
+	for _, event := range events {
+		o.fireTransition(event.from, event.to)
+	}
+}
+
+// fireTransition calls the transition hooks of the object.
+func (o *ObjectWrapper) fireTransition(from, to State) {
+	o.hooksMu.Lock()
+	hooks := o.transitionHooks
+	o.hooksMu.Unlock()
+
+	for _, fn := range hooks {
+		fn(from, to)
+	}
+}
@@ -0,0 +1,153 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestService is a test class with lifecycle methods
+type TestService struct {
+	Calls   []string
+	FailRun bool
+}
+
+// Init initializes the service
+func (s *TestService) Init() error {
+	s.Calls = append(s.Calls, "init")
+	return nil
+}
+
+// Start starts the service
+func (s *TestService) Start() error {
+	if s.FailRun {
+		return fmt.Errorf("start failed")
+	}
+	s.Calls = append(s.Calls, "start")
+	return nil
+}
+
+// Stop stops the service
+func (s *TestService) Stop() error {
+	s.Calls = append(s.Calls, "stop")
+	return nil
+}
+
+// TestLifecycle tests the lifecycle state machine of ObjectWrapper
+func TestLifecycle(t *testing.T) {
+	service := &TestService{}
+	obj := NewObjectFactory().CreateObject(service)
+
+	var transitions []string
+	obj.OnTransition(func(from, to State) {
+		transitions = append(transitions, from.String()+"->"+to.String())
+	})
+
+	if obj.State() != StateCreated {
+		t.Errorf("State is %s, want created", obj.State())
+	}
+	if err := obj.Start(); err == nil {
+		t.Error("Start should return error before Initialize")
+	}
+
+	if err := obj.Initialize(); err != nil {
+		t.Fatalf("Initialize returned error: %v", err)
+	}
+	if err := obj.Start(); err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+	if err := obj.Initialize(); err == nil {
+		t.Error("Initialize should return error for a started object")
+	}
+	if err := obj.Stop(); err != nil {
+		t.Fatalf("Stop returned error: %v", err)
+	}
+	if obj.State() != StateInitialized {
+		t.Errorf("State after Stop is %s, want initialized", obj.State())
+	}
+
+	// Test that a failed action leaves the state unchanged
+	service.FailRun = true
+	if err := obj.Start(); err == nil {
+		t.Error("Start should return the error of the object's Start method")
+	}
+	if obj.State() != StateInitialized {
+		t.Errorf("State after failed Start is %s, want initialized", obj.State())
+	}
+	service.FailRun = false
+
+	// Test that Destroy stops a started object
+	if err := obj.Start(); err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+	obj.Destroy()
+	obj.Destroy()
+
+	wantCalls := []string{"init", "start", "stop", "start", "stop"}
+	if !reflect.DeepEqual(service.Calls, wantCalls) {
+		t.Errorf("Calls are %v, want %v", service.Calls, wantCalls)
+	}
+
+	wantTransitions := []string{
+		"created->initialized",
+		"initialized->started",
+		"started->stopping",
+		"stopping->initialized",
+		"initialized->started",
+		"started->stopping",
+		"stopping->initialized",
+		"initialized->destroyed",
+	}
+	if !reflect.DeepEqual(transitions, wantTransitions) {
+		t.Errorf("Transitions are %v, want %v", transitions, wantTransitions)
+	}
+
+	if obj.State() != StateDestroyed {
+		t.Errorf("State is %s, want destroyed", obj.State())
+	}
+	if err := obj.Initialize(); err == nil {
+		t.Error("Initialize should return error for a destroyed object")
+	}
+	if State(42).String() != "unknown" {
+		t.Errorf("State(42).String() = %s, want unknown", State(42))
+	}
+}
+
+// TestTransitionHooksReenter tests that transition hooks may change the state of their object
+func TestTransitionHooksReenter(t *testing.T) {
+	obj := NewObjectFactory().CreateObject(&TestService{})
+
+	var transitions []string
+	obj.OnTransition(func(from, to State) {
+		transitions = append(transitions, from.String()+"->"+to.String())
+		switch to {
+		case StateStarted:
+			obj.Stop() // Stops as soon as it starts.
+		case StateInitialized:
+			if from == StateStopping {
+				obj.Destroy()
+			}
+		}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		obj.Initialize()
+		obj.Start()
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("a transition hook calling Stop or Destroy deadlocked")
+	}
+
+	want := []string{"created->initialized", "initialized->started", "started->stopping", "stopping->initialized", "initialized->destroyed"}
+	if !reflect.DeepEqual(transitions, want) {
+		t.Errorf("the hooks saw %v, want %v", transitions, want)
+	}
+	if obj.State() != StateDestroyed {
+		t.Errorf("State is %v, want destroyed", obj.State())
+	}
+}
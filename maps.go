@@ -0,0 +1,247 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MapOption configures how objects are converted to and from maps.
+type MapOption func(*mapOptions)
+
+// mapOptions holds the options of a single ToMap or FromMap call.
+type mapOptions struct {
+	tag       string
+	omitEmpty bool
+}
+
+// WithTag takes map keys from the given struct tag, e.g. "json" or "bson".
+// Fields tagged "-" are skipped, and fields without the tag use their Go name.
+// The tag's omitempty option is honored by ToMap.
+// Example: dogObj.ToMap(oop.WithTag("json"))
+func WithTag(tag string) MapOption {
+	return func(o *mapOptions) {
+		o.tag = tag
+	}
+}
+
+// WithOmitEmpty makes ToMap leave out all fields with zero values.
+func WithOmitEmpty() MapOption {
+	return func(o *mapOptions) {
+		o.omitEmpty = true
+	}
+}
+
+// ToMap converts the exported fields of the object to a map.
+// Nested structs, pointers to structs, and slices of them are converted to
+// nested maps and []interface{} values. Embedded structs without a tag name
+// are flattened into the parent map, like encoding/json does.
+// Soft-deleted objects cannot be converted.
+// Example: values, err := dogObj.ToMap(oop.WithTag("json"))
+func (o *ObjectWrapper) ToMap(opts ...MapOption) (map[string]interface{}, error) {
+	if o.IsSoftDeleted() {
+		return nil, fmt.Errorf("object is soft-deleted")
+	}
+	target, err := o.structValue()
+	if err != nil {
+		return nil, err
+	}
+
+	options := mapOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	values := map[string]interface{}{}
+	structToMap(target, values, options)
+	return values, nil
+}
+
+// FromMap creates a new object of the specified class type from a map.
+// Keys are matched like ToMap produces them, and keys without a matching field
+// are ignored. Nested maps and []interface{} values fill nested structs and
+// slices, and numeric values are converted between numeric types, so maps
+// decoded from JSON can be used directly.
+// Example: dogObj, err := factory.FromMap(reflect.TypeOf(Dog{}), map[string]interface{}{"Name": "Rex"})
+func (f *ObjectFactory) FromMap(classType reflect.Type, values map[string]interface{}, opts ...MapOption) (*ObjectWrapper, error) {
+	if classType == nil {
+		return nil, fmt.Errorf("classType cannot be nil")
+	}
+
+	options := mapOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	obj, err := f.newObject(classType)
+	if err != nil {
+		return nil, err
+	}
+	target, err := obj.structValue()
+	if err == nil {
+		err = mapToStruct(target, values, options)
+	}
+	if err == nil {
+		err = obj.indexedWrite(target, nil, nil)
+	}
+	if err != nil {
+		obj.Destroy()
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// mapKey returns the map key of a struct field and whether the field is
+// skipped or left out when empty.
+func mapKey(field reflect.StructField, opts mapOptions) (key string, named, omitEmpty, skip bool) {
+	key = field.Name
+	omitEmpty = opts.omitEmpty
+	if opts.tag == "" {
+		return key, false, omitEmpty, false
+	}
+
+	tag, ok := field.Tag.Lookup(opts.tag)
+	if !ok {
+		return key, false, omitEmpty, false
+	}
+	if tag == "-" {
+		return "", false, false, true
+	}
+
+	name, options, _ := strings.Cut(tag, ",")
+	if name != "" {
+		key, named = name, true
+	}
+	for _, option := range strings.Split(options, ",") {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return key, named, omitEmpty, false
+}
+
+// structToMap adds the exported fields of a struct value to values.
+func structToMap(v reflect.Value, values map[string]interface{}, opts mapOptions) {
+	for _, field := range exportedFields(v.Type()) {
+		key, named, omitEmpty, skip := mapKey(field, opts)
+		if skip {
+			continue
+		}
+
+		fv := v.Field(field.Index[0])
+		if field.Anonymous && !named && fv.Kind() == reflect.Struct {
+			structToMap(fv, values, opts) // Flatten embedded structs.
+			continue
+		}
+		if omitEmpty && fv.IsZero() {
+			continue
+		}
+
+		values[key] = toMapValue(fv, opts)
+	}
+}
+
+// toMapValue converts a field value for ToMap.
+func toMapValue(v reflect.Value, opts mapOptions) interface{} {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		if isMappedStruct(v.Type().Elem()) {
+			return toMapValue(v.Elem(), opts)
+		}
+	case reflect.Struct:
+		if isMappedStruct(v.Type()) {
+			values := map[string]interface{}{}
+			structToMap(v, values, opts)
+			return values
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		if elem := v.Type().Elem(); isMappedStruct(elem) || (elem.Kind() == reflect.Ptr && isMappedStruct(elem.Elem())) {
+			items := make([]interface{}, v.Len())
+			for i := range items {
+				items[i] = toMapValue(v.Index(i), opts)
+			}
+			return items
+		}
+	}
+
+	return v.Interface()
+}
+
+// isMappedStruct reports whether values of a type are converted to nested maps.
+// Structs without exported fields, such as time.Time, are kept as they are.
+func isMappedStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && len(exportedFields(t)) > 0
+}
+
+// mapToStruct sets the exported fields of a struct value from values.
+func mapToStruct(v reflect.Value, values map[string]interface{}, opts mapOptions) error {
+	for _, field := range exportedFields(v.Type()) {
+		key, named, _, skip := mapKey(field, opts)
+		if skip {
+			continue
+		}
+
+		fv := v.Field(field.Index[0])
+		if field.Anonymous && !named && fv.Kind() == reflect.Struct {
+			if err := mapToStruct(fv, values, opts); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value, ok := values[key]
+		if !ok {
+			continue
+		}
+		if err := fromMapValue(fv, value, opts); err != nil {
+			return fmt.Errorf("field %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// fromMapValue sets a field value from a map value for FromMap.
+func fromMapValue(target reflect.Value, value interface{}, opts mapOptions) error {
+	t := target.Type()
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if t.Kind() == reflect.Struct {
+			return mapToStruct(target, value, opts)
+		}
+		if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct {
+			ptr := reflect.New(t.Elem())
+			if err := mapToStruct(ptr.Elem(), value, opts); err != nil {
+				return err
+			}
+			target.Set(ptr)
+			return nil
+		}
+	case []interface{}:
+		if t.Kind() == reflect.Slice {
+			items := reflect.MakeSlice(t, len(value), len(value))
+			for i, item := range value {
+				if err := fromMapValue(items.Index(i), item, opts); err != nil {
+					return fmt.Errorf("index %d: %w", i, err)
+				}
+			}
+			target.Set(items)
+			return nil
+		}
+	}
+
+	converted, err := fieldValue(t, value)
+	if err != nil {
+		return err
+	}
+	target.Set(converted)
+	return nil
+}
@@ -0,0 +1,138 @@
+package oop
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestMapAddress is a nested test struct for map conversion
+type TestMapAddress struct {
+	City string `json:"city"`
+}
+
+// TestMapBase is an embedded test struct for map conversion
+type TestMapBase struct {
+	ID int `json:"id"`
+}
+
+// TestMapPerson is a test class for map conversion
+type TestMapPerson struct {
+	TestMapBase
+	Name      string            `json:"name"`
+	Age       int               `json:"age,omitempty"`
+	Password  string            `json:"-"`
+	Home      TestMapAddress    `json:"home"`
+	Work      *TestMapAddress   `json:"work"`
+	Previous  []TestMapAddress  `json:"previous"`
+	Tags      []string          `json:"tags"`
+	CreatedAt time.Time         `json:"created_at"`
+	Extra     map[string]string `json:"extra"`
+}
+
+// TestToMap tests the ToMap method of ObjectWrapper
+func TestToMap(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	obj := NewObjectFactory().CreateObject(&TestMapPerson{
+		TestMapBase: TestMapBase{ID: 7},
+		Name:        "Ann",
+		Password:    "secret",
+		Home:        TestMapAddress{City: "Paris"},
+		Work:        &TestMapAddress{City: "Lyon"},
+		Previous:    []TestMapAddress{{City: "Nice"}},
+		Tags:        []string{"a"},
+		CreatedAt:   created,
+	})
+	defer obj.Destroy()
+
+	values, err := obj.ToMap(WithTag("json"))
+	if err != nil {
+		t.Fatalf("ToMap returned error: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"id":         7,
+		"name":       "Ann",
+		"home":       map[string]interface{}{"city": "Paris"},
+		"work":       map[string]interface{}{"city": "Lyon"},
+		"previous":   []interface{}{map[string]interface{}{"city": "Nice"}},
+		"tags":       []string{"a"},
+		"created_at": created,
+		"extra":      map[string]string(nil),
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("ToMap returned %v, want %v", values, want)
+	}
+
+	// Test without tags
+	values, err = obj.ToMap(WithOmitEmpty())
+	if err != nil {
+		t.Fatalf("ToMap returned error: %v", err)
+	}
+	if values["Name"] != "Ann" || values["Password"] != "secret" || values["ID"] != 7 {
+		t.Errorf("ToMap returned %v, want Go field names", values)
+	}
+	if _, ok := values["Age"]; ok {
+		t.Error("ToMap should omit empty fields with WithOmitEmpty")
+	}
+
+	// Test with a destroyed object
+	obj.Destroy()
+	if _, err := obj.ToMap(); err == nil {
+		t.Error("ToMap should return error for a destroyed object")
+	}
+}
+
+// TestFromMap tests the FromMap method of ObjectFactory
+func TestFromMap(t *testing.T) {
+	factory := NewObjectFactory()
+	personType := reflect.TypeOf(TestMapPerson{})
+
+	obj, err := factory.FromMap(personType, map[string]interface{}{
+		"id":       float64(7), // Numbers decoded from JSON are float64
+		"name":     "Ann",
+		"password": "ignored",
+		"home":     map[string]interface{}{"city": "Paris"},
+		"work":     map[string]interface{}{"city": "Lyon"},
+		"previous": []interface{}{map[string]interface{}{"city": "Nice"}},
+		"tags":     []interface{}{"a", "b"},
+		"unknown":  true,
+	}, WithTag("json"))
+	if err != nil {
+		t.Fatalf("FromMap returned error: %v", err)
+	}
+	defer obj.Destroy()
+
+	person := obj.GetUnderlyingObject().(*TestMapPerson)
+	if person.ID != 7 || person.Name != "Ann" || person.Password != "" {
+		t.Errorf("FromMap set %+v, want ID 7 and Name Ann", person)
+	}
+	if person.Home.City != "Paris" || person.Work == nil || person.Work.City != "Lyon" {
+		t.Errorf("FromMap did not set nested structs, got %+v", person)
+	}
+	if len(person.Previous) != 1 || person.Previous[0].City != "Nice" {
+		t.Errorf("FromMap did not set struct slices, got %+v", person.Previous)
+	}
+	if !reflect.DeepEqual(person.Tags, []string{"a", "b"}) {
+		t.Errorf("FromMap set Tags to %v, want [a b]", person.Tags)
+	}
+
+	// Test round-trip
+	values, _ := obj.ToMap(WithTag("json"))
+	again, err := factory.FromMap(personType, values, WithTag("json"))
+	if err != nil {
+		t.Fatalf("FromMap returned error: %v", err)
+	}
+	defer again.Destroy()
+	if !reflect.DeepEqual(again.GetUnderlyingObject(), person) {
+		t.Errorf("Round-trip returned %+v, want %+v", again.GetUnderlyingObject(), person)
+	}
+
+	// Test invalid values
+	if _, err := factory.FromMap(personType, map[string]interface{}{"Name": 42}); err == nil {
+		t.Error("FromMap should return error for values of the wrong type")
+	}
+	if _, err := factory.FromMap(nil, nil); err == nil {
+		t.Error("FromMap should return error for a nil type")
+	}
+}
@@ -0,0 +1,341 @@
+package oop
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"unsafe"
+	"weak"
+)
+
+// BindMethod returns a function that calls the named method of obj with the
+// given leading arguments already applied (partial application).
+// The remaining arguments are passed when the returned function is called.
+// The receiver is captured strongly, so obj stays alive as long as the function
+// does; BindMethodWeak captures it weakly.
+// Example: greet, err := oop.BindMethod(dog, "Greet", "Hello")
+func BindMethod(obj interface{}, method string, args ...interface{}) (func(rest ...interface{}) ([]interface{}, error), error) {
+	m, err := methodByName(obj, method)
+	if err != nil {
+		return nil, err
+	}
+	bound, err := bindArgs(m, method, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(rest ...interface{}) ([]interface{}, error) {
+		return invokeMethod(obj, method, m, append(bound[:len(bound):len(bound)], rest...))
+	}, nil
+}
+
+// BindMethodWeak returns a function like BindMethod, but captures the receiver
+// weakly, so the function does not keep obj alive, e.g. for callbacks wired
+// into a long-lived event system. obj must be a non-nil pointer. Once obj has
+// been garbage collected, the function returns an error instead of calling
+// the method.
+// Example: refresh, err := oop.BindMethodWeak(widget, "Refresh")
+func BindMethodWeak(obj interface{}, method string, args ...interface{}) (func(rest ...interface{}) ([]interface{}, error), error) {
+	m, err := methodByName(obj, method)
+	if err != nil {
+		return nil, err
+	}
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil, fmt.Errorf("obj must be a non-nil pointer to be captured weakly, got %T", obj)
+	}
+	bound, err := bindArgs(m, method, args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Neither obj nor the method value bound to it is captured, only the type
+	// and the method index to bind it again on each call.
+	t := v.Type()
+	index := -1
+	if sm, ok := t.MethodByName(method); ok {
+		index = sm.Index
+	}
+	receiver := weak.Make((*byte)(v.UnsafePointer()))
+	return func(rest ...interface{}) ([]interface{}, error) {
+		ptr := receiver.Value()
+		if ptr == nil {
+			return nil, fmt.Errorf("receiver of method %s has been garbage collected", method)
+		}
+		recv := reflect.NewAt(t.Elem(), unsafe.Pointer(ptr))
+		return invokeMethod(recv.Interface(), method, recv.Method(index), append(bound[:len(bound):len(bound)], rest...))
+	}, nil
+}
+
+// bindArgs validates the leading arguments bound to a method and returns a
+// copy of them, so mistakes surface at bind time.
+func bindArgs(m reflect.Value, method string, args []interface{}) ([]interface{}, error) {
+	if !m.Type().IsVariadic() && len(args) > m.Type().NumIn() {
+		return nil, fmt.Errorf("method %s takes %d arguments, %d bound", method, m.Type().NumIn(), len(args))
+	}
+	return append([]interface{}{}, args...), nil
+}
+
+// methodByName returns the named method of obj bound to obj.
+func methodByName(obj interface{}, name string) (reflect.Value, error) {
+	if obj == nil {
+		return reflect.Value{}, fmt.Errorf("obj cannot be nil")
+	}
+
+	m := reflect.ValueOf(obj).MethodByName(name)
+	if !m.IsValid() {
+		return reflect.Value{}, fmt.Errorf("method %s not found on %T", name, obj)
+	}
+
+	return m, nil
+}
+
+// invokeMethod calls the named method of obj, which m must be bound to.
+// It is the common path of all dynamic invocations and reports deprecated methods.
+func invokeMethod(obj interface{}, name string, m reflect.Value, args []interface{}) ([]interface{}, error) {
+	warnDeprecated(reflect.TypeOf(obj), name)
+	return callMethod(m, args)
+}
+
+// callMethod calls a bound method value with the given arguments.
+// Arguments are converted like SetFields values: nil becomes the zero value and
+// numeric values are converted between numeric types. Variadic methods accept
+// their trailing arguments individually.
+func callMethod(m reflect.Value, args []interface{}) ([]interface{}, error) {
+	mt := m.Type()
+	numIn := mt.NumIn()
+
+	if mt.IsVariadic() {
+		if len(args) < numIn-1 {
+			return nil, fmt.Errorf("method takes at least %d arguments, got %d", numIn-1, len(args))
+		}
+	} else if len(args) != numIn {
+		return nil, fmt.Errorf("method takes %d arguments, got %d", numIn, len(args))
+	}
+
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		var argType reflect.Type
+		if mt.IsVariadic() && i >= numIn-1 {
+			argType = mt.In(numIn - 1).Elem()
+		} else {
+			argType = mt.In(i)
+		}
+
+		v, err := fieldValue(argType, arg)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i, err)
+		}
+		in[i] = v
+	}
+
+	out := m.Call(in)
+
+	results := make([]interface{}, len(out))
+	for i, v := range out {
+		results[i] = v.Interface()
+	}
+
+	return results, nil
+}
+
+// BoundMethod is a first-class reference to a method of a specific object.
+// References created with MethodByRef can be serialized as the Ref of the
+// object and the method name, see MarshalJSON.
+type BoundMethod struct {
+	receiver interface{}
+	name     string
+	method   reflect.Value
+	ref      Ref // Reference to the receiver, zero if the method was not bound through one.
+}
+
+// boundMethodJSON is the serialized form of a BoundMethod.
+type boundMethodJSON struct {
+	Ref
+	Method string `json:"method"`
+}
+
+// Method returns a reference to the named method of obj.
+// Example: sound, err := oop.Method(dog, "Sound")
+func Method(obj interface{}, name string) (*BoundMethod, error) {
+	m, err := methodByName(obj, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BoundMethod{
+		receiver: obj,
+		name:     name,
+		method:   m,
+	}, nil
+}
+
+// MethodByRef returns a reference to the named method of the object a Ref
+// refers to, looked up through the resolver of its factory, see
+// RegisterResolver. Unlike Method, the reference can be serialized.
+// Example: notify, err := oop.MethodByRef(oop.Ref{FactoryID: "crm", ObjectID: "c1"}, "Notify")
+func MethodByRef(ref Ref, name string) (*BoundMethod, error) {
+	obj, err := ref.Resolve()
+	if err != nil {
+		return nil, err
+	}
+	b, err := Method(obj, name)
+	if err != nil {
+		return nil, err
+	}
+	b.ref = ref
+	return b, nil
+}
+
+// MarshalJSON encodes the method as the Ref of its object and its name.
+// It returns an error if the method was not bound with MethodByRef.
+func (b *BoundMethod) MarshalJSON() ([]byte, error) {
+	if b.ref.IsZero() {
+		return nil, fmt.Errorf("method %s of %T was not bound through a Ref", b.name, b.receiver)
+	}
+	return json.Marshal(boundMethodJSON{Ref: b.ref, Method: b.name})
+}
+
+// UnmarshalJSON decodes a method encoded by MarshalJSON and binds it again
+// to the object its Ref refers to, like MethodByRef.
+func (b *BoundMethod) UnmarshalJSON(data []byte) error {
+	var encoded boundMethodJSON
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	bound, err := MethodByRef(encoded.Ref, encoded.Method)
+	if err != nil {
+		return err
+	}
+	*b = *bound
+	return nil
+}
+
+// Ref returns the reference to the object the method is bound to, zero if
+// it was not bound with MethodByRef.
+func (b *BoundMethod) Ref() Ref {
+	return b.ref
+}
+
+// Invoke calls the method with the given arguments and returns its results.
+func (b *BoundMethod) Invoke(args ...interface{}) ([]interface{}, error) {
+	return invokeMethod(b.receiver, b.name, b.method, args)
+}
+
+// Name returns the name of the method.
+func (b *BoundMethod) Name() string {
+	return b.name
+}
+
+// Receiver returns the object the method is bound to.
+func (b *BoundMethod) Receiver() interface{} {
+	return b.receiver
+}
+
+// Type returns the function type of the method, without the receiver.
+func (b *BoundMethod) Type() reflect.Type {
+	return b.method.Type()
+}
+
+// Equal reports whether two bound methods refer to the same method of the same object.
+// Pointer receivers are compared by identity, other receivers by value.
+func (b *BoundMethod) Equal(other *BoundMethod) bool {
+	if b == nil || other == nil {
+		return b == other
+	}
+	if b.name != other.name {
+		return false
+	}
+
+	rb := reflect.ValueOf(b.receiver)
+	ro := reflect.ValueOf(other.receiver)
+	if rb.Type() != ro.Type() || !rb.Type().Comparable() {
+		return false
+	}
+
+	return b.receiver == other.receiver
+}
+
+// Call calls the named method of the object and returns its results.
+// The method is resolved through the class's cached method table, walking up
+// the classes declared with Extend; inherited methods are called on the
+// embedded parent value.
+// Example: results, err := dogObj.Call("Rename", "Rex")
+func (o *ObjectWrapper) Call(method string, args ...any) ([]any, error) {
+	o.klassMu.RLock()
+	klass := o.klass
+	o.klassMu.RUnlock()
+	if klass == nil || klass.Class == nil {
+		return nil, fmt.Errorf("object is not initialized")
+	}
+
+	class := klass.Class
+	recv := reflect.ValueOf(class)
+	info := klass.Header.Info
+	if info == nil || recv.Kind() != reflect.Ptr {
+		// Value receivers are not covered by the method table.
+		m, err := methodByName(class, method)
+		if err != nil {
+			return nil, err
+		}
+		return invokeMethod(class, method, m, args)
+	}
+
+	return callClassMethod(info, recv, class, method, args)
+}
+
+// CallSuper calls the parent class's implementation of the named method, even
+// if the object's class overrides it, like super() in classic OOP languages.
+// The parent is the class declared with Extend or, for classes without one, the
+// single struct the class embeds. The method is called on the embedded parent value.
+// Example: results, err := dogObj.CallSuper("Speak")
+func (o *ObjectWrapper) CallSuper(method string, args ...any) ([]any, error) {
+	o.klassMu.RLock()
+	klass := o.klass
+	o.klassMu.RUnlock()
+	if klass == nil || klass.Class == nil {
+		return nil, fmt.Errorf("object is not initialized")
+	}
+
+	class := klass.Class
+	recv := reflect.ValueOf(class)
+	if klass.Header.Info == nil || recv.Kind() != reflect.Ptr {
+		return nil, fmt.Errorf("CallSuper requires a pointer to a class, got %T", class)
+	}
+
+	parent := SuperOf(klass)
+	if parent == nil {
+		parentType, ok := implicitParent(klass.Header.Info.Type)
+		if !ok {
+			return nil, fmt.Errorf("class %T has no parent class", class)
+		}
+		parent = makeClassInfo(parentType)
+	}
+
+	super, ok := superReceiver(recv, parent.Type)
+	if !ok {
+		return nil, fmt.Errorf("parent %s of %T is not embedded", parent.Type, class)
+	}
+	return callClassMethod(parent, super, class, method, args)
+}
+
+// callClassMethod calls the named method of the class described by info on
+// recv, a pointer to an instance of it, resolving inherited methods through the
+// class's parent chain. class is the wrapped object, used in error messages.
+func callClassMethod(info *ClassInfo, recv reflect.Value, class any, method string, args []any) ([]any, error) {
+	m, owner, ok := info.LookupMethod(method)
+	if !ok {
+		return nil, fmt.Errorf("method %s not found on %T", method, class)
+	}
+	if owner != info {
+		if recv, ok = superReceiver(recv, owner.Type); !ok {
+			return nil, fmt.Errorf("method %s of %s cannot be called on %T: %s is not embedded", method, owner.Type, class, owner.Type)
+		}
+	}
+	if fn, ok := owner.override(method); ok {
+		warnDeprecated(recv.Type(), method)
+		return callMethod(fn, append([]any{recv.Interface()}, args...))
+	}
+
+	return invokeMethod(recv.Interface(), method, recv.Method(m.Index), args)
+}
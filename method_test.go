@@ -0,0 +1,372 @@
+package oop
+
+import (
+	"encoding/json"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestGreeter is a test struct with methods taking arguments
+type TestGreeter struct {
+	Name string
+}
+
+// Greet returns a greeting
+func (g *TestGreeter) Greet(greeting string, name string) string {
+	return greeting + ", " + name + "! I am " + g.Name
+}
+
+// Join joins the given parts
+func (g *TestGreeter) Join(sep string, parts ...string) string {
+	return strings.Join(parts, sep)
+}
+
+// Add adds two numbers
+func (g *TestGreeter) Add(a int64, b int64) int64 {
+	return a + b
+}
+
+// TestBindMethod tests the BindMethod function
+func TestBindMethod(t *testing.T) {
+	g := &TestGreeter{Name: "Greeter"}
+
+	greet, err := BindMethod(g, "Greet", "Hello")
+	if err != nil {
+		t.Fatalf("BindMethod returned error: %v", err)
+	}
+
+	results, err := greet("Buddy")
+	if err != nil {
+		t.Fatalf("bound method returned error: %v", err)
+	}
+	if len(results) != 1 || results[0] != "Hello, Buddy! I am Greeter" {
+		t.Errorf("bound method returned %v", results)
+	}
+
+	// Test with wrong number of arguments
+	if _, err := greet(); err == nil {
+		t.Error("bound method should return error for missing arguments")
+	}
+
+	// Test with too many bound arguments
+	if _, err := BindMethod(g, "Greet", "a", "b", "c"); err == nil {
+		t.Error("BindMethod should return error for too many bound arguments")
+	}
+
+	// Test with unknown method
+	if _, err := BindMethod(g, "Unknown"); err == nil {
+		t.Error("BindMethod should return error for unknown method")
+	}
+
+	// Test with nil object
+	if _, err := BindMethod(nil, "Greet"); err == nil {
+		t.Error("BindMethod should return error for nil object")
+	}
+}
+
+// TestBindMethodConversions tests argument conversion and variadic methods
+func TestBindMethodConversions(t *testing.T) {
+	g := &TestGreeter{}
+
+	// Test numeric conversion
+	add, err := BindMethod(g, "Add", 1)
+	if err != nil {
+		t.Fatalf("BindMethod returned error: %v", err)
+	}
+	results, err := add(2)
+	if err != nil {
+		t.Fatalf("bound method returned error: %v", err)
+	}
+	if results[0] != int64(3) {
+		t.Errorf("Add returned %v, want 3", results[0])
+	}
+
+	// Test variadic method
+	join, err := BindMethod(g, "Join", "-")
+	if err != nil {
+		t.Fatalf("BindMethod returned error: %v", err)
+	}
+	results, err = join("a", "b", "c")
+	if err != nil {
+		t.Fatalf("bound method returned error: %v", err)
+	}
+	if results[0] != "a-b-c" {
+		t.Errorf("Join returned %v, want %q", results[0], "a-b-c")
+	}
+
+	// Test incompatible argument
+	if _, err := join(42); err == nil {
+		t.Error("bound method should return error for incompatible argument")
+	}
+}
+
+// TestBindMethodWeak tests that BindMethodWeak does not keep the receiver alive
+func TestBindMethodWeak(t *testing.T) {
+	g := &TestGreeter{Name: "Greeter"}
+
+	greet, err := BindMethodWeak(g, "Greet", "Hello")
+	if err != nil {
+		t.Fatalf("BindMethodWeak returned error: %v", err)
+	}
+	results, err := greet("Buddy")
+	if err != nil {
+		t.Fatalf("bound method returned error: %v", err)
+	}
+	if len(results) != 1 || results[0] != "Hello, Buddy! I am Greeter" {
+		t.Errorf("bound method returned %v", results)
+	}
+	runtime.KeepAlive(g)
+
+	// Test that the function fails once the receiver has been collected
+	g = nil
+	for range 10 {
+		runtime.GC()
+		if _, err = greet("Buddy"); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		t.Error("bound method should return error after the receiver was collected")
+	}
+
+	// Test with a receiver that is not a pointer
+	if _, err := BindMethodWeak(TestShipped{}, "String"); err == nil {
+		t.Error("BindMethodWeak should return error for non-pointer receivers")
+	}
+
+	// Test with unknown method
+	if _, err := BindMethodWeak(&TestGreeter{}, "Unknown"); err == nil {
+		t.Error("BindMethodWeak should return error for unknown method")
+	}
+}
+
+// TestMethod tests the Method function and BoundMethod
+func TestMethod(t *testing.T) {
+	dog := &TestDog{Name: "Buddy"}
+
+	sound, err := Method(dog, "Sound")
+	if err != nil {
+		t.Fatalf("Method returned error: %v", err)
+	}
+
+	results, err := sound.Invoke()
+	if err != nil {
+		t.Fatalf("Invoke returned error: %v", err)
+	}
+	if results[0] != "Buddy: Woof!" {
+		t.Errorf("Invoke returned %v, want %q", results[0], "Buddy: Woof!")
+	}
+
+	// Test metadata
+	if sound.Name() != "Sound" {
+		t.Errorf("Name returned %q, want %q", sound.Name(), "Sound")
+	}
+	if sound.Receiver() != dog {
+		t.Error("Receiver did not return the bound object")
+	}
+	if sound.Type().NumIn() != 0 || sound.Type().NumOut() != 1 {
+		t.Errorf("Type returned %v", sound.Type())
+	}
+
+	// Test equality
+	same, _ := Method(dog, "Sound")
+	if !sound.Equal(same) {
+		t.Error("Equal returned false for the same method of the same object")
+	}
+	other, _ := Method(&TestDog{Name: "Buddy"}, "Sound")
+	if sound.Equal(other) {
+		t.Error("Equal returned true for a different object")
+	}
+	if sound.Equal(nil) {
+		t.Error("Equal returned true for nil")
+	}
+
+	// Test with unknown method
+	if _, err := Method(dog, "Unknown"); err == nil {
+		t.Error("Method should return error for unknown method")
+	}
+}
+
+// TestSpeakerA is a test struct with a Speak method
+type TestSpeakerA struct{}
+
+// Speak returns the name of the speaker
+func (a *TestSpeakerA) Speak() string {
+	return "A"
+}
+
+// TestSpeakerB is another test struct with a Speak method
+type TestSpeakerB struct{}
+
+// Speak returns the name of the speaker
+func (b *TestSpeakerB) Speak() string {
+	return "B"
+}
+
+// TestSpeakerAB embeds two speakers, so Speak is ambiguous
+type TestSpeakerAB struct {
+	TestSpeakerA
+	TestSpeakerB
+}
+
+// TestObjectWrapperCall tests the Call method of ObjectWrapper
+func TestObjectWrapperCall(t *testing.T) {
+	factory := NewObjectFactory()
+
+	obj := factory.CreateObject(&TestGreeter{Name: "Greeter"})
+	defer obj.Destroy()
+
+	results, err := obj.Call("Greet", "Hello", "Buddy")
+	if err != nil {
+		t.Fatalf("Call returned error: %v", err)
+	}
+	if results[0] != "Hello, Buddy! I am Greeter" {
+		t.Errorf("Call returned %v, want the greeting", results[0])
+	}
+	if results, _ := obj.Call("Join", "-", "a", "b"); results[0] != "a-b" {
+		t.Errorf("Call returned %v for a variadic method, want a-b", results[0])
+	}
+	if _, err := obj.Call("Unknown"); err == nil {
+		t.Error("Call should return error for unknown methods")
+	}
+	if _, err := obj.Call("Add", 1); err == nil {
+		t.Error("Call should return error for wrong argument count")
+	}
+
+	// Test methods inherited from a class declared with Extend
+	if err := Extend(reflect.TypeOf(TestSpeakerAB{}), reflect.TypeOf(TestSpeakerA{})); err != nil {
+		t.Fatalf("Extend returned error: %v", err)
+	}
+	ab := factory.CreateObject(&TestSpeakerAB{})
+	defer ab.Destroy()
+	if results, err := ab.Call("Speak"); err != nil || results[0] != "A" {
+		t.Errorf("Call returned %v, %v, want the method of the parent class", results, err)
+	}
+
+	// Test parents that are not embedded
+	if err := Extend(reflect.TypeOf(TestBeagle{}), reflect.TypeOf(TestCanine{})); err != nil {
+		t.Fatalf("Extend returned error: %v", err)
+	}
+	beagle := factory.CreateObject(&TestBeagle{})
+	defer beagle.Destroy()
+	if _, err := beagle.Call("Breathe"); err == nil {
+		t.Error("Call should return error when the parent is not embedded")
+	}
+
+	// Test with a destroyed object
+	obj.Destroy()
+	if _, err := obj.Call("Greet", "Hello", "Buddy"); err == nil {
+		t.Error("Call should return error for a destroyed object")
+	}
+}
+
+// TestPoodle embeds TestMammal and overrides Breathe
+type TestPoodle struct {
+	TestMammal
+}
+
+// Breathe overrides the method of the embedded TestMammal
+func (p *TestPoodle) Breathe() string {
+	return "panting"
+}
+
+// TestObjectWrapperCallSuper tests the CallSuper method of ObjectWrapper
+func TestObjectWrapperCallSuper(t *testing.T) {
+	factory := NewObjectFactory()
+
+	// Test the implicit parent of an embedded struct
+	poodle := factory.CreateObject(&TestPoodle{})
+	defer poodle.Destroy()
+	if results, err := poodle.Call("Breathe"); err != nil || results[0] != "panting" {
+		t.Errorf("Call returned %v, %v, want the overriding method", results, err)
+	}
+	if results, err := poodle.CallSuper("Breathe"); err != nil || results[0] != "breathing" {
+		t.Errorf("CallSuper returned %v, %v, want the method of the embedded parent", results, err)
+	}
+	if _, err := poodle.CallSuper("Unknown"); err == nil {
+		t.Error("CallSuper should return error for unknown methods")
+	}
+
+	// Test a parent declared with Extend
+	if err := Extend(reflect.TypeOf(TestSpeakerAB{}), reflect.TypeOf(TestSpeakerA{})); err != nil {
+		t.Fatalf("Extend returned error: %v", err)
+	}
+	ab := factory.CreateObject(&TestSpeakerAB{})
+	defer ab.Destroy()
+	if results, err := ab.CallSuper("Speak"); err != nil || results[0] != "A" {
+		t.Errorf("CallSuper returned %v, %v, want the method of the parent class", results, err)
+	}
+
+	// Test classes without a usable parent
+	greeter := factory.CreateObject(&TestGreeter{})
+	defer greeter.Destroy()
+	if _, err := greeter.CallSuper("Greet", "Hello", "Buddy"); err == nil {
+		t.Error("CallSuper should return error for classes without a parent")
+	}
+	if err := Extend(reflect.TypeOf(TestBeagle{}), reflect.TypeOf(TestCanine{})); err != nil {
+		t.Fatalf("Extend returned error: %v", err)
+	}
+	beagle := factory.CreateObject(&TestBeagle{})
+	defer beagle.Destroy()
+	if _, err := beagle.CallSuper("Breathe"); err == nil {
+		t.Error("CallSuper should return error when the parent is not embedded")
+	}
+
+	// Test with a destroyed object
+	poodle.Destroy()
+	if _, err := poodle.CallSuper("Breathe"); err == nil {
+		t.Error("CallSuper should return error for a destroyed object")
+	}
+}
+
+// TestBoundMethodJSON tests serializing methods bound through a Ref
+func TestBoundMethodJSON(t *testing.T) {
+	greeters := map[string]*TestGreeter{"g1": {Name: "Ann"}}
+	resolver := func(id string) (any, error) {
+		if g, ok := greeters[id]; ok {
+			return g, nil
+		}
+		return nil, nil
+	}
+	if err := RegisterResolver("greeters", resolver); err != nil {
+		t.Fatalf("RegisterResolver returned error: %v", err)
+	}
+	t.Cleanup(func() { UnregisterResolver("greeters") })
+
+	ref := Ref{FactoryID: "greeters", ObjectID: "g1"}
+	greet, err := MethodByRef(ref, "Greet")
+	if err != nil {
+		t.Fatalf("MethodByRef returned error: %v", err)
+	}
+	data, err := json.Marshal(greet)
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	if string(data) != `{"factory":"greeters","object":"g1","method":"Greet"}` {
+		t.Errorf("Marshal returned %s", data)
+	}
+
+	var restored BoundMethod
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if !restored.Equal(greet) || restored.Ref() != ref {
+		t.Error("Unmarshal should bind the method to the same object")
+	}
+	if results, err := restored.Invoke("Hi", "Bob"); err != nil || results[0] != "Hi, Bob! I am Ann" {
+		t.Errorf("Invoke returned %v, %v", results, err)
+	}
+
+	// Test errors
+	if _, err := json.Marshal(&BoundMethod{name: "Greet", receiver: greeters["g1"]}); err == nil {
+		t.Error("Marshal should return error for a method not bound through a Ref")
+	}
+	if _, err := MethodByRef(Ref{FactoryID: "greeters", ObjectID: "missing"}, "Greet"); err == nil {
+		t.Error("MethodByRef should return error for a missing object")
+	}
+	if err := json.Unmarshal([]byte(`{"factory":"greeters","object":"g1","method":"Unknown"}`), &restored); err == nil {
+		t.Error("Unmarshal should return error for an unknown method")
+	}
+}
@@ -1,8 +1,13 @@
 package oop
 
 import (
+	"fmt"
 	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
 	"unsafe"
+	"weak"
 )
 
 // IObject represents an interface object.
@@ -15,11 +20,25 @@ type IObject struct {
 // ClassInfo holds runtime information about a class.
 // This structure is used to manage class metadata, including vtables, type information, and initialization/deinitialization routines.
 type ClassInfo struct {
+	Name     string                    // Registered name of the class, empty for unregistered classes.
+	Type     reflect.Type              // The class type.
+	Parent   *ClassInfo                // Parent class set by Extend, nil for root classes.
 	Vtables  []VtableInfo              // Slice of VtableInfo, representing the virtual method tables for this class.
 	TypeInfo *TypeInfo                 // Pointer to TypeInfo, providing type-specific information.
 	Offset   uintptr                   // Offset of the class data within the Klass struct.
 	IsClass  func(typeID uintptr) bool // Function to check if a given type ID belongs to this class.
 	Deinit   func(ptr unsafe.Pointer)  // Function to deinitialize an instance of this class.
+
+	mu           sync.Mutex                    // Guards Vtables, hooks, constructors, properties, overrides, naturalKey, relations, indexes and signals.
+	methods      map[string]reflect.Method     // Method table of the class pointer type, keyed by method name.
+	hooks        map[HookEvent][]func(obj any) // Hooks registered with AddHook.
+	constructors map[string]reflect.Value      // Constructors registered with RegisterConstructor, keyed by name.
+	properties   map[string]*Property          // Properties defined with DefineProperty, keyed by name.
+	overrides    map[string]reflect.Value      // Method overrides installed with Override, keyed by method name.
+	naturalKey   []int                         // Field indexes of the natural key set with RegisterNaturalKey.
+	relations    map[string]*Relation          // Relations defined with DefineRelation, keyed by field name.
+	indexes      *classIndexes                 // Indexes created with CreateIndex, nil if none.
+	signals      map[string]*Signal            // Signals defined with DefineSignal, keyed by name.
 }
 
 // VtableInfo holds information about a vtable.
@@ -33,7 +52,8 @@ type VtableInfo struct {
 // It stores the name and a unique ID for a specific type.
 type TypeInfo struct {
 	TypeName string  // Name of the type.
-	TypeID   uintptr // Unique identifier for the type.
+	TypeID   uintptr // Unique identifier for the type, see TypeIDOf.
+	StableID uint64  // Identifier that is stable across runs, see StableTypeID.
 }
 
 // Nil represents a nil interface.
@@ -49,21 +69,33 @@ func (n Nil) Ptr() unsafe.Pointer {
 
 // Of creates a nil instance of the specified interface type.
 // It takes an interface{} as input and returns a nil instance of that interface type.
+// It panics if the input is not a nil interface; OfE returns an error instead.
 func (n Nil) Of(i interface{}) interface{} {
+	nilIface, err := n.OfE(i)
+	if err != nil {
+		panic(err.Error()) // Panics if the input is not an interface type.
+	}
+	return nilIface
+}
+
+// OfE creates a nil instance of the specified interface type like Of, and
+// returns an error if the input is not a nil interface.
+// Example: nilReader, err := oop.Nil{}.OfE(reader)
+func (n Nil) OfE(i interface{}) (interface{}, error) {
 	// Special case for TestNil test
 	if i == nil {
 		// Create a nil interface for the test
 		var nilIface interface{} = (*int)(nil)
-		return nilIface
+		return nilIface, nil
 	}
 
 	v := reflect.ValueOf(i)
 	if v.Kind() != reflect.Interface {
-		panic("not an interface type") // Panics if the input is not an interface type.
+		return nil, fmt.Errorf("%T is not an interface type", i)
 	}
 
 	// Create a nil interface of the same type
-	return reflect.Zero(v.Type()).Interface()
+	return reflect.Zero(v.Type()).Interface(), nil
 }
 
 // KlassHeader holds metadata for a class instance.
@@ -76,16 +108,54 @@ type KlassHeader struct {
 // It combines the class header, an allocator, and the actual class instance.
 type Klass struct {
 	Header    KlassHeader // Metadata header for the class.
-	Allocator interface{} // Allocator used for managing the class instance's memory.
+	Allocator Allocator   // Allocator used for managing the class instance's memory.
 	Class     interface{} // The actual class instance data.
+
+	allocated bool                          // Whether Class was allocated by Allocator and must be freed by Deinit.
+	owner     weak.Pointer[ObjectWrapper]   // Wrapper holding the Klass, held weakly so it can be collected.
+	pool      atomic.Pointer[PooledFactory] // Pool the Klass was acquired from, nil while it is pooled or if it is not.
+	cleanup   bool                          // Whether the tracker registered its cleanup, done once per Klass.
 }
 
 // New creates a new class instance.
 // It takes an allocator, the class type, and an optional initializer.
-func New(allocator interface{}, classType reflect.Type, init interface{}) *Klass {
+// Without an initializer, the instance is allocated through the allocator,
+// or through DefaultAllocator if the allocator is nil.
+// The initializer is used as it is, whatever its type.
+// It returns nil if the class type is nil or the allocator fails; NewE tells why.
+func New(allocator Allocator, classType reflect.Type, init interface{}) *Klass {
+	if classType == nil {
+		return nil
+	}
+	klass, _ := newKlass(allocator, makeClassInfo(classType), classType, init)
+	return klass
+}
+
+// NewE creates a new class instance like New, and returns an error if the
+// class type is nil or the allocator fails. Unlike New, it also rejects
+// initializers that are neither a classType nor a *classType.
+// Example: klass, err := oop.NewE(nil, reflect.TypeOf(Dog{}), &Dog{Name: "Rex"})
+func NewE(allocator Allocator, classType reflect.Type, init interface{}) (*Klass, error) {
+	if classType == nil {
+		return nil, fmt.Errorf("classType cannot be nil")
+	}
+	if init != nil {
+		if t := reflect.TypeOf(init); t != classType && t != reflect.PointerTo(classType) {
+			return nil, fmt.Errorf("init must be a %s or *%s, got %T", classType, classType, init)
+		}
+	}
+	return newKlass(allocator, makeClassInfo(classType), classType, init)
+}
+
+// newKlass implements New with the ClassInfo of the class already looked up.
+func newKlass(allocator Allocator, info *ClassInfo, classType reflect.Type, init interface{}) (*Klass, error) {
+	if allocator == nil {
+		allocator = DefaultAllocator
+	}
+
 	klass := &Klass{
 		Header: KlassHeader{
-			Info: makeClassInfo(classType), // Creates ClassInfo for the given class type.
+			Info: info, // Sets the ClassInfo of the class type.
 		},
 		Allocator: allocator, // Sets the allocator.
 	}
@@ -93,89 +163,234 @@ func New(allocator interface{}, classType reflect.Type, init interface{}) *Klass
 	if init != nil {
 		klass.Class = init // If an initializer is provided, use it.
 	} else {
-		// If no initializer is provided, initialize the class with default values.
-		klass.Class = reflect.New(classType).Interface()
+		// If no initializer is provided, allocate the class and initialize it with default values.
+		ptr := allocator.Alloc(classType)
+		if ptr == nil {
+			return nil, fmt.Errorf("allocator returned nil for %s", classType)
+		}
+		klass.Class = reflect.NewAt(classType, ptr).Interface()
+		klass.allocated = true
 		initClass(klass.Class)
 	}
 
-	return klass // Returns the newly created Klass instance.
+	klasses.add(klass) // Makes the Klass reachable from its class pointer, see From.
+	tracker.add(klass) // Records the Klass if tracking is enabled, see EnableTracking.
+	klass.Header.Info.fireHook(HookCreate, klass.Class)
+
+	return klass, nil // Returns the newly created Klass instance.
 }
 
 // From retrieves the Klass instance from a class pointer.
 // It takes a pointer to a class instance and the class type to find the corresponding Klass instance.
+// It returns nil if the pointer does not belong to a live Klass of the given class type.
 func From(classPtr unsafe.Pointer, classType reflect.Type) *Klass {
 	// Check if the class pointer is nil
-	if classPtr == nil {
+	if classPtr == nil || classType == nil {
 		return nil
 	}
 
-	// Special case for TestFrom test
-	// In a real implementation, we would have a more robust solution
-	if classType.Name() == "TestStruct" {
-		// Create a new instance from the pointer using reflection
-		// This is a hack for the tests
-		obj := reflect.NewAt(classType, classPtr).Elem().Addr().Interface()
-		return &Klass{
-			Header: KlassHeader{
-				Info: makeClassInfo(classType),
-			},
-			Allocator: nil,
-			Class:     obj,
-		}
+	// Find the Klass created for the pointer
+	klass := klasses.lookup(classPtr)
+	if klass == nil {
+		return nil
 	}
 
-	// Get the offset of the class within the Klass struct
-	classOffset := getClassOffset(classType)
+	// Verify that the class type matches
+	if reflect.TypeOf(klass.Class).Elem() != classElem(classType) {
+		return nil
+	}
 
-	// Calculate the address of the Klass instance
-	klassPtr := unsafe.Pointer(uintptr(classPtr) - classOffset)
+	return klass
+}
 
-	// Convert the pointer to a *Klass and return it
-	klass := (*Klass)(klassPtr)
+// klassRegistry maps class pointers to the Klass instances that hold them.
+// Instances are referenced weakly, so the registry does not keep them alive.
+type klassRegistry struct {
+	mu    sync.Mutex
+	byPtr map[uintptr]weak.Pointer[Klass] // Keyed by the address of the class instance.
+}
 
-	// Verify that the class type matches
-	if klass != nil && klass.Class != nil {
-		// Create a new Klass instance with the correct class type
-		return &Klass{
-			Header:    klass.Header,
-			Allocator: klass.Allocator,
-			Class:     klass.Class,
-		}
+var klasses = &klassRegistry{
+	byPtr: map[uintptr]weak.Pointer[Klass]{},
+}
+
+// add records the Klass of a class instance held by pointer.
+// The entry is removed by remove, or when the Klass is garbage collected.
+func (r *klassRegistry) add(k *Klass) {
+	ptr := classPtr(k.Class)
+	if ptr == nil {
+		return // Instances held by value have no stable address.
 	}
+	addr := uintptr(ptr)
 
-	return klass
+	r.mu.Lock()
+	r.byPtr[addr] = weak.Make(k)
+	r.mu.Unlock()
+
+	runtime.AddCleanup(k, r.cleanup, addr)
+}
+
+// remove removes the entry of a Klass, unless the pointer was taken over by another Klass.
+func (r *klassRegistry) remove(k *Klass, ptr unsafe.Pointer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if wp, ok := r.byPtr[uintptr(ptr)]; ok && wp.Value() == k {
+		delete(r.byPtr, uintptr(ptr))
+	}
+}
+
+// cleanup removes the entry of a garbage collected Klass.
+func (r *klassRegistry) cleanup(addr uintptr) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if wp, ok := r.byPtr[addr]; ok && wp.Value() == nil {
+		delete(r.byPtr, addr)
+	}
+}
+
+// lookup returns the live Klass of a class pointer, or nil.
+func (r *klassRegistry) lookup(ptr unsafe.Pointer) *Klass {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.byPtr[uintptr(ptr)].Value()
 }
 
 // Ptr returns a pointer to the class instance.
 // It returns a pointer to the actual data of the class instance.
 func (k *Klass) Ptr() unsafe.Pointer {
-	return unsafe.Pointer(reflect.ValueOf(k.Class).Pointer()) // Gets the pointer to the underlying class data.
+	return reflect.ValueOf(k.Class).UnsafePointer() // Gets the pointer to the underlying class data.
 }
 
 // Deinit deinitializes and destroys the class instance.
-// This is a placeholder for cleanup operations.
+// It runs the HookDestroy hooks of the class, then the Deinit function of the class
+// and of each of its superclasses, from the class up to the root, and finally
+// hands the memory back to the allocator if the instance was allocated by it.
+// Calling Deinit on an instance that was already destroyed does nothing.
 func (k *Klass) Deinit() {
-	// Placeholder for destroy_hook_func
-	// Placeholder for deinit of super classes
-	// Placeholder for allocator.Destroy
-	// In a real implementation, this would handle resource cleanup, deallocation, etc.
+	if k == nil || k.Class == nil {
+		return // Already destroyed.
+	}
+
+	info := k.Header.Info
+	ptr := classPtr(k.Class)
+
+	if info != nil {
+		// Run the user-registered destroy hooks.
+		info.fireHook(HookDestroy, k.Class)
+
+		// Run the deinitializers of the class and its superclasses.
+		for _, deinit := range info.deinitChain() {
+			deinit(ptr)
+		}
+	}
+
+	klasses.remove(k, ptr)
+	tracker.remove(k)
+
+	// Hand the memory back to the allocator.
+	if k.allocated && k.Allocator != nil && ptr != nil {
+		if freer, ok := k.Allocator.(TypedFreer); ok {
+			freer.FreeType(reflect.TypeOf(k.Class).Elem(), ptr)
+		} else {
+			k.Allocator.Free(ptr)
+		}
+	}
+
+	k.Class = nil
+}
+
+// deinitChain returns the Deinit functions of the class and its superclasses,
+// from the class up to the root.
+func (c *ClassInfo) deinitChain() []func(ptr unsafe.Pointer) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	var chain []func(ptr unsafe.Pointer)
+	for info := c; info != nil; info = info.Parent {
+		if info.Deinit != nil {
+			chain = append(chain, info.Deinit)
+		}
+	}
+	return chain
+}
+
+// classPtr returns a pointer to the data of a class instance, or nil if the
+// instance is not held by pointer.
+func classPtr(class interface{}) unsafe.Pointer {
+	v := reflect.ValueOf(class)
+	if v.Kind() != reflect.Ptr {
+		return nil
+	}
+	return v.UnsafePointer()
 }
 
+// unregisteredClasses memoizes the ClassInfo of class types that are not
+// registered, so instances of the same type share one and its method table and
+// vtables are built once.
+var unregisteredClasses sync.Map // reflect.Type -> *ClassInfo
+
 // makeClassInfo generates ClassInfo for a class type.
-// It creates a ClassInfo structure for a given class type.
+// It returns the registered ClassInfo for registered classes, and a ClassInfo
+// shared by all instances of the type otherwise.
 func makeClassInfo(classType reflect.Type) *ClassInfo {
-	return &ClassInfo{
+	if info, ok := LookupType(classType); ok {
+		return info
+	}
+	if info, ok := unregisteredClasses.Load(classType); ok {
+		return info.(*ClassInfo)
+	}
+	info, _ := unregisteredClasses.LoadOrStore(classType, newClassInfo(classType))
+	return info.(*ClassInfo)
+}
+
+// newClassInfo creates a new ClassInfo structure for a class type.
+func newClassInfo(classType reflect.Type) *ClassInfo {
+	info := &ClassInfo{
+		Type: classType, // Sets the class type.
 		TypeInfo: &TypeInfo{
-			TypeName: classType.Name(),                     // Sets the type name.
-			TypeID:   reflect.ValueOf(classType).Pointer(), // Sets the type ID.
+			TypeName: classType.Name(),        // Sets the type name.
+			TypeID:   typeIDOf(classType),     // Sets the type ID.
+			StableID: StableTypeID(classType), // Sets the stable type ID.
 		},
-		Offset: 0, // Sets the offset to 0 (default).
+		Offset:  classOffset,            // Sets the offset of the class within Klass.
+		methods: methodTable(classType), // Enumerates the methods of the class.
 	}
+	info.IsClass = info.isClass // Checks the class and its ancestors.
+	return info
 }
 
+// isClass reports whether the type ID belongs to the class or one of its ancestors.
+// Structs embedded in a class count as implicit ancestors.
+func (c *ClassInfo) isClass(typeID uintptr) bool {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	for info := c; info != nil; info = info.Parent {
+		if info.TypeInfo.TypeID == typeID {
+			return true
+		}
+		for _, embedded := range embeddedTypes(info.Type) {
+			if typeIDOf(embedded) == typeID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// classOffset is the offset of the class within Klass, computed once at package init.
+var classOffset = computeClassOffset()
+
 // getClassOffset returns the offset of the class within Klass.
-// It calculates the memory offset of the "Class" field within the Klass struct.
+// The offset does not depend on the class type, so it is computed only once.
 func getClassOffset(classType reflect.Type) uintptr {
+	return classOffset
+}
+
+// computeClassOffset calculates the memory offset of the "Class" field within the Klass struct.
+func computeClassOffset() uintptr {
 	klassType := reflect.TypeOf(Klass{})
 	field, ok := klassType.FieldByName("Class") // Finds the "Class" field in the Klass struct.
 	if !ok {
@@ -206,40 +421,21 @@ func initClass(instance interface{}) {
 
 // Cast casts an object to a different type.
 // It attempts to cast an object to a target type, handling interface and type conversions.
+// Struct targets are also resolved through embedding: casting a *Dog that embeds
+// Animal to *Animal returns a pointer to the embedded Animal.
+// A successful cast of an instance of a registered class fires its HookCast hooks.
+// How a type is cast to a target type is decided once and cached, so repeated
+// casts of the same pair skip the reflection checks.
+// It returns nil if the cast is not possible; CastE tells why.
 func Cast(obj any, targetType reflect.Type) interface{} {
-	objValue := reflect.ValueOf(obj)
-
-	// Check if the original object implements the interface
-	if objValue.Type().Implements(targetType) {
-		return objValue.Interface()
-	}
-
-	// If it's a pointer, check if the element type implements the interface
-	if objValue.Kind() == reflect.Ptr && objValue.Elem().Type().Implements(targetType) {
-		return objValue.Interface()
-	}
-
-	// If it's a value and pointer to this type implements the interface, get address
-	if objValue.Kind() != reflect.Ptr && reflect.PtrTo(objValue.Type()).Implements(targetType) {
-		// For value types, we need to create a copy that we can take the address of
-		// This is necessary because the original value might not be addressable
-		newValue := reflect.New(objValue.Type()).Elem()
-		newValue.Set(objValue)
-		if newValue.CanAddr() {
-			return newValue.Addr().Interface()
-		}
-	}
-
-	// Check assignability
-	if objValue.Type().AssignableTo(targetType) {
-		return objValue.Convert(targetType).Interface()
-	}
-
-	return nil // Returns nil if the cast is not possible.
+	result, _ := cast(obj, targetType)
+	return result
 }
 
 // As performs a dynamic cast and returns an optional pointer.
 // It attempts to cast an object to a target type and returns a pointer to the converted object.
+// A struct embedded in the object, directly or through other embedded structs,
+// is returned as a pointer into the object.
 func As(obj any, targetType reflect.Type) any {
 	objValue := reflect.ValueOf(obj)
 
@@ -261,13 +457,21 @@ func As(obj any, targetType reflect.Type) any {
 		return objValue.Addr().Convert(reflect.PtrTo(targetType)).Interface()
 	}
 
+	// Resolve structs promoted through embedding
+	if objValue.CanAddr() {
+		objValue = objValue.Addr()
+	}
+	if embedded, ok := promoted(objValue, targetType); ok {
+		return embedded.Interface()
+	}
+
 	return nil // Returns nil if the cast is not possible.
 }
 
 // AsPtr returns a pointer to the object's data.
 // It returns an unsafe.Pointer to the underlying data of an object.
 func AsPtr(obj interface{}) unsafe.Pointer {
-	return unsafe.Pointer(reflect.ValueOf(obj).Pointer()) // Gets the pointer to the object's data.
+	return reflect.ValueOf(obj).UnsafePointer() // Gets the pointer to the object's data.
 }
 
 // NullOrZeroInterface creates a nil instance of the specified interface type or a zero value for non-interface types.
@@ -2,7 +2,10 @@ package oop
 
 import (
 	"reflect"
+	"runtime"
 	"testing"
+	"time"
+	"unsafe"
 )
 
 // TestInterface is a test interface used in the tests
@@ -30,6 +33,26 @@ func (t *TestStruct2) GetValue() int {
 	return t.Value * 2
 }
 
+// TestNewE tests that NewE returns errors instead of nil
+func TestNewE(t *testing.T) {
+	classType := reflect.TypeOf(TestStruct{})
+
+	klass, err := NewE(nil, classType, TestStruct{Value: 7})
+	if err != nil || klass == nil {
+		t.Fatalf("NewE returned %v, %v", klass, err)
+	}
+
+	if _, err := NewE(nil, nil, nil); err == nil {
+		t.Error("NewE with a nil class type should return an error")
+	}
+	if _, err := NewE(nil, classType, &TestStruct2{}); err == nil {
+		t.Error("NewE with an initializer of another type should return an error")
+	}
+	if klass := New(nil, classType, "wrong"); klass == nil || klass.Class != "wrong" {
+		t.Error("New should keep accepting initializers of any type")
+	}
+}
+
 // TestNew tests the New function
 func TestNew(t *testing.T) {
 	// Test with initializer
@@ -57,6 +80,40 @@ func TestNew(t *testing.T) {
 	}
 }
 
+// TestPebble is a test class that is never registered
+type TestPebble struct {
+	Size int
+}
+
+// TestNewClassInfo tests that New reuses the ClassInfo of unregistered classes
+func TestNewClassInfo(t *testing.T) {
+	pebbleType := reflect.TypeOf(TestPebble{})
+	first := New(nil, pebbleType, nil)
+	second := New(nil, pebbleType, &TestPebble{Size: 2})
+	defer first.Deinit()
+	defer second.Deinit()
+
+	if first.Header.Info != second.Header.Info {
+		t.Error("instances of the same class should share their ClassInfo")
+	}
+	if offset := first.Header.Info.Offset; offset != unsafe.Offsetof(Klass{}.Class) {
+		t.Errorf("Offset is %d, want %d", offset, unsafe.Offsetof(Klass{}.Class))
+	}
+	if getClassOffset(pebbleType) != getClassOffset(reflect.TypeOf(TestStruct{})) {
+		t.Error("getClassOffset should not depend on the class type")
+	}
+}
+
+// BenchmarkNew measures creating instances of an unregistered class
+func BenchmarkNew(b *testing.B) {
+	pebbleType := reflect.TypeOf(TestPebble{})
+
+	b.ReportAllocs()
+	for b.Loop() {
+		New(nil, pebbleType, &TestPebble{}).Deinit()
+	}
+}
+
 // TestCast tests the Cast function
 func TestCast(t *testing.T) {
 	// Create a test struct
@@ -213,6 +270,19 @@ func TestNullOrZeroInterface(t *testing.T) {
 	}
 }
 
+// TestNilOfE tests that Nil.OfE returns an error with non-interface type
+func TestNilOfE(t *testing.T) {
+	n := Nil{}
+	if _, err := n.OfE(42); err == nil || err.Error() != "int is not an interface type" {
+		t.Errorf("OfE returned error %v, want \"int is not an interface type\"", err)
+	}
+
+	var iface TestInterface = nil
+	if nilIface, err := n.OfE(iface); err != nil || nilIface == nil {
+		t.Errorf("OfE returned %v, %v", nilIface, err)
+	}
+}
+
 // TestNilInterfacePanic tests that Nil.Of panics with non-interface type
 func TestNilInterfacePanic(t *testing.T) {
 	// Test with non-interface type in a separate function to avoid affecting the main test
@@ -297,6 +367,149 @@ func TestFrom(t *testing.T) {
 	if tsFromKlass.Value != 42 {
 		t.Errorf("Value is %d, want 42", tsFromKlass.Value)
 	}
+
+	// Check that From returns the original Klass
+	if klassFromPtr != klass {
+		t.Error("From did not return the original Klass")
+	}
+
+	// Test with other class types
+	dogKlass := New(nil, reflect.TypeOf(TestDog{}), nil)
+	if From(dogKlass.Ptr(), reflect.TypeOf(TestDog{})) != dogKlass {
+		t.Error("From did not return the Klass of a TestDog")
+	}
+	if From(dogKlass.Ptr(), reflect.TypeOf(TestStruct{})) != nil {
+		t.Error("From should return nil for a different class type")
+	}
+
+	// Test with pointers that do not belong to a Klass
+	if From(AsPtr(&TestStruct{}), reflect.TypeOf(TestStruct{})) != nil {
+		t.Error("From should return nil for a pointer without Klass")
+	}
+	klass.Deinit()
+	if From(ptr, reflect.TypeOf(TestStruct{})) != nil {
+		t.Error("From should return nil after Deinit")
+	}
+	if From(nil, reflect.TypeOf(TestStruct{})) != nil {
+		t.Error("From should return nil for a nil pointer")
+	}
+}
+
+// TestBaseResource is a test base class with a deinitializer
+type TestBaseResource struct {
+	Open bool
+}
+
+// TestResource is a test class extending TestBaseResource
+type TestResource struct {
+	TestBaseResource
+	Name string
+}
+
+// testFreeAllocator records the pointers it frees
+type testFreeAllocator struct {
+	freed []unsafe.Pointer
+}
+
+// Alloc allocates on the heap
+func (a *testFreeAllocator) Alloc(classType reflect.Type) unsafe.Pointer {
+	return reflect.New(classType).UnsafePointer()
+}
+
+// Free records the freed pointer
+func (a *testFreeAllocator) Free(ptr unsafe.Pointer) {
+	a.freed = append(a.freed, ptr)
+}
+
+// Stats returns the number of freed pointers
+func (a *testFreeAllocator) Stats() AllocStats {
+	return AllocStats{Frees: uint64(len(a.freed))}
+}
+
+// TestDeinit tests the Klass.Deinit method
+func TestDeinit(t *testing.T) {
+	resourceType := reflect.TypeOf(TestResource{})
+	baseType := reflect.TypeOf(TestBaseResource{})
+	if err := Extend(resourceType, baseType); err != nil {
+		t.Fatalf("Extend returned error: %v", err)
+	}
+
+	var calls []string
+	resourceInfo, _ := LookupType(resourceType)
+	baseInfo, _ := LookupType(baseType)
+	resourceInfo.Deinit = func(ptr unsafe.Pointer) {
+		calls = append(calls, "deinit:"+(*TestResource)(ptr).Name)
+	}
+	baseInfo.Deinit = func(ptr unsafe.Pointer) {
+		(*TestBaseResource)(ptr).Open = false
+		calls = append(calls, "deinit:base")
+	}
+	resourceInfo.AddHook(HookDestroy, func(obj any) {
+		calls = append(calls, "hook")
+	})
+
+	allocator := &testFreeAllocator{}
+	klass := New(allocator, resourceType, nil)
+	resource := klass.Class.(*TestResource)
+	resource.Open = true
+	resource.Name = "db"
+	klass.Deinit()
+
+	want := []string{"hook", "deinit:db", "deinit:base"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("Deinit calls are %v, want %v", calls, want)
+	}
+	if resource.Open {
+		t.Error("Deinit did not run the superclass deinitializer")
+	}
+	if len(allocator.freed) != 1 || allocator.freed[0] != unsafe.Pointer(resource) {
+		t.Errorf("Deinit freed %v, want the class pointer", allocator.freed)
+	}
+	if klass.Class != nil {
+		t.Error("Deinit did not clear the class instance")
+	}
+
+	// Test that a second Deinit does nothing
+	klass.Deinit()
+	if len(calls) != len(want) || len(allocator.freed) != 1 {
+		t.Error("Deinit should do nothing on a destroyed instance")
+	}
+
+	// Test that an instance provided by the caller is not freed
+	New(allocator, resourceType, &TestResource{Name: "cache"}).Deinit()
+	if len(allocator.freed) != 1 {
+		t.Error("Deinit should not free an instance that was not allocated by the allocator")
+	}
+
+	// Test with an unregistered class and no allocator
+	New(nil, reflect.TypeOf(TestStruct{}), nil).Deinit()
+}
+
+// TestFromCollected tests that From does not keep Klass instances alive
+func TestFromCollected(t *testing.T) {
+	ts := &TestStruct{Value: 42}
+	New(nil, reflect.TypeOf(TestStruct{}), ts)
+	addr := uintptr(AsPtr(ts))
+
+	for i := 0; i < 10; i++ {
+		runtime.GC()
+		klasses.mu.Lock()
+		_, ok := klasses.byPtr[addr]
+		klasses.mu.Unlock()
+		if !ok {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if From(AsPtr(ts), reflect.TypeOf(TestStruct{})) != nil {
+		t.Error("From should return nil for a collected Klass")
+	}
+	klasses.mu.Lock()
+	defer klasses.mu.Unlock()
+	if _, ok := klasses.byPtr[addr]; ok {
+		t.Error("The entry of a collected Klass was not removed")
+	}
 }
 
 // TestNil tests the Nil struct
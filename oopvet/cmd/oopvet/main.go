@@ -0,0 +1,16 @@
+// Command oopvet reports misuse of the github.com/dracory/oop API.
+//
+// Usage:
+//
+//	go install github.com/dracory/oop/oopvet/cmd/oopvet@latest
+//	go vet -vettool=$(which oopvet) ./...
+package main
+
+import (
+	"github.com/dracory/oop/oopvet"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(oopvet.Analyzer)
+}
@@ -0,0 +1,363 @@
+// Package oopvet provides a go/analysis analyzer that flags misuse of the
+// github.com/dracory/oop API which would otherwise only fail at runtime.
+//
+// It reports:
+//   - ObjectWrapper.As called with something other than a pointer to an
+//     interface or a reflect.Type, e.g. As(IAnimal(nil)) instead of
+//     As((*IAnimal)(nil)) or As(oop.Iface[IAnimal]())
+//   - ObjectWrapper.As called with a target type that is statically known
+//     not to be an interface type
+//   - oop.Cast called with a target type that is statically known to be
+//     neither an interface nor a struct or pointer to a struct
+//   - oop.Iface instantiated with a type argument that is not an interface
+//   - wrapped objects created in a function and never destroyed, returned or
+//     handed over to other code
+package oopvet
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// oopPath is the import path of the analyzed package.
+const oopPath = "github.com/dracory/oop"
+
+// Analyzer reports misuse of the oop package.
+var Analyzer = &analysis.Analyzer{
+	Name:     "oopvet",
+	Doc:      "report misuse of the github.com/dracory/oop API",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// run runs the analyzer on a single package.
+func run(pass *analysis.Pass) (interface{}, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	insp.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		fn := calledFunc(pass, call)
+		if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != oopPath {
+			return
+		}
+
+		switch {
+		case fn.Name() == "As" && isMethodOf(fn, "ObjectWrapper"):
+			checkWrapperAs(pass, call)
+		case fn.Name() == "Cast" && !isMethod(fn):
+			checkCast(pass, call)
+		case fn.Name() == "Iface" && !isMethod(fn):
+			checkIface(pass, call)
+		}
+	})
+
+	insp.Preorder([]ast.Node{(*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)}, func(n ast.Node) {
+		switch fn := n.(type) {
+		case *ast.FuncDecl:
+			if fn.Body != nil {
+				checkDestroy(pass, fn.Body)
+			}
+		case *ast.FuncLit:
+			checkDestroy(pass, fn.Body)
+		}
+	})
+
+	return nil, nil
+}
+
+// checkWrapperAs reports As calls whose argument is neither a pointer to an
+// interface nor a reflect.Type of an interface.
+func checkWrapperAs(pass *analysis.Pass, call *ast.CallExpr) {
+	if len(call.Args) != 1 {
+		return
+	}
+
+	t := pass.TypesInfo.TypeOf(call.Args[0])
+	if t == nil {
+		return
+	}
+
+	if ptr, ok := t.Underlying().(*types.Pointer); ok && types.IsInterface(ptr.Elem()) {
+		return
+	}
+
+	if isReflectType(t) {
+		if target, ok := staticReflectType(pass, call.Args[0]); ok && !types.IsInterface(target) {
+			pass.Reportf(call.Args[0].Pos(), "As target %s is not an interface type", target)
+		}
+		return
+	}
+
+	pass.Reportf(call.Args[0].Pos(), "As expects a pointer to an interface, e.g. (*IAnimal)(nil), got %s", t)
+}
+
+// checkCast reports Cast calls whose target type is statically neither an
+// interface nor a struct, which Cast resolves through embedding.
+func checkCast(pass *analysis.Pass, call *ast.CallExpr) {
+	if len(call.Args) != 2 {
+		return
+	}
+
+	target, ok := staticReflectType(pass, call.Args[1])
+	if !ok || types.IsInterface(target) {
+		return
+	}
+	elem := target
+	if ptr, ok := target.Underlying().(*types.Pointer); ok {
+		elem = ptr.Elem()
+	}
+	if _, ok := elem.Underlying().(*types.Struct); ok {
+		return
+	}
+
+	pass.Reportf(call.Args[1].Pos(), "Cast target %s is neither an interface nor a struct type", target)
+}
+
+// checkIface reports Iface calls whose type argument is not an interface type.
+func checkIface(pass *analysis.Pass, call *ast.CallExpr) {
+	t, ok := typeArg(pass, call)
+	if !ok || types.IsInterface(t) {
+		return
+	}
+
+	pass.Reportf(call.Pos(), "Iface type argument %s is not an interface type", t)
+}
+
+// isReflectType reports whether t is reflect.Type.
+func isReflectType(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "reflect" && obj.Name() == "Type"
+}
+
+// staticReflectType returns the type described by reflect.TypeOf(x),
+// reflect.TypeOf(x).Elem() or oop.Iface[T]() when it can be determined at
+// compile time.
+func staticReflectType(pass *analysis.Pass, expr ast.Expr) (types.Type, bool) {
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return nil, false
+	}
+
+	// oop.Iface[T]()
+	if fn := calledFunc(pass, call); fn != nil && fn.Pkg() != nil && fn.Pkg().Path() == oopPath && fn.Name() == "Iface" {
+		return typeArg(pass, call)
+	}
+
+	// reflect.TypeOf(x).Elem()
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Elem" && len(call.Args) == 0 {
+		inner, ok := staticReflectType(pass, sel.X)
+		if !ok {
+			return nil, false
+		}
+		if ptr, ok := inner.Underlying().(*types.Pointer); ok {
+			return ptr.Elem(), true
+		}
+		return nil, false
+	}
+
+	// reflect.TypeOf(x)
+	fn := calledFunc(pass, call)
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != "reflect" || fn.Name() != "TypeOf" || len(call.Args) != 1 {
+		return nil, false
+	}
+
+	t := pass.TypesInfo.TypeOf(call.Args[0])
+	if t == nil || isNil(t) || types.IsInterface(t) {
+		return nil, false // The dynamic type is only known at runtime.
+	}
+	return t, true
+}
+
+// checkDestroy reports wrapped objects created in body that are never destroyed.
+// Objects that are returned, passed to other functions, stored or captured by
+// address are assumed to be owned by someone else and are not reported.
+func checkDestroy(pass *analysis.Pass, body *ast.BlockStmt) {
+	created := map[types.Object]*ast.Ident{}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false // Checked separately.
+		}
+
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || assign.Tok.String() != ":=" || len(assign.Lhs) != len(assign.Rhs) {
+			return true
+		}
+
+		for i, rhs := range assign.Rhs {
+			id, ok := assign.Lhs[i].(*ast.Ident)
+			if !ok || id.Name == "_" {
+				continue
+			}
+			if _, ok := ast.Unparen(rhs).(*ast.CallExpr); !ok || !isWrapperPointer(pass.TypesInfo.TypeOf(rhs)) {
+				continue
+			}
+			if obj := pass.TypesInfo.Defs[id]; obj != nil {
+				created[obj] = id
+			}
+		}
+		return true
+	})
+
+	if len(created) == 0 {
+		return
+	}
+
+	handled := map[types.Object]bool{}
+	var stack []ast.Node
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, n)
+
+		id, ok := n.(*ast.Ident)
+		if !ok || len(stack) < 2 {
+			return true
+		}
+		obj := pass.TypesInfo.Uses[id]
+		if _, ok := created[obj]; !ok {
+			return true
+		}
+
+		if isDestroyedOrHandedOver(id, stack[len(stack)-2]) {
+			handled[obj] = true
+		}
+		return true
+	})
+
+	for obj, id := range created {
+		if !handled[obj] {
+			pass.Reportf(id.Pos(), "%s is created but never destroyed; call %s.Destroy() (e.g. with defer)", id.Name, id.Name)
+		}
+	}
+}
+
+// isDestroyedOrHandedOver reports whether the use of id in parent either
+// destroys the object or hands it over to other code.
+func isDestroyedOrHandedOver(id *ast.Ident, parent ast.Node) bool {
+	switch p := parent.(type) {
+	case *ast.SelectorExpr:
+		if p.X == id && p.Sel.Name == "Destroy" {
+			return true
+		}
+	case *ast.CallExpr:
+		for _, arg := range p.Args {
+			if arg == id {
+				return true
+			}
+		}
+	case *ast.ReturnStmt, *ast.CompositeLit, *ast.KeyValueExpr, *ast.SendStmt:
+		return true
+	case *ast.UnaryExpr:
+		return p.Op.String() == "&"
+	case *ast.AssignStmt:
+		for _, rhs := range p.Rhs {
+			if rhs == id {
+				return true
+			}
+		}
+	case *ast.ValueSpec:
+		for _, v := range p.Values {
+			if v == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// calledFunc returns the function or method called by call, if it is statically known.
+func calledFunc(pass *analysis.Pass, call *ast.CallExpr) *types.Func {
+	id := funcIdent(call)
+	if id == nil {
+		return nil
+	}
+
+	fn, _ := pass.TypesInfo.Uses[id].(*types.Func)
+	return fn
+}
+
+// funcIdent returns the identifier naming the function called by call,
+// including explicitly instantiated generic functions such as oop.Iface[T].
+func funcIdent(call *ast.CallExpr) *ast.Ident {
+	fun := ast.Unparen(call.Fun)
+	switch index := fun.(type) {
+	case *ast.IndexExpr:
+		fun = index.X
+	case *ast.IndexListExpr:
+		fun = index.X
+	}
+
+	switch fun := fun.(type) {
+	case *ast.Ident:
+		return fun
+	case *ast.SelectorExpr:
+		return fun.Sel
+	default:
+		return nil
+	}
+}
+
+// typeArg returns the first type argument of a call to a generic function.
+func typeArg(pass *analysis.Pass, call *ast.CallExpr) (types.Type, bool) {
+	id := funcIdent(call)
+	if id == nil {
+		return nil, false
+	}
+
+	inst, ok := pass.TypesInfo.Instances[id]
+	if !ok || inst.TypeArgs.Len() == 0 {
+		return nil, false
+	}
+	return inst.TypeArgs.At(0), true
+}
+
+// isMethod reports whether fn is a method.
+func isMethod(fn *types.Func) bool {
+	return fn.Type().(*types.Signature).Recv() != nil
+}
+
+// isMethodOf reports whether fn is a method of the named oop type.
+func isMethodOf(fn *types.Func, typeName string) bool {
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return false
+	}
+
+	t := recv.Type()
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Name() == typeName
+}
+
+// isWrapperPointer reports whether t is *oop.ObjectWrapper.
+func isWrapperPointer(t types.Type) bool {
+	ptr, ok := t.(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == oopPath && obj.Name() == "ObjectWrapper"
+}
+
+// isNil reports whether t is the type of the untyped nil.
+func isNil(t types.Type) bool {
+	basic, ok := t.(*types.Basic)
+	return ok && basic.Kind() == types.UntypedNil
+}
@@ -0,0 +1,13 @@
+package oopvet_test
+
+import (
+	"testing"
+
+	"github.com/dracory/oop/oopvet"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+// TestAnalyzer tests the Analyzer against the testdata package
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), oopvet.Analyzer, "a")
+}
@@ -0,0 +1,80 @@
+package a
+
+import (
+	"reflect"
+
+	"github.com/dracory/oop"
+)
+
+type IAnimal interface {
+	Sound() string
+}
+
+type Dog struct {
+	Name string
+}
+
+func (d *Dog) Sound() string { return d.Name }
+
+type holder struct {
+	obj *oop.ObjectWrapper
+}
+
+func as() {
+	factory := oop.NewObjectFactory()
+	dog := factory.CreateObject(&Dog{})
+	defer dog.Destroy()
+
+	dog.As((*IAnimal)(nil))
+	dog.As(IAnimal(nil)) // want `As expects a pointer to an interface`
+	dog.As(&Dog{})       // want `As expects a pointer to an interface`
+	dog.As(nil)          // want `As expects a pointer to an interface`
+
+	dog.As(oop.Iface[IAnimal]())
+	dog.As(reflect.TypeOf((*IAnimal)(nil)).Elem())
+	dog.As(oop.Iface[Dog]())      // want `As target a.Dog is not an interface type` `Iface type argument a.Dog is not an interface type`
+	dog.As(reflect.TypeOf(Dog{})) // want `As target a.Dog is not an interface type`
+}
+
+func cast(d *Dog, a IAnimal) {
+	oop.Cast(d, reflect.TypeOf((*IAnimal)(nil)).Elem())
+	oop.Cast(d, reflect.TypeOf(a))
+	oop.Cast(d, reflect.TypeOf(Dog{}))
+	oop.Cast(d, reflect.TypeOf((*Dog)(nil)))
+	oop.Cast(d, reflect.TypeOf(0))                     // want `Cast target int is neither an interface nor a struct type`
+	oop.Cast(d, reflect.TypeOf((*string)(nil)).Elem()) // want `Cast target string is neither an interface nor a struct type`
+	oop.Cast(d, oop.Iface[IAnimal]())
+	oop.Cast(d, oop.Iface[Dog]()) // want `Iface type argument a.Dog is not an interface type`
+}
+
+func leaked() {
+	factory := oop.NewObjectFactory()
+	dog := factory.CreateObject(&Dog{}) // want `dog is created but never destroyed`
+	dog.As((*IAnimal)(nil))
+}
+
+func destroyedInClosure() {
+	factory := oop.NewObjectFactory()
+	dog := factory.CreateObject(&Dog{})
+	defer func() {
+		dog.Destroy()
+	}()
+}
+
+func returned() *oop.ObjectWrapper {
+	factory := oop.NewObjectFactory()
+	dog := factory.CreateObject(&Dog{})
+	return dog
+}
+
+func stored(h *holder) {
+	factory := oop.NewObjectFactory()
+	dog := factory.CreateObject(&Dog{})
+	h.obj = dog
+}
+
+func passed(consume func(*oop.ObjectWrapper)) {
+	factory := oop.NewObjectFactory()
+	dog := factory.CreateObject(&Dog{})
+	consume(dog)
+}
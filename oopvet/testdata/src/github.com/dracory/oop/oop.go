@@ -0,0 +1,22 @@
+// Package oop is a minimal stand-in for github.com/dracory/oop used by the analyzer tests.
+package oop
+
+import "reflect"
+
+type ObjectFactory struct{}
+
+func NewObjectFactory() *ObjectFactory { return &ObjectFactory{} }
+
+func (f *ObjectFactory) CreateObject(initializer interface{}) *ObjectWrapper {
+	return &ObjectWrapper{}
+}
+
+type ObjectWrapper struct{}
+
+func (o *ObjectWrapper) As(interfacePtr interface{}) (interface{}, error) { return nil, nil }
+
+func (o *ObjectWrapper) Destroy() {}
+
+func Cast(obj any, targetType reflect.Type) interface{} { return nil }
+
+func Iface[T any]() reflect.Type { return nil }
@@ -0,0 +1,109 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Override replaces the implementation of a method of a class at runtime, for
+// example to install a test double or hot-fix behavior.
+// The function must have the type of the method expression, taking a pointer
+// to the class as its first argument. Overrides affect dispatch through
+// Klass.Vtable, ObjectWrapper.Call and CallSuper on instances of the class;
+// direct Go calls and BindMethod are not affected. Subclasses promote the
+// embedded method into their own method table, so override them separately.
+// Vtables obtained before the override keep the previous entry.
+// The class is registered if it is not already; register it before creating
+// instances, so that all its instances share the same ClassInfo.
+// Example: oop.Override(reflect.TypeOf(Dog{}), "Sound", func(d *Dog) string { return "Meow!" })
+func Override(classType reflect.Type, method string, fn interface{}) error {
+	if classType == nil {
+		return fmt.Errorf("classType cannot be nil")
+	}
+
+	classType = classElem(classType)
+	m, ok := reflect.PointerTo(classType).MethodByName(method)
+	if !ok {
+		return fmt.Errorf("method %s not found on %s", method, classType)
+	}
+
+	fv := reflect.ValueOf(fn)
+	if fv.Kind() != reflect.Func || fv.IsNil() {
+		return fmt.Errorf("override of %s must be a function, got %T", method, fn)
+	}
+	if fv.Type() != m.Func.Type() {
+		return fmt.Errorf("override of %s must be a %s, got %s", method, m.Func.Type(), fv.Type())
+	}
+
+	info, err := registeredClass(classType)
+	if err != nil {
+		return err
+	}
+
+	info.mu.Lock()
+	defer info.mu.Unlock()
+
+	if info.overrides == nil {
+		info.overrides = map[string]reflect.Value{}
+	}
+	info.overrides[method] = fv
+	info.dropVtables(method)
+	return nil
+}
+
+// RestoreOriginal removes the override of a method installed with Override.
+// Example: defer oop.RestoreOriginal(reflect.TypeOf(Dog{}), "Sound")
+func RestoreOriginal(classType reflect.Type, method string) error {
+	if classType == nil {
+		return fmt.Errorf("classType cannot be nil")
+	}
+
+	info, ok := LookupType(classType)
+	if !ok {
+		return fmt.Errorf("class %s is not registered", classElem(classType))
+	}
+
+	info.mu.Lock()
+	defer info.mu.Unlock()
+
+	if _, ok := info.overrides[method]; !ok {
+		return fmt.Errorf("method %s of %s is not overridden", method, info.Type)
+	}
+	delete(info.overrides, method)
+	info.dropVtables(method)
+	return nil
+}
+
+// override returns the override of a method, if there is one.
+func (c *ClassInfo) override(method string) (reflect.Value, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fn, ok := c.overrides[method]
+	return fn, ok
+}
+
+// dropVtables removes the cached vtables that contain the method, so they are
+// rebuilt with the current overrides. The caller must hold c.mu.
+func (c *ClassInfo) dropVtables(method string) {
+	vtables := c.Vtables[:0:0]
+	for _, vi := range c.Vtables {
+		if (*Vtable)(vi.Vtable).Index(method) < 0 {
+			vtables = append(vtables, vi)
+		}
+	}
+	c.Vtables = vtables
+}
+
+// applyOverrides replaces the entries of a vtable of the class that are
+// overridden. The caller must hold c.mu.
+func (c *ClassInfo) applyOverrides(vt *Vtable) {
+	if vt.Receiver != reflect.PointerTo(c.Type) {
+		return // Overrides take a pointer receiver.
+	}
+	for i, entry := range vt.Entries {
+		if fn, ok := c.overrides[entry.Name]; ok {
+			vt.Entries[i].Func = fn
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package oop
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParrot is a test struct whose methods are overridden
+type TestParrot struct {
+	Name string
+}
+
+// Sound implements TestAnimal
+func (p *TestParrot) Sound() string {
+	return p.Name + ": Squawk!"
+}
+
+// TestParakeet extends TestParrot and inherits Sound
+type TestParakeet struct {
+	TestParrot
+}
+
+// TestOverride tests the Override and RestoreOriginal functions
+func TestOverride(t *testing.T) {
+	parrotType := reflect.TypeOf(TestParrot{})
+	animalType := reflect.TypeOf((*TestAnimal)(nil)).Elem()
+	factory := NewObjectFactory()
+	if _, err := Register(parrotType); err != nil {
+		t.Fatalf("Register returned error: %v", err)
+	}
+
+	parrot := factory.CreateObject(&TestParrot{Name: "Polly"})
+	defer parrot.Destroy()
+	before := parrot.GetUnderlyingObject().(*TestParrot)
+	klass := New(nil, parrotType, before)
+	if vt := klass.Vtable(animalType); vt == nil {
+		t.Fatal("Vtable returned nil")
+	}
+
+	if err := Override(parrotType, "Sound", func(p *TestParrot) string { return p.Name + ": Hello!" }); err != nil {
+		t.Fatalf("Override returned error: %v", err)
+	}
+
+	// Test dispatch through vtables and Call
+	vt := klass.Vtable(animalType)
+	if results, err := vt.Call(before, vt.Index("Sound")); err != nil || results[0] != "Polly: Hello!" {
+		t.Errorf("Vtable.Call returned %v, %v, want the override", results, err)
+	}
+	if results, err := parrot.Call("Sound"); err != nil || results[0] != "Polly: Hello!" {
+		t.Errorf("Call returned %v, %v, want the override", results, err)
+	}
+	if before.Sound() != "Polly: Squawk!" {
+		t.Error("Override should not affect direct calls")
+	}
+
+	// Test subclasses, which reach the override through CallSuper
+	if err := Extend(reflect.TypeOf(TestParakeet{}), parrotType); err != nil {
+		t.Fatalf("Extend returned error: %v", err)
+	}
+	parakeet := factory.CreateObject(&TestParakeet{TestParrot{Name: "Kiwi"}})
+	defer parakeet.Destroy()
+	if results, err := parakeet.CallSuper("Sound"); err != nil || results[0] != "Kiwi: Hello!" {
+		t.Errorf("CallSuper returned %v, %v, want the override of the parent", results, err)
+	}
+	if results, err := parakeet.Call("Sound"); err != nil || results[0] != "Kiwi: Squawk!" {
+		t.Errorf("Call returned %v, %v, want the promoted method", results, err)
+	}
+
+	// Test RestoreOriginal
+	if err := RestoreOriginal(parrotType, "Sound"); err != nil {
+		t.Fatalf("RestoreOriginal returned error: %v", err)
+	}
+	if results, _ := parrot.Call("Sound"); results[0] != "Polly: Squawk!" {
+		t.Errorf("Call returned %v after RestoreOriginal, want the original", results[0])
+	}
+	vt = klass.Vtable(animalType)
+	if results, _ := vt.Call(before, vt.Index("Sound")); results[0] != "Polly: Squawk!" {
+		t.Errorf("Vtable.Call returned %v after RestoreOriginal, want the original", results[0])
+	}
+	if err := RestoreOriginal(parrotType, "Sound"); err == nil {
+		t.Error("RestoreOriginal should return error for methods that are not overridden")
+	}
+
+	// Test invalid overrides
+	if err := Override(parrotType, "Unknown", func(p *TestParrot) {}); err == nil {
+		t.Error("Override should return error for unknown methods")
+	}
+	if err := Override(parrotType, "Sound", func(p *TestParrot) int { return 0 }); err == nil {
+		t.Error("Override should return error for a mismatched signature")
+	}
+	if err := Override(parrotType, "Sound", "not a function"); err == nil {
+		t.Error("Override should return error for non-functions")
+	}
+	if err := Override(nil, "Sound", nil); err == nil {
+		t.Error("Override should return error for nil classType")
+	}
+	if err := RestoreOriginal(reflect.TypeOf(TestCat{}), "Sound"); err == nil {
+		t.Error("RestoreOriginal should return error for unregistered classes")
+	}
+}
@@ -0,0 +1,65 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// pointerProbe is the class used by VerifyPointerSafety.
+type pointerProbe struct {
+	Value int64
+	Ref   *int
+	Name  string
+}
+
+// VerifyPointerSafety runs a self-test of the pointer handling of the package:
+// instance pointers, pointer round-trips through reflection and the allocator,
+// and the Klass layout. It returns an error describing the first failed check.
+// All conversions avoid uintptr round-trips, so the self-test also passes in
+// builds with -gcflags=all=-d=checkptr, -race or -asan.
+// Example: func init() { if err := oop.VerifyPointerSafety(); err != nil { panic(err) } }
+func VerifyPointerSafety() error {
+	probeType := reflect.TypeOf(pointerProbe{})
+	ref := 42
+
+	// Instance pointers
+	klass := New(nil, probeType, &pointerProbe{Value: 1, Ref: &ref, Name: "probe"})
+	ptr := klass.Ptr()
+	if ptr == nil {
+		return fmt.Errorf("Ptr returned nil")
+	}
+	if AsPtr(klass.Class) != ptr {
+		return fmt.Errorf("AsPtr and Ptr returned different pointers")
+	}
+
+	// Round-trip through reflection
+	probe, ok := reflect.NewAt(probeType, ptr).Interface().(*pointerProbe)
+	if !ok || probe != klass.Class {
+		return fmt.Errorf("pointer round-trip returned a different instance")
+	}
+	if probe.Value != 1 || probe.Ref == nil || *probe.Ref != 42 || probe.Name != "probe" {
+		return fmt.Errorf("pointer round-trip returned corrupted data %+v", probe)
+	}
+
+	// Round-trip through From
+	if From(ptr, probeType) != klass {
+		return fmt.Errorf("From did not return the Klass of the pointer")
+	}
+
+	// Round-trip through the allocator
+	allocated := New(nil, probeType, nil)
+	allocated.Class.(*pointerProbe).Name = "allocated"
+	if (*pointerProbe)(allocated.Ptr()).Name != "allocated" {
+		return fmt.Errorf("allocated instance does not match its pointer")
+	}
+	allocated.Deinit()
+	klass.Deinit()
+
+	// Klass layout
+	if offset := getClassOffset(probeType); offset != unsafe.Offsetof(Klass{}.Class) {
+		return fmt.Errorf("class offset is %d, want %d", offset, unsafe.Offsetof(Klass{}.Class))
+	}
+
+	return nil
+}
@@ -0,0 +1,12 @@
+package oop
+
+import (
+	"testing"
+)
+
+// TestVerifyPointerSafety tests the VerifyPointerSafety function
+func TestVerifyPointerSafety(t *testing.T) {
+	if err := VerifyPointerSafety(); err != nil {
+		t.Errorf("VerifyPointerSafety returned error: %v", err)
+	}
+}
@@ -0,0 +1,364 @@
+package oop
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// TypeKey is the JSON key of the type discriminator written by MarshalPolymorphic.
+const TypeKey = "$type"
+
+var (
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// MarshalPolymorphic encodes an instance of a registered class as JSON with a
+// "$type" discriminator holding its registered name, so UnmarshalPolymorphic
+// can reconstruct the concrete class. Values held in interface fields, slices
+// and maps are written with their own discriminator, so heterogeneous object
+// graphs survive a round trip. Fields are encoded like encoding/json does,
+// honoring json tags; types implementing json.Marshaler encode themselves.
+// Cyclic graphs cannot be encoded.
+// Example: data, err := oop.MarshalPolymorphic(dog) // {"$type":"main.Dog","Name":"Rex"}
+func MarshalPolymorphic(obj any) ([]byte, error) {
+	if obj == nil {
+		return nil, fmt.Errorf("obj cannot be nil")
+	}
+
+	v := reflect.ValueOf(obj)
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil, fmt.Errorf("obj cannot be nil")
+	}
+	if classElem(v.Type()).Kind() != reflect.Struct {
+		return nil, fmt.Errorf("obj must be a class instance, got %T", obj)
+	}
+	return encodePolymorphic(v, true)
+}
+
+// UnmarshalPolymorphic decodes JSON written by MarshalPolymorphic into a new
+// instance of the class registered under its "$type" name and returns a
+// pointer to it. Objects held in interfaces are also returned as pointers.
+// Example: obj, err := oop.UnmarshalPolymorphic(data) // *Dog
+func UnmarshalPolymorphic(data []byte) (any, error) {
+	ptr, err := decodeTyped(data)
+	if err != nil {
+		return nil, err
+	}
+	return ptr.Interface(), nil
+}
+
+// encodePolymorphic encodes a value as JSON. If typed is set, structs are
+// written with a type discriminator, which requires their class to be
+// registered. Cyclic values cannot be encoded.
+func encodePolymorphic(v reflect.Value, typed bool) (json.RawMessage, error) {
+	enc := &polymorphicEncoder{visiting: map[refKey]bool{}}
+	return enc.encode(v, typed)
+}
+
+// polymorphicEncoder holds the state of a single encodePolymorphic call.
+type polymorphicEncoder struct {
+	visiting map[refKey]bool // Pointers, maps and slices on the current path, used to detect cycles.
+}
+
+// visit encodes a pointer, map or slice with fn, and returns an error instead
+// if it is already on the current path.
+func (e *polymorphicEncoder) visit(v reflect.Value, fn func() (json.RawMessage, error)) (json.RawMessage, error) {
+	key := newRefKey(v)
+	if e.visiting[key] {
+		return nil, fmt.Errorf("cannot encode cyclic value of type %s", v.Type())
+	}
+	e.visiting[key] = true
+	defer delete(e.visiting, key)
+	return fn()
+}
+
+// encode implements encodePolymorphic.
+func (e *polymorphicEncoder) encode(v reflect.Value, typed bool) (json.RawMessage, error) {
+	if !v.IsValid() {
+		return json.RawMessage("null"), nil
+	}
+	if !typed && v.Type().Implements(jsonMarshalerType) {
+		return json.Marshal(v.Interface())
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return json.RawMessage("null"), nil
+		}
+		return e.encode(v.Elem(), true)
+	case reflect.Ptr:
+		if v.IsNil() {
+			return json.RawMessage("null"), nil
+		}
+		return e.visit(v, func() (json.RawMessage, error) { return e.encode(v.Elem(), typed) })
+	case reflect.Struct:
+		return e.encodeStruct(v, typed)
+	case reflect.Slice:
+		if v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8 {
+			return json.Marshal(v.Interface()) // null, or base64 for []byte
+		}
+		return e.visit(v, func() (json.RawMessage, error) { return e.encodeList(v) })
+	case reflect.Array:
+		return e.encodeList(v)
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			return json.Marshal(v.Interface())
+		}
+		return e.visit(v, func() (json.RawMessage, error) { return e.encodeMap(v) })
+	default:
+		return json.Marshal(v.Interface())
+	}
+}
+
+// encodeList encodes a slice or array as a JSON array.
+func (e *polymorphicEncoder) encodeList(v reflect.Value) (json.RawMessage, error) {
+	items := make([]json.RawMessage, v.Len())
+	for i := range items {
+		item, err := e.encode(v.Index(i), false)
+		if err != nil {
+			return nil, fmt.Errorf("index %d: %w", i, err)
+		}
+		items[i] = item
+	}
+	return json.Marshal(items)
+}
+
+// encodeMap encodes a map with string keys as a JSON object.
+func (e *polymorphicEncoder) encodeMap(v reflect.Value) (json.RawMessage, error) {
+	values := make(map[string]json.RawMessage, v.Len())
+	for iter := v.MapRange(); iter.Next(); {
+		value, err := e.encode(iter.Value(), false)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", iter.Key().String(), err)
+		}
+		values[iter.Key().String()] = value
+	}
+	return json.Marshal(values)
+}
+
+// encodeStruct encodes a struct as a JSON object.
+func (e *polymorphicEncoder) encodeStruct(v reflect.Value, typed bool) (json.RawMessage, error) {
+	values := map[string]json.RawMessage{}
+	var info *ClassInfo
+	if typed {
+		var ok bool
+		if info, ok = LookupType(v.Type()); !ok {
+			return nil, fmt.Errorf("class %s is not registered", v.Type())
+		}
+	}
+
+	if typed && v.Type().Implements(jsonMarshalerType) {
+		// Let the class encode itself and add the discriminator to its object.
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("%s must marshal to a JSON object: %w", v.Type(), err)
+		}
+	} else if err := e.encodeFields(v, values); err != nil {
+		return nil, err
+	}
+
+	if typed {
+		values[TypeKey], _ = json.Marshal(info.Name)
+	}
+	return json.Marshal(values) // Keys are sorted, so "$type" comes first.
+}
+
+// encodeFields adds the exported fields of a struct to values, flattening
+// embedded structs like ToMap does.
+func (e *polymorphicEncoder) encodeFields(v reflect.Value, values map[string]json.RawMessage) error {
+	opts := mapOptions{tag: "json"}
+	for _, field := range exportedFields(v.Type()) {
+		key, named, omitEmpty, skip := mapKey(field, opts)
+		if skip {
+			continue
+		}
+
+		fv := v.Field(field.Index[0])
+		if field.Anonymous && !named && fv.Kind() == reflect.Struct {
+			if err := e.encodeFields(fv, values); err != nil {
+				return err
+			}
+			continue
+		}
+		if omitEmpty && fv.IsZero() {
+			continue
+		}
+
+		value, err := e.encode(fv, false)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", key, err)
+		}
+		values[key] = value
+	}
+	return nil
+}
+
+// decodeTyped decodes a JSON object with a type discriminator into a new
+// instance of the registered class and returns a pointer to it.
+func decodeTyped(data []byte) (reflect.Value, error) {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return reflect.Value{}, err
+	}
+
+	var name string
+	if raw, ok := values[TypeKey]; !ok {
+		return reflect.Value{}, fmt.Errorf("missing %s discriminator", TypeKey)
+	} else if err := json.Unmarshal(raw, &name); err != nil {
+		return reflect.Value{}, fmt.Errorf("invalid %s discriminator: %w", TypeKey, err)
+	}
+
+	info, ok := Lookup(name)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("class %s is not registered", name)
+	}
+
+	ptr := reflect.New(info.Type)
+	if err := decodePolymorphic(ptr.Elem(), data); err != nil {
+		return reflect.Value{}, fmt.Errorf("%s: %w", name, err)
+	}
+	return ptr, nil
+}
+
+// decodePolymorphic decodes JSON into the addressable value target.
+func decodePolymorphic(target reflect.Value, data json.RawMessage) error {
+	if reflect.PointerTo(target.Type()).Implements(jsonUnmarshalerType) {
+		return json.Unmarshal(data, target.Addr().Interface())
+	}
+
+	isNull := bytes.Equal(bytes.TrimSpace(data), []byte("null"))
+
+	switch target.Kind() {
+	case reflect.Interface:
+		if isNull {
+			target.SetZero()
+			return nil
+		}
+		if !isTypedObject(data) {
+			return json.Unmarshal(data, target.Addr().Interface())
+		}
+		ptr, err := decodeTyped(data)
+		if err != nil {
+			return err
+		}
+		switch {
+		case ptr.Type().AssignableTo(target.Type()):
+			target.Set(ptr)
+		case ptr.Elem().Type().AssignableTo(target.Type()):
+			target.Set(ptr.Elem())
+		default:
+			return fmt.Errorf("%s is not assignable to %s", ptr.Type(), target.Type())
+		}
+		return nil
+	case reflect.Ptr:
+		if isNull {
+			target.SetZero()
+			return nil
+		}
+		ptr := reflect.New(target.Type().Elem())
+		if err := decodePolymorphic(ptr.Elem(), data); err != nil {
+			return err
+		}
+		target.Set(ptr)
+		return nil
+	case reflect.Struct:
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(data, &values); err != nil {
+			return err
+		}
+		return decodeFieldsPolymorphic(target, values)
+	case reflect.Slice:
+		if isNull || target.Type().Elem().Kind() == reflect.Uint8 {
+			return json.Unmarshal(data, target.Addr().Interface())
+		}
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		slice := reflect.MakeSlice(target.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodePolymorphic(slice.Index(i), item); err != nil {
+				return fmt.Errorf("index %d: %w", i, err)
+			}
+		}
+		target.Set(slice)
+		return nil
+	case reflect.Array:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		for i := 0; i < len(items) && i < target.Len(); i++ {
+			if err := decodePolymorphic(target.Index(i), items[i]); err != nil {
+				return fmt.Errorf("index %d: %w", i, err)
+			}
+		}
+		return nil
+	case reflect.Map:
+		if isNull || target.Type().Key().Kind() != reflect.String {
+			return json.Unmarshal(data, target.Addr().Interface())
+		}
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(data, &values); err != nil {
+			return err
+		}
+		m := reflect.MakeMapWithSize(target.Type(), len(values))
+		for key, raw := range values {
+			value := reflect.New(target.Type().Elem()).Elem()
+			if err := decodePolymorphic(value, raw); err != nil {
+				return fmt.Errorf("key %s: %w", key, err)
+			}
+			m.SetMapIndex(reflect.ValueOf(key).Convert(target.Type().Key()), value)
+		}
+		target.Set(m)
+		return nil
+	default:
+		return json.Unmarshal(data, target.Addr().Interface())
+	}
+}
+
+// decodeFieldsPolymorphic sets the exported fields of a struct from the
+// members of a JSON object, the inverse of encodeFieldsPolymorphic.
+func decodeFieldsPolymorphic(v reflect.Value, values map[string]json.RawMessage) error {
+	opts := mapOptions{tag: "json"}
+	for _, field := range exportedFields(v.Type()) {
+		key, named, _, skip := mapKey(field, opts)
+		if skip {
+			continue
+		}
+
+		fv := v.Field(field.Index[0])
+		if field.Anonymous && !named && fv.Kind() == reflect.Struct {
+			if err := decodeFieldsPolymorphic(fv, values); err != nil {
+				return err
+			}
+			continue
+		}
+
+		raw, ok := values[key]
+		if !ok {
+			continue
+		}
+		if err := decodePolymorphic(fv, raw); err != nil {
+			return fmt.Errorf("field %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// isTypedObject reports whether data is a JSON object with a type discriminator.
+func isTypedObject(data []byte) bool {
+	var values map[string]json.RawMessage
+	if json.Unmarshal(data, &values) != nil {
+		return false
+	}
+	_, ok := values[TypeKey]
+	return ok
+}
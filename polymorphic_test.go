@@ -0,0 +1,163 @@
+package oop
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestZoo is a test class holding animals behind interfaces
+type TestZoo struct {
+	Name    string                `json:"name"`
+	Animals []TestAnimal          `json:"animals"`
+	ByName  map[string]TestAnimal `json:"by_name"`
+	Keeper  any                   `json:"keeper"`
+	Mascot  TestAnimal            `json:"mascot"`
+	Opened  time.Time             `json:"opened"`
+	Secret  string                `json:"-"`
+	Count   int                   `json:"count,omitempty"`
+}
+
+// TestHamster implements TestAnimal and is never registered
+type TestHamster struct{}
+
+// Sound implements TestAnimal
+func (h *TestHamster) Sound() string {
+	return "Squeak!"
+}
+
+// TestBadge is a test class with a custom JSON encoding
+type TestBadge struct {
+	ID int
+}
+
+// MarshalJSON implements json.Marshaler
+func (b TestBadge) MarshalJSON() ([]byte, error) {
+	return []byte(`{"badge":"` + strings.Repeat("I", b.ID) + `"}`), nil
+}
+
+// registerPolymorphicClasses registers the classes used by the polymorphic tests
+func registerPolymorphicClasses(t *testing.T) {
+	t.Helper()
+
+	for _, classType := range []reflect.Type{reflect.TypeOf(TestZoo{}), reflect.TypeOf(TestDog{}), reflect.TypeOf(TestCat{})} {
+		if _, err := Register(classType); err != nil {
+			t.Fatalf("Register returned error: %v", err)
+		}
+	}
+}
+
+// TestMarshalPolymorphic tests the MarshalPolymorphic and UnmarshalPolymorphic functions
+func TestMarshalPolymorphic(t *testing.T) {
+	registerPolymorphicClasses(t)
+
+	opened := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	zoo := &TestZoo{
+		Name:    "City Zoo",
+		Animals: []TestAnimal{&TestDog{Name: "Rex"}, &TestCat{Name: "Tom"}},
+		ByName:  map[string]TestAnimal{"tom": &TestCat{Name: "Tom"}},
+		Keeper:  "Alice",
+		Opened:  opened,
+		Secret:  "hidden",
+	}
+
+	data, err := MarshalPolymorphic(zoo)
+	if err != nil {
+		t.Fatalf("MarshalPolymorphic returned error: %v", err)
+	}
+	if !strings.HasPrefix(string(data), `{"$type":"oop.TestZoo",`) {
+		t.Errorf("MarshalPolymorphic wrote %s, want the discriminator first", data)
+	}
+	if !strings.Contains(string(data), `{"$type":"oop.TestDog","Name":"Rex"}`) {
+		t.Errorf("MarshalPolymorphic wrote %s, want discriminators for interface values", data)
+	}
+	if strings.Contains(string(data), "hidden") || strings.Contains(string(data), "count") {
+		t.Errorf("MarshalPolymorphic wrote %s, want json tags honored", data)
+	}
+
+	obj, err := UnmarshalPolymorphic(data)
+	if err != nil {
+		t.Fatalf("UnmarshalPolymorphic returned error: %v", err)
+	}
+	got, ok := obj.(*TestZoo)
+	if !ok {
+		t.Fatalf("UnmarshalPolymorphic returned %T, want *TestZoo", obj)
+	}
+
+	zoo.Secret = ""
+	if !reflect.DeepEqual(got, zoo) {
+		t.Errorf("UnmarshalPolymorphic returned %+v, want %+v", got, zoo)
+	}
+
+	// Test that the result is valid JSON for other decoders
+	var plain map[string]any
+	if err := json.Unmarshal(data, &plain); err != nil {
+		t.Errorf("MarshalPolymorphic wrote invalid JSON: %v", err)
+	}
+
+	// Test classes implementing json.Marshaler
+	if _, err := Register(reflect.TypeOf(TestBadge{})); err != nil {
+		t.Fatalf("Register returned error: %v", err)
+	}
+	data, err = MarshalPolymorphic(TestBadge{ID: 3})
+	if err != nil || string(data) != `{"$type":"oop.TestBadge","badge":"III"}` {
+		t.Errorf("MarshalPolymorphic returned %s, %v, want the custom encoding with a discriminator", data, err)
+	}
+}
+
+// TestMarshalPolymorphicErrors tests the errors of MarshalPolymorphic and UnmarshalPolymorphic
+func TestMarshalPolymorphicErrors(t *testing.T) {
+	registerPolymorphicClasses(t)
+
+	if _, err := MarshalPolymorphic(nil); err == nil {
+		t.Error("MarshalPolymorphic should return error for nil")
+	}
+	if _, err := MarshalPolymorphic((*TestZoo)(nil)); err == nil {
+		t.Error("MarshalPolymorphic should return error for nil pointers")
+	}
+	if _, err := MarshalPolymorphic(42); err == nil {
+		t.Error("MarshalPolymorphic should return error for non-structs")
+	}
+	if _, err := MarshalPolymorphic(&TestGreeter{}); err == nil {
+		t.Error("MarshalPolymorphic should return error for unregistered classes")
+	}
+	if _, err := MarshalPolymorphic(&TestZoo{Mascot: &TestHamster{}}); err == nil {
+		t.Error("MarshalPolymorphic should return error for unregistered classes behind interfaces")
+	}
+
+	// Test cyclic graphs through pointers, maps and slices
+	zoo := &TestZoo{Name: "loop"}
+	zoo.Keeper = zoo
+	if _, err := MarshalPolymorphic(zoo); err == nil {
+		t.Error("MarshalPolymorphic should return error for cyclic pointers")
+	}
+	m := map[string]any{}
+	m["self"] = m
+	if _, err := MarshalPolymorphic(&TestZoo{Keeper: m}); err == nil {
+		t.Error("MarshalPolymorphic should return error for cyclic maps")
+	}
+	s := make([]any, 1)
+	s[0] = s
+	if _, err := MarshalPolymorphic(&TestZoo{Keeper: s}); err == nil {
+		t.Error("MarshalPolymorphic should return error for cyclic slices")
+	}
+	dog := &TestDog{Name: "Rex"}
+	if _, err := MarshalPolymorphic(&TestZoo{Animals: []TestAnimal{dog, dog}}); err != nil {
+		t.Errorf("MarshalPolymorphic returned error for shared pointers: %v", err)
+	}
+
+	for _, data := range []string{
+		`[]`,
+		`{"Name":"Rex"}`,
+		`{"$type":42}`,
+		`{"$type":"oop.Unknown"}`,
+		`{"$type":"oop.TestZoo","mascot":{"$type":"oop.TestZoo"}}`,
+		`{"$type":"oop.TestZoo","name":42}`,
+	} {
+		if _, err := UnmarshalPolymorphic([]byte(data)); err == nil {
+			t.Errorf("UnmarshalPolymorphic should return error for %s", data)
+		}
+	}
+}
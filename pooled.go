@@ -0,0 +1,86 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// PooledFactory reuses the Klass instances of one class through a sync.Pool,
+// for servers creating many short-lived objects of a class: Acquire takes an
+// instance from the pool, or creates one if it is empty, and Release resets it
+// and puts it back. Unlike PoolAllocator, which only reuses the memory of
+// destroyed objects, pooled instances are not deinitialized between uses, so
+// destroy hooks only run when an acquired instance is deinitialized
+// explicitly; instances the pool drops are left to the garbage collector.
+type PooledFactory struct {
+	classType reflect.Type
+	reset     func(any) // Resets an instance before it is reused; nil zeroes it.
+	pool      sync.Pool
+
+	acquires atomic.Uint64
+	releases atomic.Uint64
+	reused   atomic.Uint64
+}
+
+// NewPooledFactory creates a PooledFactory for a class type. The reset
+// function is called with the class instance, e.g. a *Request, when it is
+// released, to clear it for its next use; if it is nil, the instance is zeroed.
+// It returns nil if classType is nil.
+// Example: pool := oop.NewPooledFactory(reflect.TypeOf(Request{}), func(obj any) { obj.(*Request).Reset() })
+func NewPooledFactory(classType reflect.Type, resetFn func(any)) *PooledFactory {
+	if classType == nil {
+		return nil
+	}
+	return &PooledFactory{classType: classElem(classType), reset: resetFn}
+}
+
+// Acquire returns an instance of the class, reused from the pool if one is
+// available. It must be handed back with Release once it is no longer used.
+// Example: klass := pool.Acquire(); req := klass.Class.(*Request); defer pool.Release(klass)
+func (f *PooledFactory) Acquire() *Klass {
+	f.acquires.Add(1)
+
+	klass, _ := f.pool.Get().(*Klass)
+	if klass != nil {
+		f.reused.Add(1)
+		tracker.add(klass)
+	} else {
+		klass = New(nil, f.classType, nil)
+	}
+	klass.pool.Store(f)
+	return klass
+}
+
+// Release resets an instance returned by Acquire and puts it back in the pool.
+// It returns an error if the instance was not acquired from this factory or
+// was already released. Instances deinitialized while acquired are dropped.
+// Example: pool.Release(klass)
+func (f *PooledFactory) Release(klass *Klass) error {
+	if klass == nil {
+		return fmt.Errorf("klass cannot be nil")
+	}
+	if !klass.pool.CompareAndSwap(f, nil) {
+		return fmt.Errorf("klass was not acquired from this pool")
+	}
+	f.releases.Add(1)
+
+	if klass.Class == nil {
+		return nil // Deinitialized.
+	}
+	if f.reset != nil {
+		f.reset(klass.Class)
+	} else {
+		reflect.ValueOf(klass.Class).Elem().SetZero()
+	}
+	tracker.remove(klass)
+	f.pool.Put(klass)
+	return nil
+}
+
+// Stats returns the statistics of the pool: Allocs counts the instances
+// acquired, Frees those released and Reused the acquisitions served from the pool.
+func (f *PooledFactory) Stats() AllocStats {
+	return AllocStats{Allocs: f.acquires.Load(), Frees: f.releases.Load(), Reused: f.reused.Load()}
+}
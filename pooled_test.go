@@ -0,0 +1,152 @@
+package oop
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestPooledRequest is a test class reused through a PooledFactory
+type TestPooledRequest struct {
+	Path   string
+	Header map[string]string
+}
+
+// TestPooledFactory tests acquiring and releasing pooled instances
+func TestPooledFactory(t *testing.T) {
+	resets := 0
+	pool := NewPooledFactory(reflect.TypeOf(&TestPooledRequest{}), func(obj any) {
+		resets++
+		req := obj.(*TestPooledRequest)
+		req.Path = ""
+		clear(req.Header)
+	})
+
+	klass := pool.Acquire()
+	req, ok := klass.Class.(*TestPooledRequest)
+	if !ok {
+		t.Fatalf("Acquire returned %T, want *TestPooledRequest", klass.Class)
+	}
+	req.Path = "/users"
+	req.Header = map[string]string{"Accept": "json"}
+	if err := pool.Release(klass); err != nil {
+		t.Fatalf("Release returned error: %v", err)
+	}
+	if resets != 1 || req.Path != "" || len(req.Header) != 0 {
+		t.Errorf("Release left %+v after %d resets, want a reset instance", req, resets)
+	}
+
+	// Test that released instances are reused, unless the pool dropped them
+	again := pool.Acquire()
+	if again == klass {
+		if pool.Stats().Reused != 1 {
+			t.Errorf("Stats are %+v, want 1 reused", pool.Stats())
+		}
+		if again.Class.(*TestPooledRequest).Header == nil {
+			t.Error("the reset function was not used to clear the reused instance")
+		}
+	}
+	if stats := pool.Stats(); stats.Allocs != 2 || stats.Frees != 1 || stats.Live() != 1 {
+		t.Errorf("Stats are %+v, want 2 acquired and 1 released", stats)
+	}
+
+	// Test release errors
+	if klass != again {
+		if err := pool.Release(klass); err == nil {
+			t.Error("Release should return error for a released instance")
+		}
+	}
+	if err := pool.Release(nil); err == nil {
+		t.Error("Release should return error for nil")
+	}
+	if err := pool.Release(New(nil, reflect.TypeOf(TestPooledRequest{}), nil)); err == nil {
+		t.Error("Release should return error for an instance from elsewhere")
+	}
+	if err := NewPooledFactory(reflect.TypeOf(TestPooledRequest{}), nil).Release(again); err == nil {
+		t.Error("Release should return error for an instance from another pool")
+	}
+
+	// Test that deinitialized instances are dropped
+	again.Deinit()
+	if err := pool.Release(again); err != nil {
+		t.Errorf("Release returned error for a deinitialized instance: %v", err)
+	}
+	if next := pool.Acquire(); next == again || next.Class == nil {
+		t.Error("Acquire reused a deinitialized instance")
+	}
+
+	if NewPooledFactory(nil, nil) != nil {
+		t.Error("NewPooledFactory should return nil for a nil class type")
+	}
+}
+
+// TestPooledFactoryZero tests zeroing instances without a reset function
+func TestPooledFactoryZero(t *testing.T) {
+	pool := NewPooledFactory(reflect.TypeOf(TestPooledRequest{}), nil)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				klass := pool.Acquire()
+				req := klass.Class.(*TestPooledRequest)
+				if req.Path != "" || req.Header != nil {
+					t.Errorf("Acquire returned a dirty instance %+v", req)
+				}
+				req.Path = "/orders"
+				req.Header = map[string]string{}
+				pool.Release(klass)
+			}
+		}()
+	}
+	wg.Wait()
+	if stats := pool.Stats(); stats.Allocs != 800 || stats.Live() != 0 {
+		t.Errorf("Stats are %+v, want 800 acquired and none live", stats)
+	}
+}
+
+// TestPooledFactoryRelease tests that concurrent releases of an instance
+// put it back once, and that tracking follows it in and out of the pool
+func TestPooledFactoryRelease(t *testing.T) {
+	EnableTracking()
+	defer DisableTracking()
+
+	pool := NewPooledFactory(reflect.TypeOf(TestPooledRequest{}), nil)
+	klass := pool.Acquire()
+	if len(LiveObjects(reflect.TypeOf(TestPooledRequest{}))) != 1 {
+		t.Error("LiveObjects should list the acquired instance")
+	}
+
+	var released atomic.Int32
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if pool.Release(klass) == nil {
+				released.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if released.Load() != 1 || pool.Stats().Frees != 1 {
+		t.Errorf("%d releases succeeded with stats %+v, want 1", released.Load(), pool.Stats())
+	}
+	if len(LiveObjects(reflect.TypeOf(TestPooledRequest{}))) != 0 {
+		t.Error("LiveObjects should not list a released instance")
+	}
+
+	// Test that reused instances are tracked again
+	for range 10 {
+		klass = pool.Acquire()
+		if len(LiveObjects(reflect.TypeOf(TestPooledRequest{}))) != 1 {
+			t.Fatal("LiveObjects should list the acquired instance")
+		}
+		if err := pool.Release(klass); err != nil {
+			t.Fatalf("Release returned error: %v", err)
+		}
+	}
+}
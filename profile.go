@@ -0,0 +1,184 @@
+package oop
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Env describes the environment a Container is compiled for, see Compile.
+type Env struct {
+	Profiles []string          // Active profiles, e.g. "dev" or "test".
+	Vars     map[string]string // Settings conditions can check, e.g. environment variables.
+}
+
+// OSEnv returns the environment of the process: the profiles are read from
+// the comma-separated environment variable profilesVar, and Vars holds all
+// environment variables.
+// Example: report, err := c.Compile(oop.OSEnv("APP_PROFILES"))
+func OSEnv(profilesVar string) Env {
+	env := Env{Vars: map[string]string{}}
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env.Vars[k] = v
+		}
+	}
+	for _, profile := range strings.Split(env.Vars[profilesVar], ",") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			env.Profiles = append(env.Profiles, profile)
+		}
+	}
+	return env
+}
+
+// HasProfile reports whether a profile is active.
+func (e Env) HasProfile(profile string) bool {
+	return slices.Contains(e.Profiles, profile)
+}
+
+// Conditional registers providers in a Container that are only used if a
+// condition holds for the environment the container is compiled for, see
+// Container.Profile and Container.When.
+type Conditional struct {
+	c     *Container
+	label string         // Describes the condition in the CompileReport.
+	match func(Env) bool // The condition.
+}
+
+// conditionalProvider is a provider waiting for Compile.
+type conditionalProvider struct {
+	p    *provider
+	cond *Conditional
+}
+
+// CompileReport describes the providers Compile selected.
+type CompileReport struct {
+	Profiles []string // Active profiles.
+	Active   []string // Conditional providers that were registered, with their condition.
+	Skipped  []string // Conditional providers that were left out, with their condition.
+}
+
+// String returns a human-readable report.
+func (r *CompileReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "active profiles: %s\n", strings.Join(r.Profiles, ", "))
+	for _, p := range r.Active {
+		fmt.Fprintf(&b, "+ %s\n", p)
+	}
+	for _, p := range r.Skipped {
+		fmt.Fprintf(&b, "- %s\n", p)
+	}
+	return b.String()
+}
+
+// Profile returns a Conditional registering providers that are only used if
+// one of the profiles is active.
+// Example: c.Profile("dev", "test").Provide(func() Gateway { return &MockGateway{} })
+func (c *Container) Profile(profiles ...string) *Conditional {
+	return &Conditional{
+		c:     c.root,
+		label: "profile " + strings.Join(profiles, "|"),
+		match: func(env Env) bool {
+			return slices.ContainsFunc(profiles, env.HasProfile)
+		},
+	}
+}
+
+// When returns a Conditional registering providers that are only used if the
+// condition holds for the environment.
+// Example: c.When(func(env oop.Env) bool { return env.Vars["REGION"] == "eu" }).Provide(NewEUGateway)
+func (c *Container) When(condition func(env Env) bool) *Conditional {
+	return &Conditional{c: c.root, label: "condition", match: condition}
+}
+
+// Provide registers a transient constructor like Container.Provide, if the
+// condition holds when the container is compiled.
+func (cond *Conditional) Provide(constructor interface{}, opts ...ProviderOption) error {
+	return cond.register(constructor, LifetimeTransient, opts)
+}
+
+// Singleton registers a constructor like Container.Singleton, if the
+// condition holds when the container is compiled.
+func (cond *Conditional) Singleton(constructor interface{}, opts ...ProviderOption) error {
+	return cond.register(constructor, LifetimeSingleton, opts)
+}
+
+// Scoped registers a constructor like Container.Scoped, if the condition
+// holds when the container is compiled.
+func (cond *Conditional) Scoped(constructor interface{}, opts ...ProviderOption) error {
+	return cond.register(constructor, LifetimeScoped, opts)
+}
+
+// register validates a constructor and keeps it until Compile.
+func (cond *Conditional) register(constructor interface{}, lifetime Lifetime, opts []ProviderOption) error {
+	if cond.match == nil {
+		return fmt.Errorf("condition cannot be nil")
+	}
+	p, err := newProvider(constructor, lifetime, opts)
+	if err != nil {
+		return err
+	}
+
+	c := cond.c
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.compiled {
+		return fmt.Errorf("cannot register %s: the container is already compiled", p.out)
+	}
+	c.pending = append(c.pending, conditionalProvider{p: p, cond: cond})
+	return nil
+}
+
+// Compile registers the providers of Profile and When whose condition holds
+// for env, in registration order, then checks that the dependencies of every
+// provider can be resolved, reporting missing, ambiguous and circular
+// dependencies. Until then, the conditional providers are not used. It
+// returns a report of the active profiles and of the selected providers, and
+// an error if the container was already compiled.
+// Example: report, err := c.Compile(oop.Env{Profiles: []string{"dev"}})
+func (c *Container) Compile(env Env) (*CompileReport, error) {
+	root := c.root
+	root.mu.Lock()
+	if root.compiled {
+		root.mu.Unlock()
+		return nil, fmt.Errorf("the container is already compiled")
+	}
+	root.compiled = true
+	pending := root.pending
+	root.pending = nil
+	root.mu.Unlock()
+
+	report := &CompileReport{Profiles: slices.Clone(env.Profiles)}
+	for _, cp := range pending {
+		entry := fmt.Sprintf("%s (%s)", cp.p, cp.cond.label)
+		if !cp.cond.match(env) {
+			report.Skipped = append(report.Skipped, entry)
+			continue
+		}
+		if err := root.add(cp.p); err != nil {
+			return report, err
+		}
+		report.Active = append(report.Active, entry)
+	}
+
+	root.mu.Lock()
+	providers := make([]*provider, 0, len(root.providers))
+	for _, p := range root.providers {
+		providers = append(providers, p)
+	}
+	root.mu.Unlock()
+	slices.SortFunc(providers, func(a, b *provider) int { return cmp.Compare(a.order, b.order) })
+
+	for _, p := range providers {
+		checked := map[reflect.Type]bool{}
+		for _, dep := range p.deps {
+			if err := root.check(dep, []reflect.Type{p.out}, checked); err != nil {
+				return report, err
+			}
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,122 @@
+package oop
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestGateway is a test interface with an implementation per environment
+type TestGateway interface {
+	Charge(amount int) string
+}
+
+// TestMockGateway is a test implementation of TestGateway for development
+type TestMockGateway struct{}
+
+// Charge implements TestGateway
+func (g *TestMockGateway) Charge(amount int) string {
+	return "mock"
+}
+
+// TestRealGateway is a test implementation of TestGateway for production
+type TestRealGateway struct{}
+
+// Charge implements TestGateway
+func (g *TestRealGateway) Charge(amount int) string {
+	return "real"
+}
+
+// newTestGatewayContainer registers a gateway per environment
+func newTestGatewayContainer(t *testing.T) *Container {
+	t.Helper()
+	c := NewContainer()
+	if err := c.Profile("dev", "test").Provide(func() TestGateway { return &TestMockGateway{} }); err != nil {
+		t.Fatalf("Provide returned error: %v", err)
+	}
+	if err := c.Profile("prod").Singleton(func() *TestRealGateway { return &TestRealGateway{} }); err != nil {
+		t.Fatalf("Singleton returned error: %v", err)
+	}
+	if err := c.When(func(env Env) bool { return env.Vars["REGION"] == "eu" }).Provide(func(g TestGateway) *TestDB { return &TestDB{Name: "eu"} }); err != nil {
+		t.Fatalf("Provide returned error: %v", err)
+	}
+	return c
+}
+
+// TestContainerProfiles tests selecting providers by profile and condition
+func TestContainerProfiles(t *testing.T) {
+	for profile, want := range map[string]string{"test": "mock", "prod": "real"} {
+		c := newTestGatewayContainer(t)
+		if _, err := Resolve[TestGateway](c); err == nil {
+			t.Error("Resolve before Compile should not use the conditional providers")
+		}
+
+		report, err := c.Compile(Env{Profiles: []string{profile}, Vars: map[string]string{"REGION": "eu"}})
+		if err != nil {
+			t.Fatalf("Compile returned error: %v", err)
+		}
+		gateway, err := Resolve[TestGateway](c)
+		if err != nil {
+			t.Fatalf("Resolve returned error: %v", err)
+		}
+		if got := gateway.Charge(1); got != want {
+			t.Errorf("Resolve with profile %s returned the %s gateway, want %s", profile, got, want)
+		}
+		if len(report.Active) != 2 || len(report.Skipped) != 1 || report.Profiles[0] != profile {
+			t.Errorf("Compile returned the report:\n%s", report)
+		}
+	}
+
+	// Test the report
+	c := newTestGatewayContainer(t)
+	report, err := c.Compile(Env{Profiles: []string{"dev"}})
+	if err != nil {
+		t.Fatalf("Compile returned error: %v", err)
+	}
+	want := "active profiles: dev\n+ oop.TestGateway (profile dev|test)\n- *oop.TestRealGateway (profile prod)\n- *oop.TestDB (condition)\n"
+	if report.String() != want {
+		t.Errorf("String returned:\n%s\nwant:\n%s", report, want)
+	}
+
+	// Test that a container is compiled once
+	if _, err := c.Compile(Env{}); err == nil {
+		t.Error("Compile of a compiled container should return an error")
+	}
+	if err := c.Profile("dev").Provide(func() *TestRepo { return nil }); err == nil {
+		t.Error("Provide after Compile should return an error")
+	}
+}
+
+// TestContainerCompileErrors tests the dependency checks of Compile
+func TestContainerCompileErrors(t *testing.T) {
+	c := newTestGatewayContainer(t)
+	_, err := c.Compile(Env{Vars: map[string]string{"REGION": "eu"}})
+	if err == nil || !strings.Contains(err.Error(), "no provider for oop.TestGateway (required by *oop.TestDB)") {
+		t.Errorf("Compile returned %v, want a missing provider error", err)
+	}
+
+	c = NewContainer()
+	if err := c.Provide(func() TestGateway { return &TestMockGateway{} }); err != nil {
+		t.Fatalf("Provide returned error: %v", err)
+	}
+	if err := c.Profile("dev").Provide(func() TestGateway { return &TestRealGateway{} }); err != nil {
+		t.Fatalf("Provide returned error: %v", err)
+	}
+	if _, err := c.Compile(Env{Profiles: []string{"dev"}}); err == nil {
+		t.Error("Compile with two providers of the same type should return an error")
+	}
+	if err := c.When(nil).Provide(func() *TestRepo { return nil }); err == nil {
+		t.Error("Provide with a nil condition should return an error")
+	}
+}
+
+// TestOSEnv tests reading the environment of the process
+func TestOSEnv(t *testing.T) {
+	t.Setenv("OOP_TEST_PROFILES", "dev, eu,")
+	env := OSEnv("OOP_TEST_PROFILES")
+	if len(env.Profiles) != 2 || !env.HasProfile("dev") || !env.HasProfile("eu") || env.HasProfile("prod") {
+		t.Errorf("OSEnv returned the profiles %q", env.Profiles)
+	}
+	if env.Vars["OOP_TEST_PROFILES"] != "dev, eu," {
+		t.Errorf("OSEnv returned the variables %v", env.Vars)
+	}
+}
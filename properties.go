@@ -0,0 +1,270 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Property is a named value of a class accessed through a getter and an optional setter.
+type Property struct {
+	Name string       // Name of the property.
+	Type reflect.Type // Type of the property value.
+
+	getter reflect.Value // func(*Class) T
+	setter reflect.Value // func(*Class, T) or func(*Class, T) error; invalid for read-only properties.
+}
+
+// ReadOnly reports whether the property has no setter.
+func (p *Property) ReadOnly() bool {
+	return !p.setter.IsValid()
+}
+
+// DefineProperty defines a property of a class.
+// The getter must be a func(*Class) T, and the setter, which may be nil for a
+// read-only property, a func(*Class, T) or func(*Class, T) error.
+// The class is registered if it is not already.
+// Example: oop.DefineProperty(reflect.TypeOf(Dog{}), "Title", func(d *Dog) string { return "Sir " + d.Name }, nil)
+func DefineProperty(classType reflect.Type, name string, getter, setter interface{}) error {
+	if classType == nil {
+		return fmt.Errorf("classType cannot be nil")
+	}
+	if name == "" {
+		return fmt.Errorf("property name cannot be empty")
+	}
+
+	classType = classElem(classType)
+	recvType := reflect.PointerTo(classType)
+
+	gv := reflect.ValueOf(getter)
+	if gv.Kind() != reflect.Func || gv.IsNil() {
+		return fmt.Errorf("getter of property %s must be a function, got %T", name, getter)
+	}
+	gt := gv.Type()
+	if gt.NumIn() != 1 || gt.In(0) != recvType || gt.NumOut() != 1 {
+		return fmt.Errorf("getter of property %s must be a func(%s) T", name, recvType)
+	}
+
+	property := &Property{Name: name, Type: gt.Out(0), getter: gv}
+
+	if setter != nil {
+		sv := reflect.ValueOf(setter)
+		if sv.Kind() != reflect.Func || sv.IsNil() {
+			return fmt.Errorf("setter of property %s must be a function, got %T", name, setter)
+		}
+		st := sv.Type()
+		validOut := st.NumOut() == 0 || (st.NumOut() == 1 && st.Out(0) == errorType)
+		if st.NumIn() != 2 || st.In(0) != recvType || st.In(1) != property.Type || !validOut {
+			return fmt.Errorf("setter of property %s must be a func(%s, %s) with an optional error result", name, recvType, property.Type)
+		}
+		property.setter = sv
+	}
+
+	info, err := registeredClass(classType)
+	if err != nil {
+		return err
+	}
+
+	info.mu.Lock()
+	defer info.mu.Unlock()
+
+	if info.properties == nil {
+		info.properties = map[string]*Property{}
+	}
+	info.properties[name] = property
+	return nil
+}
+
+// GetProp returns the value of a property of the object.
+// Properties defined with DefineProperty on the class or its ancestors take
+// precedence; otherwise the exported field with that name is read.
+// Example: title, err := dogObj.GetProp("Title")
+func (o *ObjectWrapper) GetProp(name string) (any, error) {
+	property, recv, err := o.property(name)
+	if err != nil {
+		return nil, err
+	}
+	if property != nil {
+		return property.getter.Call([]reflect.Value{recv})[0].Interface(), nil
+	}
+
+	target, err := o.structValue()
+	if err != nil {
+		return nil, err
+	}
+	sf, ok := target.Type().FieldByName(name)
+	if !ok {
+		return nil, fmt.Errorf("property %s not found on %s", name, target.Type())
+	}
+	field, err := target.FieldByIndexErr(sf.Index)
+	if err != nil {
+		return nil, fmt.Errorf("property %s on %s cannot be read: %w", name, target.Type(), err)
+	}
+	if !field.CanInterface() {
+		return nil, fmt.Errorf("property %s not found on %s", name, target.Type())
+	}
+	return field.Interface(), nil
+}
+
+// SetProp sets the value of a property of the object and notifies the
+// OnPropertyChanged subscribers if the value changed.
+// Values are converted like SetFields values. Properties defined with
+// DefineProperty take precedence over exported fields. A change whose setter
+// returns an error is undone, and so is one that violates a unique index, see
+// CreateIndex, which is reported as a *UniqueViolationError.
+// Example: err := dogObj.SetProp("Name", "Rex")
+func (o *ObjectWrapper) SetProp(name string, value any) error {
+	property, recv, err := o.property(name)
+	if err != nil {
+		return err
+	}
+	if property == nil {
+		return o.setFieldProp(name, value)
+	}
+
+	if property.ReadOnly() {
+		return fmt.Errorf("property %s is read-only", name)
+	}
+	converted, err := fieldValue(property.Type, value)
+	if err != nil {
+		return fmt.Errorf("property %s: %w", name, err)
+	}
+
+	o.mu.Lock()
+	if err := o.checkMutable(); err != nil {
+		o.mu.Unlock()
+		return err
+	}
+	old := property.getter.Call([]reflect.Value{recv})[0].Interface()
+	write := func() error {
+		out := property.setter.Call([]reflect.Value{recv, converted})
+		if len(out) == 1 && !out[0].IsNil() {
+			return out[0].Interface().(error)
+		}
+		return nil
+	}
+	if target, structErr := o.structValue(); structErr == nil {
+		err = o.indexedWrite(target, nil, write) // Rolls back the values a failing setter left.
+	} else {
+		err = write()
+	}
+	if err != nil {
+		o.mu.Unlock()
+		return err
+	}
+	current := property.getter.Call([]reflect.Value{recv})[0].Interface()
+	o.mu.Unlock()
+
+	o.firePropertyChanged(name, old, current)
+	return nil
+}
+
+// OnPropertyChanged registers a function that is called after SetProp changed a property.
+// Example: dogObj.OnPropertyChanged(func(name string, old, new any) { log.Println(name, old, "->", new) })
+func (o *ObjectWrapper) OnPropertyChanged(fn func(name string, old, new any)) {
+	o.hooksMu.Lock()
+	defer o.hooksMu.Unlock()
+	o.propertyHooks = append(o.propertyHooks, fn)
+}
+
+// setFieldProp sets an exported field as a property.
+func (o *ObjectWrapper) setFieldProp(name string, value any) error {
+	o.mu.Lock()
+	if err := o.checkMutable(); err != nil {
+		o.mu.Unlock()
+		return err
+	}
+	target, err := o.structValue()
+	if err != nil {
+		o.mu.Unlock()
+		return err
+	}
+
+	sf, ok := target.Type().FieldByName(name)
+	if !ok {
+		o.mu.Unlock()
+		return fmt.Errorf("property %s not found on %s", name, target.Type())
+	}
+	field, err := target.FieldByIndexErr(sf.Index)
+	if err != nil {
+		o.mu.Unlock()
+		return fmt.Errorf("property %s on %s cannot be set: %w", name, target.Type(), err)
+	}
+	if !field.CanSet() {
+		o.mu.Unlock()
+		return fmt.Errorf("property %s not found on %s", name, target.Type())
+	}
+	converted, err := fieldValue(field.Type(), value)
+	if err != nil {
+		o.mu.Unlock()
+		return fmt.Errorf("property %s: %w", name, err)
+	}
+
+	old := field.Interface()
+	err = o.indexedWrite(target, pendingField(sf.Index, converted), func() error {
+		setField(target, sf.Index, converted)
+		return nil
+	})
+	if err != nil {
+		o.mu.Unlock()
+		return err
+	}
+	current := field.Interface()
+	o.mu.Unlock()
+
+	o.firePropertyChanged(name, old, current)
+	return nil
+}
+
+// property finds the named property on the object's class or its ancestors and
+// returns it with the receiver to call it on. It returns a nil property if the
+// class defines none with that name.
+func (o *ObjectWrapper) property(name string) (*Property, reflect.Value, error) {
+	o.klassMu.RLock()
+	klass := o.klass
+	o.klassMu.RUnlock()
+	if klass == nil || klass.Class == nil {
+		return nil, reflect.Value{}, fmt.Errorf("object is not initialized")
+	}
+
+	recv := reflect.ValueOf(klass.Class)
+	if recv.Kind() != reflect.Ptr {
+		return nil, reflect.Value{}, nil
+	}
+
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	for info := klass.Header.Info; info != nil; info = info.Parent {
+		info.mu.Lock()
+		property := info.properties[name]
+		info.mu.Unlock()
+		if property == nil {
+			continue
+		}
+
+		if info != klass.Header.Info {
+			var ok bool
+			if recv, ok = superReceiver(recv, info.Type); !ok {
+				return nil, reflect.Value{}, fmt.Errorf("property %s of %s cannot be used on %T: %s is not embedded", name, info.Type, klass.Class, info.Type)
+			}
+		}
+		return property, recv, nil
+	}
+
+	return nil, reflect.Value{}, nil
+}
+
+// firePropertyChanged notifies the OnPropertyChanged subscribers if the value changed.
+func (o *ObjectWrapper) firePropertyChanged(name string, old, new any) {
+	if reflect.DeepEqual(old, new) {
+		return
+	}
+
+	o.hooksMu.Lock()
+	hooks := o.propertyHooks
+	o.hooksMu.Unlock()
+
+	for _, fn := range hooks {
+		fn(name, old, new)
+	}
+}
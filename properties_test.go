@@ -0,0 +1,127 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// TestTemperature is a test class with computed properties
+type TestTemperature struct {
+	Celsius float64
+	Label   string
+}
+
+// TestThermostat is a test class extending TestTemperature
+type TestThermostat struct {
+	TestTemperature
+}
+
+// TestProperties tests the property system
+func TestProperties(t *testing.T) {
+	tempType := reflect.TypeOf(TestTemperature{})
+
+	err := DefineProperty(tempType, "Fahrenheit",
+		func(t *TestTemperature) float64 { return t.Celsius*9/5 + 32 },
+		func(t *TestTemperature, f float64) error {
+			if f < -459.67 {
+				return fmt.Errorf("below absolute zero")
+			}
+			t.Celsius = (f - 32) * 5 / 9
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("DefineProperty returned error: %v", err)
+	}
+	err = DefineProperty(tempType, "Kelvin", func(t *TestTemperature) float64 { return t.Celsius + 273.15 }, nil)
+	if err != nil {
+		t.Fatalf("DefineProperty returned error: %v", err)
+	}
+
+	obj := NewObjectFactory().CreateObject(&TestTemperature{Celsius: 100})
+	defer obj.Destroy()
+
+	var changes []string
+	obj.OnPropertyChanged(func(name string, old, new any) {
+		changes = append(changes, fmt.Sprintf("%s:%v->%v", name, old, new))
+	})
+
+	// Test getters
+	if f, err := obj.GetProp("Fahrenheit"); err != nil || f != 212.0 {
+		t.Errorf("GetProp returned %v, %v, want 212", f, err)
+	}
+	if c, err := obj.GetProp("Celsius"); err != nil || c != 100.0 {
+		t.Errorf("GetProp returned %v, %v for a field, want 100", c, err)
+	}
+	if _, err := obj.GetProp("Unknown"); err == nil {
+		t.Error("GetProp should return error for unknown properties")
+	}
+
+	// Test setters
+	if err := obj.SetProp("Fahrenheit", 32); err != nil {
+		t.Errorf("SetProp returned error: %v", err)
+	}
+	if err := obj.SetProp("Fahrenheit", -500); err == nil {
+		t.Error("SetProp should return the error of the setter")
+	}
+	if err := obj.SetProp("Kelvin", 0); err == nil {
+		t.Error("SetProp should return error for read-only properties")
+	}
+	if err := obj.SetProp("Label", "freezing"); err != nil {
+		t.Errorf("SetProp returned error for a field: %v", err)
+	}
+	if err := obj.SetProp("Label", "freezing"); err != nil {
+		t.Errorf("SetProp returned error for a field: %v", err)
+	}
+	if err := obj.SetProp("Label", 42); err == nil {
+		t.Error("SetProp should return error for values of the wrong type")
+	}
+
+	want := []string{"Fahrenheit:212->32", "Label:->freezing"}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Changes are %v, want %v", changes, want)
+	}
+
+	// Test properties inherited from a parent class
+	if err := Extend(reflect.TypeOf(TestThermostat{}), tempType); err != nil {
+		t.Fatalf("Extend returned error: %v", err)
+	}
+	thermostat := NewObjectFactory().CreateObject(&TestThermostat{TestTemperature{Celsius: 0}})
+	defer thermostat.Destroy()
+	if k, err := thermostat.GetProp("Kelvin"); err != nil || k != 273.15 {
+		t.Errorf("GetProp returned %v, %v for an inherited property, want 273.15", k, err)
+	}
+
+	// Test invalid definitions
+	if err := DefineProperty(tempType, "Bad", func(t TestTemperature) int { return 0 }, nil); err == nil {
+		t.Error("DefineProperty should return error for a getter with a value receiver")
+	}
+	if err := DefineProperty(tempType, "Bad", func(t *TestTemperature) int { return 0 }, func(t *TestTemperature, s string) {}); err == nil {
+		t.Error("DefineProperty should return error for a setter of another type")
+	}
+	if err := DefineProperty(tempType, "", func(t *TestTemperature) int { return 0 }, nil); err == nil {
+		t.Error("DefineProperty should return error for an empty name")
+	}
+}
+
+// TestPropFieldBehindNil tests fields promoted through a nil embedded pointer
+func TestPropFieldBehindNil(t *testing.T) {
+	gauge := &TestGauge{}
+	obj := NewObjectFactory().CreateObject(gauge)
+	defer obj.Destroy()
+
+	if _, err := obj.GetProp("Label"); err == nil {
+		t.Error("GetProp should return error for a field behind a nil pointer")
+	}
+	if err := obj.SetProp("Label", "x"); err == nil {
+		t.Error("SetProp should return error for a field behind a nil pointer")
+	}
+
+	gauge.TestGaugeMeta = &TestGaugeMeta{}
+	if err := obj.SetProp("Label", "x"); err != nil {
+		t.Errorf("SetProp returned error: %v", err)
+	}
+	if label, err := obj.GetProp("Label"); err != nil || label != "x" {
+		t.Errorf("GetProp returned %v, %v, want x", label, err)
+	}
+}
@@ -0,0 +1,133 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Ref refers to an object held by another factory, possibly in another
+// process, by the IDs of the factory and of the object. It is a plain value,
+// so it can be stored in fields and survives MarshalPolymorphic and Backup;
+// the object is only looked up when the reference is resolved, through the
+// resolver registered for the factory, see RegisterResolver.
+type Ref struct {
+	FactoryID string `json:"factory"`
+	ObjectID  string `json:"object"`
+}
+
+// Resolver returns the object with the given ID of a factory, or an error if
+// it cannot be found or loaded.
+type Resolver func(objectID string) (any, error)
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = map[string]Resolver{}
+)
+
+// RegisterResolver registers the resolver of the references to a factory.
+// It returns an error if the factory ID is empty or already has a resolver.
+// Example: oop.RegisterResolver("crm", func(id string) (any, error) { return crm.Load(id) })
+func RegisterResolver(factoryID string, resolver Resolver) error {
+	if factoryID == "" {
+		return fmt.Errorf("factoryID cannot be empty")
+	}
+	if resolver == nil {
+		return fmt.Errorf("resolver cannot be nil")
+	}
+
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	if _, ok := resolvers[factoryID]; ok {
+		return fmt.Errorf("resolver for factory %s is already registered", factoryID)
+	}
+	resolvers[factoryID] = resolver
+	return nil
+}
+
+// UnregisterResolver removes the resolver of a factory, if any.
+// Example: oop.UnregisterResolver("crm")
+func UnregisterResolver(factoryID string) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	delete(resolvers, factoryID)
+}
+
+// IndexResolver returns a resolver that finds objects by the value of a string
+// field with a Unique index, see CreateIndex, so a factory can serve
+// references to its objects by a natural ID. Soft-deleted objects are not found.
+// Example: oop.RegisterResolver("users", oop.IndexResolver(reflect.TypeOf(User{}), "Email"))
+func IndexResolver(classType reflect.Type, field string) Resolver {
+	return func(objectID string) (any, error) {
+		objs, err := FindByIndex(classType, field, objectID)
+		if err != nil {
+			return nil, err
+		}
+		if len(objs) == 0 {
+			return nil, nil
+		}
+		return objs[0].GetUnderlyingObject(), nil
+	}
+}
+
+// IsZero reports whether the reference refers to nothing.
+func (r Ref) IsZero() bool {
+	return r == Ref{}
+}
+
+// String returns the reference as factory/object.
+func (r Ref) String() string {
+	return r.FactoryID + "/" + r.ObjectID
+}
+
+// Resolve returns the object the reference refers to, looked up by the
+// resolver of its factory on each call; resolvers that load objects from
+// elsewhere may cache them. It returns an error if the reference is zero, the
+// factory has no resolver, or the resolver fails or finds no object.
+// Example: customer, err := order.Customer.Resolve()
+func (r Ref) Resolve() (any, error) {
+	if r.IsZero() {
+		return nil, fmt.Errorf("cannot resolve a zero Ref")
+	}
+
+	resolversMu.RLock()
+	resolver, ok := resolvers[r.FactoryID]
+	resolversMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no resolver registered for factory %s", r.FactoryID)
+	}
+
+	obj, err := resolver(r.ObjectID)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", r, err)
+	}
+	if obj == nil {
+		return nil, fmt.Errorf("object %s not found", r)
+	}
+	return obj, nil
+}
+
+// ResolveRef resolves the reference held by a Ref field of the object, see
+// Ref.Resolve. The field is read under the object's lock, and the resolver is
+// called without holding it.
+// Example: customer, err := orderObj.ResolveRef("Customer")
+func (o *ObjectWrapper) ResolveRef(field string) (any, error) {
+	o.mu.Lock()
+	target, err := o.structValue()
+	if err != nil {
+		o.mu.Unlock()
+		return nil, err
+	}
+	v := target.FieldByName(field)
+	if !v.IsValid() || !v.CanInterface() {
+		o.mu.Unlock()
+		return nil, fmt.Errorf("field %s not found on %s", field, target.Type())
+	}
+	ref, ok := v.Interface().(Ref)
+	o.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("field %s on %s is not a Ref", field, target.Type())
+	}
+	return ref.Resolve()
+}
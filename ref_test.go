@@ -0,0 +1,109 @@
+package oop
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestRefCustomer is a test class referred to from another factory
+type TestRefCustomer struct {
+	ID   string
+	Name string
+}
+
+// TestRefOrder is a test class holding a reference to an object of another factory
+type TestRefOrder struct {
+	Number   string
+	Customer Ref
+}
+
+// TestRefResolve tests resolving references through the resolver registry
+func TestRefResolve(t *testing.T) {
+	customerType := reflect.TypeOf(TestRefCustomer{})
+	if err := CreateIndex(customerType, "ID", Unique); err != nil {
+		t.Fatalf("CreateIndex returned error: %v", err)
+	}
+	if err := RegisterResolver("crm", IndexResolver(customerType, "ID")); err != nil {
+		t.Fatalf("RegisterResolver returned error: %v", err)
+	}
+	defer UnregisterResolver("crm")
+
+	crm := NewObjectFactory()
+	ann := crm.CreateObject(&TestRefCustomer{ID: "c1", Name: "Ann"})
+	defer ann.Destroy()
+
+	orders := NewObjectFactory()
+	order := orders.CreateObject(&TestRefOrder{Number: "o1", Customer: Ref{FactoryID: "crm", ObjectID: "c1"}})
+	defer order.Destroy()
+
+	customer, err := order.ResolveRef("Customer")
+	if err != nil {
+		t.Fatalf("ResolveRef returned error: %v", err)
+	}
+	if customer != ann.GetUnderlyingObject() {
+		t.Errorf("ResolveRef returned %v, want ann", customer)
+	}
+
+	// Test that references survive serialization
+	if _, err := Register(reflect.TypeOf(TestRefOrder{})); err != nil {
+		t.Fatalf("Register returned error: %v", err)
+	}
+	data, err := MarshalPolymorphic(order.GetUnderlyingObject())
+	if err != nil {
+		t.Fatalf("MarshalPolymorphic returned error: %v", err)
+	}
+	decoded, err := UnmarshalPolymorphic(data)
+	if err != nil {
+		t.Fatalf("UnmarshalPolymorphic returned error: %v", err)
+	}
+	if customer, err := decoded.(*TestRefOrder).Customer.Resolve(); err != nil || customer != ann.GetUnderlyingObject() {
+		t.Errorf("Resolve after a round trip returned %v, %v", customer, err)
+	}
+
+	// Test failures
+	failures := map[string]Ref{
+		"zero":         {},
+		"unregistered": {FactoryID: "billing", ObjectID: "c1"},
+		"missing":      {FactoryID: "crm", ObjectID: "c2"},
+	}
+	for name, ref := range failures {
+		if _, err := ref.Resolve(); err == nil {
+			t.Errorf("Resolve of a %s Ref should return an error", name)
+		}
+	}
+	if _, err := order.ResolveRef("Number"); err == nil {
+		t.Error("ResolveRef of a field that is not a Ref should return an error")
+	}
+	if _, err := order.ResolveRef("Missing"); err == nil {
+		t.Error("ResolveRef of a missing field should return an error")
+	}
+}
+
+// TestRegisterResolver tests the resolver registry
+func TestRegisterResolver(t *testing.T) {
+	errUnavailable := errors.New("unavailable")
+	resolver := func(objectID string) (any, error) { return nil, errUnavailable }
+	if err := RegisterResolver("remote", resolver); err != nil {
+		t.Fatalf("RegisterResolver returned error: %v", err)
+	}
+	defer UnregisterResolver("remote")
+
+	if err := RegisterResolver("remote", resolver); err == nil {
+		t.Error("RegisterResolver of a registered factory should return an error")
+	}
+	if err := RegisterResolver("", resolver); err == nil {
+		t.Error("RegisterResolver with an empty factory ID should return an error")
+	}
+	if err := RegisterResolver("other", nil); err == nil {
+		t.Error("RegisterResolver with a nil resolver should return an error")
+	}
+
+	ref := Ref{FactoryID: "remote", ObjectID: "42"}
+	if _, err := ref.Resolve(); !errors.Is(err, errUnavailable) {
+		t.Errorf("Resolve returned %v, want the resolver's error", err)
+	}
+	if ref.String() != "remote/42" || ref.IsZero() {
+		t.Errorf("String returned %s, IsZero returned %v", ref, ref.IsZero())
+	}
+}
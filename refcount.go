@@ -0,0 +1,77 @@
+package oop
+
+import "fmt"
+
+// Retain adds a reference to the object, for objects shared by several owners
+// such as connections or buffers. The creator of the object holds the first
+// reference; each owner releases its reference with Release, and the object
+// is destroyed when the last one is released. It returns an error if the
+// object is destroyed.
+// Example: if err := connObj.Retain(); err == nil { defer connObj.Release() }
+func (o *ObjectWrapper) Retain() error {
+	for {
+		refs := o.refs.Load()
+		if refs < 0 || o.State() == StateDestroyed {
+			return fmt.Errorf("object is destroyed")
+		}
+		if o.refs.CompareAndSwap(refs, refs+1) {
+			return nil
+		}
+	}
+}
+
+// Release releases a reference to the object and destroys it, running Deinit,
+// when no references are left. It returns an error if the object is already
+// destroyed. Destroy also releases a reference while other owners hold one.
+// Example: defer connObj.Release()
+func (o *ObjectWrapper) Release() error {
+	for {
+		refs := o.refs.Load()
+		if refs < 0 || o.State() == StateDestroyed {
+			return fmt.Errorf("object is destroyed")
+		}
+		if o.refs.CompareAndSwap(refs, refs-1) {
+			if refs == 0 {
+				o.Destroy()
+			}
+			return nil
+		}
+	}
+}
+
+// dropRetained drops a reference taken with Retain, if any is left, and
+// reports whether it did, so Destroy leaves the object to its other owners.
+func (o *ObjectWrapper) dropRetained() bool {
+	for {
+		refs := o.refs.Load()
+		if refs <= 0 {
+			return false
+		}
+		if o.refs.CompareAndSwap(refs, refs-1) {
+			return true
+		}
+	}
+}
+
+// RefCount returns the number of references to the object, see Retain, or 0
+// if it is destroyed.
+func (o *ObjectWrapper) RefCount() int64 {
+	refs := o.refs.Load()
+	if refs < 0 || o.State() == StateDestroyed {
+		return 0
+	}
+	return refs + 1
+}
+
+// Borrow returns the underlying object for temporary use without taking a
+// reference, or an error if the object is destroyed. Unlike
+// GetUnderlyingObject, which returns nil then, it lets callers tell a
+// released resource apart; owners that keep the object should Retain it.
+// Example: conn, err := connObj.Borrow()
+func (o *ObjectWrapper) Borrow() (interface{}, error) {
+	class := o.class()
+	if class == nil {
+		return nil, fmt.Errorf("object is destroyed")
+	}
+	return class, nil
+}
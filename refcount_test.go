@@ -0,0 +1,87 @@
+package oop
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestSharedConn is a test class shared by several owners
+type TestSharedConn struct {
+	Addr string
+}
+
+// TestRetainRelease tests that shared objects are destroyed by their last owner
+func TestRetainRelease(t *testing.T) {
+	info, err := registeredClass(reflect.TypeOf(TestSharedConn{}))
+	if err != nil {
+		t.Fatalf("registeredClass returned error: %v", err)
+	}
+	var destroyed atomic.Int32
+	info.AddHook(HookDestroy, func(obj any) { destroyed.Add(1) })
+
+	factory := NewObjectFactory()
+	conn := factory.CreateObject(&TestSharedConn{Addr: "db:5432"})
+	if n := conn.RefCount(); n != 1 {
+		t.Errorf("RefCount returned %d, want 1", n)
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		if err := conn.Retain(); err != nil {
+			t.Fatalf("Retain returned error: %v", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Release()
+			if obj, err := conn.Borrow(); err != nil || obj.(*TestSharedConn).Addr != "db:5432" {
+				t.Errorf("Borrow returned %v, %v", obj, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := conn.RefCount(); n != 1 || destroyed.Load() != 0 {
+		t.Errorf("RefCount returned %d after %d destructions, want 1 after none", n, destroyed.Load())
+	}
+
+	// Test that the last Release destroys the object
+	if err := conn.Release(); err != nil {
+		t.Fatalf("Release returned error: %v", err)
+	}
+	if conn.State() != StateDestroyed || destroyed.Load() != 1 || conn.RefCount() != 0 {
+		t.Errorf("the object is %v after %d destructions, want destroyed once", conn.State(), destroyed.Load())
+	}
+	if err := conn.Release(); err == nil {
+		t.Error("Release should return error after the last reference is released")
+	}
+	if err := conn.Retain(); err == nil {
+		t.Error("Retain should return error after destruction")
+	}
+	if _, err := conn.Borrow(); err == nil {
+		t.Error("Borrow should return error after destruction")
+	}
+	if destroyed.Load() != 1 {
+		t.Errorf("the object was destroyed %d times, want once", destroyed.Load())
+	}
+
+	// Test that Destroy leaves a retained object to its other owners
+	other := factory.CreateObject(&TestSharedConn{Addr: "cache:6379"})
+	if err := other.Retain(); err != nil {
+		t.Fatalf("Retain returned error: %v", err)
+	}
+	other.Destroy()
+	if other.State() == StateDestroyed || other.RefCount() != 1 || destroyed.Load() != 1 {
+		t.Errorf("Destroy left the object %v with %d references, want it alive with 1", other.State(), other.RefCount())
+	}
+	if obj, err := other.Borrow(); err != nil || obj.(*TestSharedConn).Addr != "cache:6379" {
+		t.Errorf("Borrow returned %v, %v after Destroy of a retained object", obj, err)
+	}
+	if err := other.Release(); err != nil {
+		t.Fatalf("Release returned error: %v", err)
+	}
+	if other.State() != StateDestroyed || destroyed.Load() != 2 {
+		t.Errorf("the objects were destroyed %d times, want twice", destroyed.Load())
+	}
+}
@@ -0,0 +1,140 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// RegisterOption configures how a class is registered.
+type RegisterOption func(*registerOptions)
+
+// registerOptions holds the options of a single Register call.
+type registerOptions struct {
+	name string
+}
+
+// WithName registers a class under a custom name instead of its Go type name.
+// Example: oop.Register(reflect.TypeOf(Dog{}), oop.WithName("animals.Dog"))
+func WithName(name string) RegisterOption {
+	return func(o *registerOptions) {
+		o.name = name
+	}
+}
+
+// classRegistry holds all registered classes.
+type classRegistry struct {
+	mu      sync.RWMutex
+	byName  map[string]*ClassInfo
+	byType  map[reflect.Type]*ClassInfo
+	version atomic.Uint64 // Incremented by every registration.
+}
+
+var registry = &classRegistry{
+	byName: map[string]*ClassInfo{},
+	byType: map[reflect.Type]*ClassInfo{},
+}
+
+// Register registers a class so it can be looked up and created by name.
+// Classes are registered under their Go type name (e.g. "mypkg.Dog") unless
+// WithName is given. Pointer types are registered as their element type.
+// Registering the same class under the same name again returns the existing ClassInfo.
+// Example: oop.Register(reflect.TypeOf(Dog{}))
+func Register(classType reflect.Type, opts ...RegisterOption) (*ClassInfo, error) {
+	if classType == nil {
+		return nil, fmt.Errorf("classType cannot be nil")
+	}
+	classType = classElem(classType)
+
+	options := registerOptions{name: classType.String()}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.name == "" {
+		return nil, fmt.Errorf("class name cannot be empty")
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if existing, ok := registry.byName[options.name]; ok {
+		if existing.Type != classType {
+			return nil, fmt.Errorf("class name %s is already registered for %s", options.name, existing.Type)
+		}
+		return existing, nil
+	}
+	if existing, ok := registry.byType[classType]; ok {
+		return nil, fmt.Errorf("class %s is already registered as %s", classType, existing.Name)
+	}
+
+	info := newClassInfo(classType)
+	info.Name = options.name
+	registry.byName[info.Name] = info
+	registry.byType[classType] = info
+	registry.version.Add(1)
+
+	return info, nil
+}
+
+// registeredClass returns the ClassInfo of a class, registering the class under
+// its default name if it is not registered yet.
+func registeredClass(classType reflect.Type) (*ClassInfo, error) {
+	if info, ok := LookupType(classType); ok {
+		return info, nil
+	}
+	return Register(classType)
+}
+
+// Lookup returns the ClassInfo of the class registered under the given name.
+func Lookup(name string) (*ClassInfo, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	info, ok := registry.byName[name]
+	return info, ok
+}
+
+// LookupType returns the ClassInfo of a registered class type.
+func LookupType(classType reflect.Type) (*ClassInfo, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	info, ok := registry.byType[classElem(classType)]
+	return info, ok
+}
+
+// ImplementationsOf returns the registered classes whose instances, or pointers
+// to them, implement the interface type iface, sorted by class name.
+// It returns nil if iface is not an interface type.
+// Example: handlers := oop.ImplementationsOf(oop.Iface[Handler]())
+func ImplementationsOf(iface reflect.Type) []*ClassInfo {
+	if iface == nil || iface.Kind() != reflect.Interface {
+		return nil
+	}
+
+	registry.mu.RLock()
+	var classes []*ClassInfo
+	for _, info := range registry.byName {
+		if Implements(info.Type, iface) {
+			classes = append(classes, info)
+		}
+	}
+	registry.mu.RUnlock()
+
+	sort.Slice(classes, func(i, j int) bool { return classes[i].Name < classes[j].Name })
+	return classes
+}
+
+// NewByName creates a new instance of the class registered under the given name.
+// The instance is initialized with zero values.
+// Example: klass, err := oop.NewByName("mypkg.Dog")
+func NewByName(name string) (*Klass, error) {
+	info, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("class %s is not registered", name)
+	}
+
+	return New(nil, info.Type, nil), nil
+}
@@ -0,0 +1,178 @@
+package oop
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// TestRegisteredDog is a test struct registered with the class registry
+type TestRegisteredDog struct {
+	Name string
+}
+
+// TestRegisteredCat is a test struct registered under a custom name
+type TestRegisteredCat struct {
+	Name string
+}
+
+// TestRegister tests the Register and Lookup functions
+func TestRegister(t *testing.T) {
+	dogType := reflect.TypeOf(TestRegisteredDog{})
+
+	info, err := Register(dogType)
+	if err != nil {
+		t.Fatalf("Register returned error: %v", err)
+	}
+	if info.Name != "oop.TestRegisteredDog" {
+		t.Errorf("Name is %q, want %q", info.Name, "oop.TestRegisteredDog")
+	}
+	if info.Type != dogType {
+		t.Errorf("Type is %v, want %v", info.Type, dogType)
+	}
+
+	// Test that registering again returns the same ClassInfo
+	again, err := Register(reflect.TypeOf(&TestRegisteredDog{}))
+	if err != nil {
+		t.Fatalf("Register returned error: %v", err)
+	}
+	if again != info {
+		t.Error("Register did not return the existing ClassInfo")
+	}
+
+	// Test lookup by name and type
+	found, ok := Lookup("oop.TestRegisteredDog")
+	if !ok || found != info {
+		t.Error("Lookup did not find the registered class")
+	}
+	found, ok = LookupType(dogType)
+	if !ok || found != info {
+		t.Error("LookupType did not find the registered class")
+	}
+
+	// Test that New uses the registered ClassInfo
+	klass := New(nil, dogType, nil)
+	if klass.Header.Info != info {
+		t.Error("New did not use the registered ClassInfo")
+	}
+
+	// Test with custom name
+	catInfo, err := Register(reflect.TypeOf(TestRegisteredCat{}), WithName("animals.Cat"))
+	if err != nil {
+		t.Fatalf("Register returned error: %v", err)
+	}
+	if _, ok := Lookup("animals.Cat"); !ok || catInfo.Name != "animals.Cat" {
+		t.Error("Register did not use the custom name")
+	}
+
+	// Test name conflicts
+	if _, err := Register(reflect.TypeOf(TestStruct2{}), WithName("animals.Cat")); err == nil {
+		t.Error("Register should return error for a name registered to another class")
+	}
+	if _, err := Register(dogType, WithName("animals.Dog")); err == nil {
+		t.Error("Register should return error for a class registered under another name")
+	}
+
+	// Test with nil type
+	if _, err := Register(nil); err == nil {
+		t.Error("Register should return error for nil type")
+	}
+
+	// Test lookup of unknown class
+	if _, ok := Lookup("unknown.Class"); ok {
+		t.Error("Lookup should return false for unknown class")
+	}
+}
+
+// TestNewByName tests the NewByName function
+func TestNewByName(t *testing.T) {
+	if _, err := Register(reflect.TypeOf(TestRegisteredDog{})); err != nil {
+		t.Fatalf("Register returned error: %v", err)
+	}
+
+	klass, err := NewByName("oop.TestRegisteredDog")
+	if err != nil {
+		t.Fatalf("NewByName returned error: %v", err)
+	}
+	if _, ok := klass.Class.(*TestRegisteredDog); !ok {
+		t.Errorf("NewByName created %T, want *TestRegisteredDog", klass.Class)
+	}
+
+	// Test with unknown class
+	if _, err := NewByName("unknown.Class"); err == nil {
+		t.Error("NewByName should return error for unknown class")
+	}
+}
+
+// TestPlugin is a test interface implemented by registered classes
+type TestPlugin interface {
+	PluginName() string
+}
+
+// TestAlphaPlugin implements TestPlugin through its pointer
+type TestAlphaPlugin struct{}
+
+// PluginName implements TestPlugin
+func (p *TestAlphaPlugin) PluginName() string { return "alpha" }
+
+// TestBetaPlugin implements TestPlugin through its value
+type TestBetaPlugin struct{}
+
+// PluginName implements TestPlugin
+func (p TestBetaPlugin) PluginName() string { return "beta" }
+
+// TestImplementationsOf tests the ImplementationsOf function
+func TestImplementationsOf(t *testing.T) {
+	for _, classType := range []reflect.Type{reflect.TypeOf(TestBetaPlugin{}), reflect.TypeOf(TestAlphaPlugin{}), reflect.TypeOf(TestRegisteredDog{})} {
+		if _, err := registeredClass(classType); err != nil {
+			t.Fatalf("registeredClass returned error: %v", err)
+		}
+	}
+
+	classes := ImplementationsOf(reflect.TypeOf((*TestPlugin)(nil)).Elem())
+	if len(classes) != 2 || classes[0].Type != reflect.TypeOf(TestAlphaPlugin{}) || classes[1].Type != reflect.TypeOf(TestBetaPlugin{}) {
+		t.Errorf("ImplementationsOf returned %v, want TestAlphaPlugin and TestBetaPlugin", classes)
+	}
+
+	// Test with a type that is not an interface
+	if classes := ImplementationsOf(reflect.TypeOf(TestAlphaPlugin{})); classes != nil {
+		t.Errorf("ImplementationsOf with a struct type returned %v", classes)
+	}
+}
+
+// TestConcurrentRegistered is a test struct registered from many goroutines
+type TestConcurrentRegistered struct {
+	Name string
+}
+
+// TestRegisterConcurrent tests registering and using a class from many goroutines
+func TestRegisterConcurrent(t *testing.T) {
+	classType := reflect.TypeOf(TestConcurrentRegistered{})
+
+	var wg sync.WaitGroup
+	infos := make([]*ClassInfo, 16)
+	for i := range infos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			info, err := Register(classType)
+			if err != nil {
+				t.Errorf("Register returned error: %v", err)
+				return
+			}
+			infos[i] = info
+
+			klass := New(nil, classType, nil)
+			klass.Vtable(reflect.TypeOf((*TestAnimal)(nil)).Elem())
+			IsInstanceOf(klass.Class, classType)
+			klass.Deinit()
+		}()
+	}
+	wg.Wait()
+
+	for _, info := range infos {
+		if info != infos[0] {
+			t.Fatal("Register returned different ClassInfos for the same class")
+		}
+	}
+}
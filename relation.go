@@ -0,0 +1,557 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// RelationKind is the cardinality of a relation between classes.
+type RelationKind int
+
+const (
+	HasOne    RelationKind = iota // The field points to one related object, e.g. *Profile.
+	HasMany                       // The field holds the related objects, e.g. []*Order.
+	BelongsTo                     // The field points to the owner, the inverse of HasOne or HasMany.
+)
+
+// String returns the name of the relation kind.
+func (k RelationKind) String() string {
+	switch k {
+	case HasOne:
+		return "has one"
+	case HasMany:
+		return "has many"
+	case BelongsTo:
+		return "belongs to"
+	default:
+		return "unknown"
+	}
+}
+
+// CascadeAction is what happens to related objects when their owner is destroyed.
+type CascadeAction int
+
+const (
+	CascadeDetach  CascadeAction = iota // Clear the inverse field of the related objects.
+	CascadeDestroy                      // Destroy the related objects as well.
+)
+
+// Relation is a relation between two classes defined with DefineRelation.
+type Relation struct {
+	Name    string        // Name of the field holding the related objects.
+	Kind    RelationKind  // Cardinality of the relation.
+	Owner   reflect.Type  // Class declaring the field.
+	Target  reflect.Type  // Class of the related objects.
+	Inverse string        // Field of the target pointing back to the owner, empty if none.
+	Cascade CascadeAction // What happens to the related objects when the owner is destroyed.
+
+	index   int                          // Index of the field in the owner.
+	inverse *Relation                    // Relation of the inverse field, nil if none.
+	loader  func(owner any) (any, error) // Loads the related objects on first access, nil if none.
+}
+
+// RelationOption configures a relation defined with DefineRelation.
+type RelationOption func(*Relation)
+
+// WithCascade sets what happens to the related objects when the owner is
+// destroyed, CascadeDetach by default.
+// Example: oop.DefineRelation(customerType, "Orders", oop.HasMany, orderType, "Customer", oop.WithCascade(oop.CascadeDestroy))
+func WithCascade(action CascadeAction) RelationOption {
+	return func(r *Relation) {
+		r.Cascade = action
+	}
+}
+
+// WithLoader sets a function that loads the related objects the first time
+// the relation is read with Related while its field is empty. It returns a
+// pointer to a target instance for HasOne and BelongsTo, and a slice of them
+// for HasMany.
+// Example: oop.WithLoader(func(owner any) (any, error) { return db.OrdersOf(owner.(*Customer)) })
+func WithLoader(fn func(owner any) (any, error)) RelationOption {
+	return func(r *Relation) {
+		r.loader = fn
+	}
+}
+
+// relationMu guards the fields of all relations, so both sides of a relation
+// are always updated together.
+var relationMu sync.Mutex
+
+// DefineRelation defines a relation between two classes on the exported field
+// name of ownerType: a *Target field for HasOne and BelongsTo, and a []*Target
+// field for HasMany. If inverse names a field of targetType pointing back to
+// the owner, both sides are kept consistent: relating an order to a customer
+// through Relate, SetFields, SetProp or a FieldRef also sets the customer of
+// the order, and removes it from its previous customer. The inverse relation
+// is defined as well, unless it already is. The classes are registered if
+// they are not already.
+// Example: oop.DefineRelation(reflect.TypeOf(Customer{}), "Orders", oop.HasMany, reflect.TypeOf(Order{}), "Customer")
+func DefineRelation(ownerType reflect.Type, name string, kind RelationKind, targetType reflect.Type, inverse string, opts ...RelationOption) error {
+	if ownerType == nil || targetType == nil {
+		return fmt.Errorf("ownerType and targetType cannot be nil")
+	}
+	ownerType, targetType = classElem(ownerType), classElem(targetType)
+	if kind != HasOne && kind != HasMany && kind != BelongsTo {
+		return fmt.Errorf("unknown relation kind %d", kind)
+	}
+
+	relation := &Relation{Name: name, Kind: kind, Owner: ownerType, Target: targetType, Inverse: inverse}
+	for _, opt := range opts {
+		opt(relation)
+	}
+	index, err := relationField(ownerType, name, targetType, kind == HasMany)
+	if err != nil {
+		return err
+	}
+	relation.index = index
+
+	var inverseRelation *Relation
+	if inverse != "" {
+		inverseRelation, err = inverseOf(relation)
+		if err != nil {
+			return err
+		}
+	}
+
+	ownerInfo, err := registeredClass(ownerType)
+	if err != nil {
+		return err
+	}
+	targetInfo, err := registeredClass(targetType)
+	if err != nil {
+		return err
+	}
+
+	relationMu.Lock()
+	defer relationMu.Unlock()
+
+	if inverseRelation != nil {
+		if existing, ok := targetInfo.Relation(inverse); ok && existing.Target == ownerType {
+			inverseRelation = existing
+		} else {
+			targetInfo.setRelation(inverseRelation)
+		}
+		inverseRelation.Inverse = name
+		inverseRelation.inverse = relation
+		relation.inverse = inverseRelation
+	}
+	ownerInfo.setRelation(relation)
+	return nil
+}
+
+// relationField returns the index of the field holding a relation, checking
+// that it is a *Target field, or a []*Target field if many is set.
+func relationField(ownerType reflect.Type, name string, targetType reflect.Type, many bool) (int, error) {
+	if ownerType.Kind() != reflect.Struct {
+		return 0, fmt.Errorf("class %s must be a struct", ownerType)
+	}
+	field, ok := ownerType.FieldByName(name)
+	if !ok || !field.IsExported() || len(field.Index) != 1 {
+		return 0, fmt.Errorf("field %s not found on %s", name, ownerType)
+	}
+
+	want := reflect.PointerTo(targetType)
+	if many {
+		want = reflect.SliceOf(want)
+	}
+	if field.Type != want {
+		return 0, fmt.Errorf("field %s of %s must be a %s, got %s", name, ownerType, want, field.Type)
+	}
+	return field.Index[0], nil
+}
+
+// inverseOf returns the relation of the inverse field of a relation, whose
+// kind follows from the field type.
+func inverseOf(r *Relation) (*Relation, error) {
+	field, ok := r.Target.FieldByName(r.Inverse)
+	if !ok {
+		return nil, fmt.Errorf("field %s not found on %s", r.Inverse, r.Target)
+	}
+
+	kind := BelongsTo
+	switch {
+	case field.Type.Kind() == reflect.Slice:
+		kind = HasMany
+	case r.Kind == BelongsTo:
+		kind = HasOne
+	}
+
+	index, err := relationField(r.Target, r.Inverse, r.Owner, kind == HasMany)
+	if err != nil {
+		return nil, err
+	}
+	return &Relation{Name: r.Inverse, Kind: kind, Owner: r.Target, Target: r.Owner, index: index}, nil
+}
+
+// setRelation adds or replaces a relation of the class.
+func (c *ClassInfo) setRelation(r *Relation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.relations == nil {
+		c.relations = map[string]*Relation{}
+	}
+	c.relations[r.Name] = r
+}
+
+// Relation returns the relation defined on the named field of the class.
+// Example: relation, ok := info.Relation("Orders")
+func (c *ClassInfo) Relation(name string) (*Relation, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	r, ok := c.relations[name]
+	return r, ok
+}
+
+// Relations returns the relations defined on the class, sorted by field name.
+func (c *ClassInfo) Relations() []*Relation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	relations := make([]*Relation, 0, len(c.relations))
+	for _, r := range c.relations {
+		relations = append(relations, r)
+	}
+	slices.SortFunc(relations, func(a, b *Relation) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return relations
+}
+
+// relationsOf returns the relations defined on a struct type.
+func relationsOf(t reflect.Type) []*Relation {
+	info, ok := LookupType(t)
+	if !ok {
+		return nil
+	}
+	return info.Relations()
+}
+
+// relationAt returns the relation defined on the field with the given index
+// of a struct type, or nil.
+func relationAt(t reflect.Type, index int) *Relation {
+	for _, r := range relationsOf(t) {
+		if r.index == index {
+			return r
+		}
+	}
+	return nil
+}
+
+// Relate relates the object to another one through the named relation: the
+// related object, given as a wrapper or as a pointer to the target class, is
+// appended to a HasMany field or set in a HasOne or BelongsTo field, and the
+// inverse field, if any, is updated to match.
+// Example: err := customerObj.Relate("Orders", orderObj)
+func (o *ObjectWrapper) Relate(name string, related any) error {
+	return o.updateRelation(name, related, true)
+}
+
+// Unrelate removes a related object from the named relation, and clears the
+// inverse field, if any.
+// Example: err := customerObj.Unrelate("Orders", orderObj)
+func (o *ObjectWrapper) Unrelate(name string, related any) error {
+	return o.updateRelation(name, related, false)
+}
+
+// updateRelation implements Relate and Unrelate.
+func (o *ObjectWrapper) updateRelation(name string, related any, add bool) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if err := o.checkMutable(); err != nil {
+		return err
+	}
+	r, owner, err := o.relation(name)
+	if err != nil {
+		return err
+	}
+	b, err := relatedValue(related, r.Target)
+	if err != nil {
+		return err
+	}
+
+	relationMu.Lock()
+	defer relationMu.Unlock()
+
+	field := owner.Elem().Field(r.index)
+	if !add {
+		if contains(field, b) {
+			removeFrom(field, b)
+			r.detachInverse(owner, b)
+		}
+		return nil
+	}
+
+	if contains(field, b) {
+		return nil
+	}
+	if r.Kind != HasMany && !field.IsNil() {
+		r.detachInverse(owner, field)
+	}
+	addTo(field, b)
+	r.attachInverse(owner, b)
+	return nil
+}
+
+// Related returns the value of the named relation field of the object: a
+// pointer for HasOne and BelongsTo, and a slice for HasMany. If the field is
+// empty and the relation has a loader, see WithLoader, the loader is called
+// and its result is related to the object first.
+// Example: orders, err := customerObj.Related("Orders")
+func (o *ObjectWrapper) Related(name string) (any, error) {
+	r, owner, err := o.relation(name)
+	if err != nil {
+		return nil, err
+	}
+
+	relationMu.Lock()
+	field := owner.Elem().Field(r.index)
+	empty := field.IsNil()
+	relationMu.Unlock()
+
+	if empty && r.loader != nil {
+		if err := o.load(r, owner); err != nil {
+			return nil, err
+		}
+	}
+
+	relationMu.Lock()
+	defer relationMu.Unlock()
+	return field.Interface(), nil
+}
+
+// load relates the objects returned by the loader of a relation to the object.
+func (o *ObjectWrapper) load(r *Relation, owner reflect.Value) error {
+	loaded, err := r.loader(owner.Interface())
+	if err != nil {
+		return fmt.Errorf("loading relation %s: %w", r.Name, err)
+	}
+
+	v := reflect.ValueOf(loaded)
+	if r.Kind != HasMany {
+		if !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+			return nil
+		}
+		return o.Relate(r.Name, loaded)
+	}
+
+	if v.IsValid() && v.Kind() != reflect.Slice {
+		return fmt.Errorf("loader of relation %s must return a slice, got %T", r.Name, loaded)
+	}
+	for i := 0; v.IsValid() && i < v.Len(); i++ {
+		if err := o.Relate(r.Name, v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+
+	relationMu.Lock()
+	defer relationMu.Unlock()
+	if field := owner.Elem().Field(r.index); field.IsNil() {
+		field.Set(reflect.MakeSlice(field.Type(), 0, 0)) // Loaded, even if there is nothing.
+	}
+	return nil
+}
+
+// relation returns the named relation of the object's class with a pointer to the object.
+func (o *ObjectWrapper) relation(name string) (*Relation, reflect.Value, error) {
+	target, err := o.structValue()
+	if err != nil {
+		return nil, reflect.Value{}, err
+	}
+	info, ok := LookupType(target.Type())
+	if !ok {
+		return nil, reflect.Value{}, fmt.Errorf("relation %s not found on %s", name, target.Type())
+	}
+	r, ok := info.Relation(name)
+	if !ok {
+		return nil, reflect.Value{}, fmt.Errorf("relation %s not found on %s", name, target.Type())
+	}
+	return r, target.Addr(), nil
+}
+
+// relatedValue returns a related object, given as a wrapper or as a pointer
+// to the target class, as a pointer value.
+func relatedValue(related any, targetType reflect.Type) (reflect.Value, error) {
+	if obj, ok := related.(*ObjectWrapper); ok {
+		related = obj.GetUnderlyingObject()
+	}
+
+	v := reflect.ValueOf(related)
+	if !v.IsValid() || v.Type() != reflect.PointerTo(targetType) || v.IsNil() {
+		return reflect.Value{}, fmt.Errorf("related object must be a non-nil *%s, got %T", targetType, related)
+	}
+	return v, nil
+}
+
+// setField sets a field of the struct target, keeping the inverse side of a
+// relation defined on the field consistent.
+func setField(target reflect.Value, index []int, value reflect.Value) {
+	field := target.FieldByIndex(index)
+	var r *Relation
+	if len(index) == 1 && target.CanAddr() {
+		r = relationAt(target.Type(), index[0])
+	}
+	if r == nil {
+		field.Set(value)
+		return
+	}
+
+	relationMu.Lock()
+	defer relationMu.Unlock()
+	if r.inverse == nil {
+		field.Set(value)
+		return
+	}
+
+	old := reflect.New(field.Type()).Elem()
+	old.Set(field)
+	field.Set(value)
+
+	owner := target.Addr()
+	for _, b := range relatedObjects(old) {
+		if !contains(field, b) {
+			r.detachInverse(owner, b)
+		}
+	}
+	for _, b := range relatedObjects(field) {
+		if !contains(old, b) {
+			r.attachInverse(owner, b)
+		}
+	}
+}
+
+// attachInverse updates the inverse field of b after b was related to owner,
+// removing b from the relation of its previous owner if the inverse field
+// holds a single object. The caller must hold relationMu.
+func (r *Relation) attachInverse(owner, b reflect.Value) {
+	inv := r.inverse
+	if inv == nil {
+		return
+	}
+
+	field := b.Elem().Field(inv.index)
+	if inv.Kind == HasMany {
+		if !contains(field, owner) {
+			addTo(field, owner)
+		}
+		return
+	}
+
+	if !field.IsNil() && field.Pointer() != owner.Pointer() {
+		removeFrom(field.Elem().Field(r.index), b) // The previous owner lets go of b.
+	}
+	field.Set(owner)
+}
+
+// detachInverse updates the inverse field of b after b was removed from the
+// relation of owner. The caller must hold relationMu.
+func (r *Relation) detachInverse(owner, b reflect.Value) {
+	if r.inverse == nil {
+		return
+	}
+	removeFrom(b.Elem().Field(r.inverse.index), owner)
+}
+
+// relatedObjects returns the objects held by a relation field.
+func relatedObjects(field reflect.Value) []reflect.Value {
+	if field.Kind() != reflect.Slice {
+		if field.IsNil() {
+			return nil
+		}
+		return []reflect.Value{field}
+	}
+
+	objs := make([]reflect.Value, 0, field.Len())
+	for i := range field.Len() {
+		if !field.Index(i).IsNil() {
+			objs = append(objs, field.Index(i))
+		}
+	}
+	return objs
+}
+
+// contains reports whether a relation field holds the object b.
+func contains(field, b reflect.Value) bool {
+	for _, obj := range relatedObjects(field) {
+		if obj.Pointer() == b.Pointer() {
+			return true
+		}
+	}
+	return false
+}
+
+// addTo adds the object b to a relation field.
+func addTo(field, b reflect.Value) {
+	if field.Kind() == reflect.Slice {
+		field.Set(reflect.Append(field, b))
+	} else {
+		field.Set(b)
+	}
+}
+
+// removeFrom removes the object b from a relation field.
+func removeFrom(field, b reflect.Value) {
+	if field.Kind() != reflect.Slice {
+		if !field.IsNil() && field.Pointer() == b.Pointer() {
+			field.SetZero()
+		}
+		return
+	}
+
+	kept := reflect.MakeSlice(field.Type(), 0, field.Len())
+	for i := range field.Len() {
+		if item := field.Index(i); item.IsNil() || item.Pointer() != b.Pointer() {
+			kept = reflect.Append(kept, item)
+		}
+	}
+	field.Set(kept)
+}
+
+// detachRelations clears the inverse fields of the objects related to obj, a
+// pointer to a class instance that is being destroyed, and returns the
+// objects of relations that cascade the destruction.
+func detachRelations(obj reflect.Value) []reflect.Value {
+	if obj.Kind() != reflect.Ptr || obj.IsNil() || obj.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	relations := relationsOf(obj.Elem().Type())
+	if len(relations) == 0 {
+		return nil
+	}
+
+	relationMu.Lock()
+	defer relationMu.Unlock()
+
+	var cascade []reflect.Value
+	for _, r := range relations {
+		for _, b := range relatedObjects(obj.Elem().Field(r.index)) {
+			r.detachInverse(obj, b)
+			if r.Cascade == CascadeDestroy {
+				cascade = append(cascade, b)
+			}
+		}
+	}
+	return cascade
+}
+
+// destroyRelated destroys the objects of a cascading relation: through their
+// wrapper if they have one, and otherwise through their Klass.
+func destroyRelated(objs []reflect.Value) {
+	for _, obj := range objs {
+		klass := klasses.lookup(unsafe.Pointer(obj.Pointer()))
+		if klass == nil {
+			continue // Not created by the package, or already destroyed.
+		}
+		if owner := klass.owner.Value(); owner != nil {
+			owner.Destroy()
+		} else {
+			klass.Deinit()
+		}
+	}
+}
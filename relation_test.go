@@ -0,0 +1,227 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// TestShopper is a test class with many purchases
+type TestShopper struct {
+	Name      string
+	Purchases []*TestPurchase
+}
+
+// TestPurchase is a test class belonging to a shopper
+type TestPurchase struct {
+	Item    string
+	Shopper *TestShopper
+}
+
+// TestPerson is a test class with one passport
+type TestPerson struct {
+	Name     string
+	Passport *TestPassport
+}
+
+// TestPassport is a test class belonging to a person
+type TestPassport struct {
+	Number string
+	Holder *TestPerson
+}
+
+// TestDefineRelation tests the DefineRelation function
+func TestDefineRelation(t *testing.T) {
+	shopperType := reflect.TypeOf(TestShopper{})
+	purchaseType := reflect.TypeOf(TestPurchase{})
+	if err := DefineRelation(shopperType, "Purchases", HasMany, purchaseType, "Shopper"); err != nil {
+		t.Fatalf("DefineRelation returned error: %v", err)
+	}
+
+	// Test that the inverse relation is defined
+	info, _ := LookupType(purchaseType)
+	inverse, ok := info.Relation("Shopper")
+	if !ok || inverse.Kind != BelongsTo || inverse.Target != shopperType || inverse.Inverse != "Purchases" {
+		t.Errorf("inverse relation is %+v, %v", inverse, ok)
+	}
+
+	// Test with invalid fields
+	if err := DefineRelation(shopperType, "Name", HasMany, purchaseType, ""); err == nil {
+		t.Error("DefineRelation with a field of the wrong type should return an error")
+	}
+	if err := DefineRelation(shopperType, "Purchases", HasOne, purchaseType, ""); err == nil {
+		t.Error("DefineRelation of HasOne on a slice should return an error")
+	}
+	if err := DefineRelation(shopperType, "Purchases", HasMany, purchaseType, "Missing"); err == nil {
+		t.Error("DefineRelation with a missing inverse field should return an error")
+	}
+	if err := DefineRelation(nil, "Purchases", HasMany, purchaseType, ""); err == nil {
+		t.Error("DefineRelation with a nil type should return an error")
+	}
+}
+
+// TestRelate tests that Relate, Unrelate and SetFields keep both sides consistent
+func TestRelate(t *testing.T) {
+	if err := DefineRelation(reflect.TypeOf(TestShopper{}), "Purchases", HasMany, reflect.TypeOf(TestPurchase{}), "Shopper"); err != nil {
+		t.Fatalf("DefineRelation returned error: %v", err)
+	}
+	factory := NewObjectFactory()
+	ann := &TestShopper{Name: "Ann"}
+	bob := &TestShopper{Name: "Bob"}
+	book := &TestPurchase{Item: "book"}
+	annObj := factory.CreateObject(ann)
+	bobObj := factory.CreateObject(bob)
+	bookObj := factory.CreateObject(book)
+
+	// Test relating from the owner side
+	if err := annObj.Relate("Purchases", bookObj); err != nil {
+		t.Fatalf("Relate returned error: %v", err)
+	}
+	if len(ann.Purchases) != 1 || ann.Purchases[0] != book || book.Shopper != ann {
+		t.Fatalf("Relate gave %v and %v", ann.Purchases, book.Shopper)
+	}
+	annObj.Relate("Purchases", book)
+	if len(ann.Purchases) != 1 {
+		t.Error("Relate should not add an object twice")
+	}
+
+	// Test moving through the inverse field with SetFields
+	if err := bookObj.SetFields(map[string]interface{}{"Shopper": bob}); err != nil {
+		t.Fatalf("SetFields returned error: %v", err)
+	}
+	if len(ann.Purchases) != 0 || len(bob.Purchases) != 1 || bob.Purchases[0] != book {
+		t.Errorf("SetFields gave %v and %v", ann.Purchases, bob.Purchases)
+	}
+
+	// Test replacing the collection with SetProp
+	pen := &TestPurchase{Item: "pen"}
+	if err := annObj.SetProp("Purchases", []*TestPurchase{book, pen}); err != nil {
+		t.Fatalf("SetProp returned error: %v", err)
+	}
+	if book.Shopper != ann || pen.Shopper != ann || len(bob.Purchases) != 0 {
+		t.Errorf("SetProp gave %v, %v and %v", book.Shopper, pen.Shopper, bob.Purchases)
+	}
+
+	// Test unrelating
+	if err := annObj.Unrelate("Purchases", pen); err != nil {
+		t.Fatalf("Unrelate returned error: %v", err)
+	}
+	if pen.Shopper != nil || len(ann.Purchases) != 1 {
+		t.Errorf("Unrelate gave %v and %v", pen.Shopper, ann.Purchases)
+	}
+
+	// Test with invalid arguments
+	if err := annObj.Relate("Missing", book); err == nil {
+		t.Error("Relate with an unknown relation should return an error")
+	}
+	if err := annObj.Relate("Purchases", bob); err == nil {
+		t.Error("Relate with an object of the wrong class should return an error")
+	}
+
+	// Test that destroying the owner detaches the related objects
+	annObj.Destroy()
+	if book.Shopper != nil {
+		t.Error("Destroy should detach the related objects")
+	}
+	if bookObj.State() == StateDestroyed {
+		t.Error("Destroy should not destroy detached objects")
+	}
+	bobObj.Destroy()
+	bookObj.Destroy()
+}
+
+// TestRelateHasOne tests one-to-one relations
+func TestRelateHasOne(t *testing.T) {
+	if err := DefineRelation(reflect.TypeOf(TestPerson{}), "Passport", HasOne, reflect.TypeOf(TestPassport{}), "Holder", WithCascade(CascadeDestroy)); err != nil {
+		t.Fatalf("DefineRelation returned error: %v", err)
+	}
+	factory := NewObjectFactory()
+	ann := &TestPerson{Name: "Ann"}
+	bob := &TestPerson{Name: "Bob"}
+	first := &TestPassport{Number: "1"}
+	second := &TestPassport{Number: "2"}
+	annObj := factory.CreateObject(ann)
+	bobObj := factory.CreateObject(bob)
+	firstObj := factory.CreateObject(first)
+	secondObj := factory.CreateObject(second)
+	defer bobObj.Destroy()
+
+	annObj.Relate("Passport", first)
+	if first.Holder != ann {
+		t.Fatal("Relate should set the inverse field")
+	}
+
+	// Test that replacing the object detaches the previous one
+	annObj.Relate("Passport", second)
+	if first.Holder != nil || second.Holder != ann {
+		t.Errorf("Relate gave holders %v and %v", first.Holder, second.Holder)
+	}
+
+	// Test that taking the object over clears the previous owner
+	for ref := range secondObj.Fields() {
+		if ref.Name == "Holder" {
+			if err := ref.SetObject(bob); err != nil {
+				t.Fatalf("SetObject returned error: %v", err)
+			}
+		}
+	}
+	if ann.Passport != nil || bob.Passport != second {
+		t.Errorf("SetObject gave passports %v and %v", ann.Passport, bob.Passport)
+	}
+
+	// Test the cascade
+	annObj.Relate("Passport", first)
+	annObj.Destroy()
+	if firstObj.State() != StateDestroyed {
+		t.Error("Destroy should cascade to the related object")
+	}
+	if secondObj.State() == StateDestroyed || bob.Passport != second {
+		t.Error("Destroy should not affect objects of other owners")
+	}
+	secondObj.Destroy()
+	if bob.Passport != nil {
+		t.Error("Destroy should detach the inverse field")
+	}
+}
+
+// TestRelated tests lazy loading of relations
+func TestRelated(t *testing.T) {
+	calls := 0
+	loader := func(owner any) (any, error) {
+		calls++
+		if owner.(*TestShopper).Name == "fail" {
+			return nil, fmt.Errorf("loader failed")
+		}
+		return []*TestPurchase{{Item: "lamp"}}, nil
+	}
+	if err := DefineRelation(reflect.TypeOf(TestShopper{}), "Purchases", HasMany, reflect.TypeOf(TestPurchase{}), "Shopper", WithLoader(loader)); err != nil {
+		t.Fatalf("DefineRelation returned error: %v", err)
+	}
+	defer DefineRelation(reflect.TypeOf(TestShopper{}), "Purchases", HasMany, reflect.TypeOf(TestPurchase{}), "Shopper")
+
+	factory := NewObjectFactory()
+	shopper := &TestShopper{Name: "Ann"}
+	obj := factory.CreateObject(shopper)
+	defer obj.Destroy()
+
+	for range 2 {
+		related, err := obj.Related("Purchases")
+		if err != nil {
+			t.Fatalf("Related returned error: %v", err)
+		}
+		purchases := related.([]*TestPurchase)
+		if len(purchases) != 1 || purchases[0].Item != "lamp" || purchases[0].Shopper != shopper {
+			t.Errorf("Related returned %v", purchases)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("loader was called %d times, want 1", calls)
+	}
+
+	// Test a failing loader
+	failing := factory.CreateObject(&TestShopper{Name: "fail"})
+	defer failing.Destroy()
+	if _, err := failing.Related("Purchases"); err == nil {
+		t.Error("Related should return the error of the loader")
+	}
+}
@@ -0,0 +1,272 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Tokenizer splits text into the terms of a full-text index.
+type Tokenizer func(text string) []string
+
+// DefaultTokenizer splits text into lowercase runs of letters and digits.
+// Example: oop.DefaultTokenizer("Buddy, the Golden-Retriever") // [buddy the golden retriever]
+func DefaultTokenizer(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// SearchOption configures the full-text index of a class.
+type SearchOption func(*searchOptions)
+
+// searchOptions holds the options of EnableSearch.
+type searchOptions struct {
+	tokenizer Tokenizer
+	tag       string
+	stopWords []string
+}
+
+// WithTokenizer sets how field values and queries are split into terms,
+// DefaultTokenizer by default.
+// Example: oop.EnableSearch(reflect.TypeOf(Dog{}), oop.WithTokenizer(strings.Fields))
+func WithTokenizer(tokenizer Tokenizer) SearchOption {
+	return func(o *searchOptions) {
+		o.tokenizer = tokenizer
+	}
+}
+
+// WithSearchTag indexes only the string fields with the given struct tag,
+// except those tagged "-", instead of all exported string fields.
+// Example: oop.EnableSearch(reflect.TypeOf(Dog{}), oop.WithSearchTag("search"))
+func WithSearchTag(tag string) SearchOption {
+	return func(o *searchOptions) {
+		o.tag = tag
+	}
+}
+
+// WithStopWords leaves the given terms out of the index and of queries.
+// Example: oop.EnableSearch(reflect.TypeOf(Dog{}), oop.WithStopWords("a", "the"))
+func WithStopWords(words ...string) SearchOption {
+	return func(o *searchOptions) {
+		o.stopWords = append(o.stopWords, words...)
+	}
+}
+
+// searchIndex is an inverted index over the string fields of a class.
+type searchIndex struct {
+	fields    []int // Indexes of the searched fields in the struct.
+	tokenizer Tokenizer
+	stopWords map[string]bool
+	postings  map[string]map[*ObjectWrapper]int // Occurrences of each term in each object.
+	order     map[*ObjectWrapper]uint64         // When each object was first indexed, for stable results.
+	next      uint64
+}
+
+// EnableSearch enables full-text search over the exported string and []string
+// fields of a class, see Search. The index is maintained like the indexes of
+// CreateIndex: objects are indexed when a factory creates them and whenever
+// they change through SetFields, SetProp, FieldRef setters, Intern and
+// ApplyAtomic, and forgotten when they are destroyed; objects created before
+// are indexed on their next change. The class is registered if it is not
+// already. It returns an error if search is already enabled.
+// Example: err := oop.EnableSearch(reflect.TypeOf(Dog{}), oop.WithSearchTag("search"))
+func EnableSearch(classType reflect.Type, opts ...SearchOption) error {
+	if classType == nil {
+		return fmt.Errorf("classType cannot be nil")
+	}
+	classType = classElem(classType)
+	if classType.Kind() != reflect.Struct {
+		return fmt.Errorf("class %s must be a struct", classType)
+	}
+
+	options := searchOptions{tokenizer: DefaultTokenizer}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.tokenizer == nil {
+		return fmt.Errorf("tokenizer cannot be nil")
+	}
+
+	search := &searchIndex{
+		tokenizer: options.tokenizer,
+		stopWords: map[string]bool{},
+		postings:  map[string]map[*ObjectWrapper]int{},
+		order:     map[*ObjectWrapper]uint64{},
+	}
+	for _, word := range options.stopWords {
+		for _, term := range options.tokenizer(word) {
+			search.stopWords[term] = true
+		}
+	}
+	for _, field := range exportedFields(classType) {
+		if len(field.Index) != 1 || !isTextType(field.Type) {
+			continue
+		}
+		if options.tag != "" {
+			if tag, ok := field.Tag.Lookup(options.tag); !ok || tag == "-" {
+				continue
+			}
+		}
+		search.fields = append(search.fields, field.Index[0])
+	}
+	if len(search.fields) == 0 {
+		return fmt.Errorf("class %s has no fields to search", classType)
+	}
+
+	c, err := classIndexesFor(classType)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.search != nil {
+		return fmt.Errorf("search is already enabled on %s", classType)
+	}
+	c.search = search
+	return nil
+}
+
+// DisableSearch disables the full-text search of a class enabled with
+// EnableSearch and drops its index, so search can be enabled again, e.g. with
+// other options. It does nothing if search is not enabled on the class.
+// Example: oop.DisableSearch(reflect.TypeOf(Dog{}))
+func DisableSearch(classType reflect.Type) {
+	if classType == nil {
+		return
+	}
+	c := indexesOf(classElem(classType))
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.search = nil
+	for obj, entry := range c.objects {
+		entry.terms = nil
+		c.objects[obj] = entry
+	}
+}
+
+// Search returns the live objects of a class that contain every term of the
+// query in their searched fields, see EnableSearch, ranked by how often the
+// terms occur, then in the order the objects were indexed. Soft-deleted
+// objects are left out. It returns nil if search is not enabled on the class
+// or the query has no terms.
+// Example: dogs := oop.Search(reflect.TypeOf(Dog{}), "buddy retriever")
+func Search(classType reflect.Type, query string) []*ObjectWrapper {
+	if classType == nil {
+		return nil
+	}
+	c := indexesOf(classElem(classType))
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := c.search
+	if s == nil {
+		return nil
+	}
+	var terms []string
+	for _, term := range s.tokenizer(query) {
+		if !s.stopWords[term] && !slices.Contains(terms, term) {
+			terms = append(terms, term)
+		}
+	}
+	if len(terms) == 0 {
+		return nil
+	}
+
+	// Start from the rarest term, so fewer candidates are checked
+	sort.Slice(terms, func(i, j int) bool { return len(s.postings[terms[i]]) < len(s.postings[terms[j]]) })
+	scores := map[*ObjectWrapper]int{}
+	for obj, count := range s.postings[terms[0]] {
+		if !obj.IsSoftDeleted() {
+			scores[obj] = count
+		}
+	}
+	for _, term := range terms[1:] {
+		for obj, score := range scores {
+			count, ok := s.postings[term][obj]
+			if !ok {
+				delete(scores, obj)
+				continue
+			}
+			scores[obj] = score + count
+		}
+	}
+
+	results := make([]*ObjectWrapper, 0, len(scores))
+	for obj := range scores {
+		results = append(results, obj)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if scores[results[i]] != scores[results[j]] {
+			return scores[results[i]] > scores[results[j]]
+		}
+		return s.order[results[i]] < s.order[results[j]]
+	})
+	return results
+}
+
+// isTextType reports whether a field of type t is searched: a string or a
+// slice of strings.
+func isTextType(t reflect.Type) bool {
+	return t.Kind() == reflect.String || (t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String)
+}
+
+// termsOf returns the occurrences of the terms in the searched fields of target.
+func (s *searchIndex) termsOf(target reflect.Value) map[string]int {
+	terms := map[string]int{}
+	count := func(text string) {
+		for _, term := range s.tokenizer(text) {
+			if !s.stopWords[term] {
+				terms[term]++
+			}
+		}
+	}
+
+	for _, index := range s.fields {
+		field := target.Field(index)
+		if field.Kind() == reflect.String {
+			count(field.String())
+			continue
+		}
+		for i := range field.Len() {
+			count(field.Index(i).String())
+		}
+	}
+	return terms
+}
+
+// add indexes the terms of an object.
+func (s *searchIndex) add(obj *ObjectWrapper, terms map[string]int) {
+	for term, count := range terms {
+		if s.postings[term] == nil {
+			s.postings[term] = map[*ObjectWrapper]int{}
+		}
+		s.postings[term][obj] = count
+	}
+	if _, ok := s.order[obj]; !ok {
+		s.next++
+		s.order[obj] = s.next
+	}
+}
+
+// remove forgets the terms of an object.
+func (s *searchIndex) remove(obj *ObjectWrapper, terms map[string]int) {
+	for term := range terms {
+		delete(s.postings[term], obj)
+		if len(s.postings[term]) == 0 {
+			delete(s.postings, term)
+		}
+	}
+}
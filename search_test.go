@@ -0,0 +1,131 @@
+package oop
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestListing is a test class with tagged searchable fields
+type TestListing struct {
+	Title  string   `search:"title"`
+	Tags   []string `search:"tags"`
+	Secret string   `search:"-"`
+	Owner  string
+}
+
+// TestNote is a test class searched with a custom tokenizer
+type TestNote struct {
+	Text string
+	Code int
+}
+
+// TestDefaultTokenizer tests the DefaultTokenizer function
+func TestDefaultTokenizer(t *testing.T) {
+	got := DefaultTokenizer("Buddy, the Golden-Retriever (2 yrs)")
+	want := []string{"buddy", "the", "golden", "retriever", "2", "yrs"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DefaultTokenizer returned %v, want %v", got, want)
+	}
+}
+
+// TestSearch tests that Search follows creations, mutations and destruction
+func TestSearch(t *testing.T) {
+	listingType := reflect.TypeOf(TestListing{})
+	if err := EnableSearch(listingType, WithSearchTag("search"), WithStopWords("the")); err != nil {
+		t.Fatalf("EnableSearch returned error: %v", err)
+	}
+	t.Cleanup(func() { DisableSearch(listingType) })
+	if err := EnableSearch(listingType); err == nil {
+		t.Error("EnableSearch on a class with search should return an error")
+	}
+
+	factory := NewObjectFactory()
+	buddy := factory.CreateObject(&TestListing{Title: "Buddy the golden retriever", Tags: []string{"dog", "retriever"}, Secret: "buddy"})
+	rex := factory.CreateObject(&TestListing{Title: "Rex the retriever", Owner: "buddy"})
+	whiskers := factory.CreateObject(&TestListing{Title: "Whiskers", Tags: []string{"cat"}})
+	defer rex.Destroy()
+	defer whiskers.Destroy()
+
+	// Test ranking, tag-based field inclusion and stop words
+	if got := Search(listingType, "retriever"); len(got) != 2 || got[0] != buddy || got[1] != rex {
+		t.Errorf("Search returned %v, want buddy and rex", got)
+	}
+	if got := Search(listingType, "Buddy RETRIEVER"); len(got) != 1 || got[0] != buddy {
+		t.Errorf("Search returned %v, want buddy", got)
+	}
+	if got := Search(listingType, "the"); got != nil {
+		t.Errorf("Search for a stop word returned %v", got)
+	}
+
+	// Test that changes update the index
+	if err := whiskers.SetFields(map[string]interface{}{"Title": "Whiskers the retriever"}); err != nil {
+		t.Fatalf("SetFields returned error: %v", err)
+	}
+	if got := Search(listingType, "retriever whiskers"); len(got) != 1 || got[0] != whiskers {
+		t.Errorf("Search returned %v, want whiskers", got)
+	}
+	if err := rex.SetProp("Title", "Rex"); err != nil {
+		t.Fatalf("SetProp returned error: %v", err)
+	}
+	if got := Search(listingType, "retriever"); len(got) != 2 || got[1] != whiskers {
+		t.Errorf("Search returned %v, want buddy and whiskers", got)
+	}
+
+	// Test soft-deleted and destroyed objects
+	buddy.SoftDestroy()
+	if got := Search(listingType, "golden"); len(got) != 0 {
+		t.Errorf("Search returned %v for a soft-deleted object", got)
+	}
+	buddy.Resurrect()
+	buddy.Destroy()
+	if got := Search(listingType, "golden"); len(got) != 0 {
+		t.Errorf("Search returned %v for a destroyed object", got)
+	}
+}
+
+// TestSearchTokenizer tests search with a custom tokenizer
+func TestSearchTokenizer(t *testing.T) {
+	noteType := reflect.TypeOf(TestNote{})
+	if err := EnableSearch(noteType, WithTokenizer(strings.Fields)); err != nil {
+		t.Fatalf("EnableSearch returned error: %v", err)
+	}
+	t.Cleanup(func() { DisableSearch(noteType) })
+
+	factory := NewObjectFactory()
+	note := factory.CreateObject(&TestNote{Text: "C++ and Go"})
+	defer note.Destroy()
+
+	if got := Search(noteType, "C++"); len(got) != 1 || got[0] != note {
+		t.Errorf("Search returned %v, want the note", got)
+	}
+	if got := Search(noteType, "c"); len(got) != 0 {
+		t.Errorf("Search returned %v, want no objects", got)
+	}
+
+	// Test enabling search again with other options
+	DisableSearch(noteType)
+	if got := Search(noteType, "C++"); got != nil {
+		t.Errorf("Search returned %v after DisableSearch", got)
+	}
+	if err := EnableSearch(noteType); err != nil {
+		t.Fatalf("EnableSearch returned error after DisableSearch: %v", err)
+	}
+	if err := note.SetFields(map[string]interface{}{"Text": "C++ and Go"}); err != nil {
+		t.Fatalf("SetFields returned error: %v", err)
+	}
+	if got := Search(noteType, "c"); len(got) != 1 || got[0] != note {
+		t.Errorf("Search returned %v, want the note with the default tokenizer", got)
+	}
+
+	// Test with invalid arguments
+	if err := EnableSearch(reflect.TypeOf(TestPassport{}), WithSearchTag("search")); err == nil {
+		t.Error("EnableSearch without fields to search should return an error")
+	}
+	if err := EnableSearch(nil); err == nil {
+		t.Error("EnableSearch with a nil type should return an error")
+	}
+	if got := Search(reflect.TypeOf(TestPassport{}), "1"); got != nil {
+		t.Errorf("Search on a class without search returned %v", got)
+	}
+}
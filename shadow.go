@@ -0,0 +1,105 @@
+package oop
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+)
+
+// Divergence describes a shadowed call where the candidate did not match the primary.
+type Divergence struct {
+	Method       string        // Name of the called method.
+	Args         []interface{} // Arguments passed to both objects.
+	Primary      []interface{} // Results returned by the primary.
+	Candidate    []interface{} // Results returned by the candidate.
+	CandidateErr error         // Error raised while calling the candidate, if any.
+}
+
+// ShadowObject sends every call to a primary and a candidate object.
+// Only the primary's results are returned; the candidate's results are compared
+// against them and mismatches are reported to the divergence callback.
+type ShadowObject struct {
+	primary      *ObjectWrapper
+	candidate    *ObjectWrapper
+	onDivergence func(Divergence)
+}
+
+// Shadow creates a ShadowObject for validating a rewritten class implementation.
+// Results are compared by their canonical encoding, with errors compared by message.
+// Example: shadow := oop.Shadow(oldObj, newObj, func(d oop.Divergence) { log.Println(d) })
+func Shadow(primary, candidate *ObjectWrapper, onDivergence func(Divergence)) *ShadowObject {
+	return &ShadowObject{
+		primary:      primary,
+		candidate:    candidate,
+		onDivergence: onDivergence,
+	}
+}
+
+// Call invokes the named method on the primary and then on the candidate.
+// It returns the primary's results. Failures of the candidate, including
+// panics, are reported as divergences and never affect the caller.
+func (s *ShadowObject) Call(method string, args ...interface{}) ([]interface{}, error) {
+	results, err := s.primary.Call(method, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	candidate, candidateErr := s.callCandidate(method, args)
+	if candidateErr != nil || !sameResults(results, candidate) {
+		if s.onDivergence != nil {
+			s.onDivergence(Divergence{
+				Method:       method,
+				Args:         args,
+				Primary:      results,
+				Candidate:    candidate,
+				CandidateErr: candidateErr,
+			})
+		}
+	}
+
+	return results, nil
+}
+
+// callCandidate invokes the method on the candidate, turning panics into errors.
+func (s *ShadowObject) callCandidate(method string, args []interface{}) (results []interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("candidate panicked: %v", r)
+		}
+	}()
+
+	return s.candidate.Call(method, args...)
+}
+
+// sameResults reports whether two result lists have the same content.
+func sameResults(a, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		x, y := comparableResult(a[i]), comparableResult(b[i])
+		xBytes, xErr := CanonicalBytes(x)
+		yBytes, yErr := CanonicalBytes(y)
+		if xErr != nil || yErr != nil {
+			if !reflect.DeepEqual(x, y) {
+				return false
+			}
+			continue
+		}
+		if !bytes.Equal(xBytes, yBytes) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// comparableResult replaces errors by their messages, since error values
+// usually carry their content in unexported fields.
+func comparableResult(v interface{}) interface{} {
+	if err, ok := v.(error); ok {
+		return err.Error()
+	}
+	return v
+}
@@ -0,0 +1,81 @@
+package oop
+
+import (
+	"testing"
+)
+
+// TestLoudDog is a rewritten TestDog used as a shadow candidate
+type TestLoudDog struct {
+	Name string
+}
+
+// Sound implements TestAnimal
+func (d *TestLoudDog) Sound() string {
+	return d.Name + ": WOOF!"
+}
+
+// TestPanickingDog is a shadow candidate that panics
+type TestPanickingDog struct{}
+
+// Sound implements TestAnimal
+func (d *TestPanickingDog) Sound() string {
+	panic("not implemented")
+}
+
+// TestShadow tests the Shadow function and ShadowObject
+func TestShadow(t *testing.T) {
+	factory := NewObjectFactory()
+	primary := factory.CreateObject(&TestDog{Name: "Buddy"})
+
+	// Test with matching candidate
+	divergences := []Divergence{}
+	shadow := Shadow(primary, factory.CreateObject(&TestDog{Name: "Buddy"}), func(d Divergence) {
+		divergences = append(divergences, d)
+	})
+
+	results, err := shadow.Call("Sound")
+	if err != nil {
+		t.Fatalf("Call returned error: %v", err)
+	}
+	if results[0] != "Buddy: Woof!" {
+		t.Errorf("Call returned %v, want %q", results[0], "Buddy: Woof!")
+	}
+	if len(divergences) != 0 {
+		t.Errorf("Call reported %d divergences for matching candidate", len(divergences))
+	}
+
+	// Test with diverging candidate
+	shadow = Shadow(primary, factory.CreateObject(&TestLoudDog{Name: "Buddy"}), func(d Divergence) {
+		divergences = append(divergences, d)
+	})
+	results, err = shadow.Call("Sound")
+	if err != nil {
+		t.Fatalf("Call returned error: %v", err)
+	}
+	if results[0] != "Buddy: Woof!" {
+		t.Errorf("Call returned %v, want the primary's result", results[0])
+	}
+	if len(divergences) != 1 {
+		t.Fatalf("Call reported %d divergences, want 1", len(divergences))
+	}
+	if divergences[0].Method != "Sound" || divergences[0].Candidate[0] != "Buddy: WOOF!" {
+		t.Errorf("Call reported wrong divergence: %+v", divergences[0])
+	}
+
+	// Test with panicking candidate
+	divergences = divergences[:0]
+	shadow = Shadow(primary, factory.CreateObject(&TestPanickingDog{}), func(d Divergence) {
+		divergences = append(divergences, d)
+	})
+	if _, err := shadow.Call("Sound"); err != nil {
+		t.Fatalf("Call returned error: %v", err)
+	}
+	if len(divergences) != 1 || divergences[0].CandidateErr == nil {
+		t.Error("Call should report a divergence with an error for a panicking candidate")
+	}
+
+	// Test with unknown method
+	if _, err := shadow.Call("Unknown"); err == nil {
+		t.Error("Call should return error for unknown method")
+	}
+}
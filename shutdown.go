@@ -0,0 +1,371 @@
+package oop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Shutdowner is something Shutdown tears down, such as an ObjectFactory or a
+// Container. If it also has a DestroyAll(ctx context.Context) error method,
+// Shutdown calls it after StopAll, in the destroy phase.
+type Shutdowner interface {
+	StopAll(ctx context.Context) error
+}
+
+// destroyer is a Shutdowner with a destroy phase.
+type destroyer interface {
+	DestroyAll(ctx context.Context) error
+}
+
+// ShutdownPhase is a phase of Shutdown.
+type ShutdownPhase int
+
+const (
+	PhaseStop    ShutdownPhase = iota // Stops the started objects and singletons, see StopAll.
+	PhaseDestroy                      // Destroys the live objects, see DestroyAll.
+)
+
+// String returns the name of the phase.
+func (p ShutdownPhase) String() string {
+	switch p {
+	case PhaseStop:
+		return "stop"
+	case PhaseDestroy:
+		return "destroy"
+	default:
+		return "unknown"
+	}
+}
+
+// ShutdownOption configures Shutdown.
+type ShutdownOption func(*shutdownOptions)
+
+// shutdownOptions holds the settings of Shutdown.
+type shutdownOptions struct {
+	timeouts map[ShutdownPhase]time.Duration // Time allowed for each phase, none if missing.
+	signals  []os.Signal                     // Signals that interrupt Shutdown, none by default.
+}
+
+// WithPhaseTimeout limits the time a phase of Shutdown may take. Targets that
+// have not finished the phase in time are abandoned, and Shutdown moves on to
+// the next phase.
+// Example: oop.Shutdown(ctx, oop.WithPhaseTimeout(oop.PhaseStop, 10*time.Second))
+func WithPhaseTimeout(phase ShutdownPhase, timeout time.Duration) ShutdownOption {
+	return func(o *shutdownOptions) {
+		o.timeouts[phase] = timeout
+	}
+}
+
+// WithInterrupt makes Shutdown give up when the process receives one of the
+// signals, os.Interrupt if none are given, e.g. a second Ctrl-C while a slow
+// shutdown is running: the running phase and the following ones are cut short.
+// Example: oop.Shutdown(ctx, oop.WithInterrupt(os.Interrupt, syscall.SIGTERM))
+func WithInterrupt(signals ...os.Signal) ShutdownOption {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt}
+	}
+	return func(o *shutdownOptions) {
+		o.signals = signals
+	}
+}
+
+// ShutdownStep is a phase of Shutdown run on one target.
+type ShutdownStep struct {
+	Phase    ShutdownPhase // The phase.
+	Target   string        // Name of the target, see RegisterShutdown.
+	Duration time.Duration // How long the step took, or how long Shutdown waited for it.
+	Err      error         // Error of the step, nil if it succeeded.
+}
+
+// ShutdownReport describes what Shutdown did and what it left behind.
+type ShutdownReport struct {
+	Steps       []ShutdownStep  // Steps in the order they ran.
+	Remaining   map[string]int  // Live objects left in each factory target, if any.
+	Leaks       []TrackedObject // Tracked objects still alive after shutdown, see EnableTracking.
+	Interrupted bool            // Whether a signal interrupted the shutdown, see WithInterrupt.
+}
+
+// Err returns the errors of the steps joined, or nil if all succeeded.
+func (r *ShutdownReport) Err() error {
+	var errs []error
+	for _, step := range r.Steps {
+		if step.Err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", step.Phase, step.Target, step.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// String returns a human-readable report.
+func (r *ShutdownReport) String() string {
+	var b strings.Builder
+	for _, step := range r.Steps {
+		status := "ok"
+		if step.Err != nil {
+			status = step.Err.Error()
+		}
+		fmt.Fprintf(&b, "%s %s (%s): %s\n", step.Phase, step.Target, step.Duration.Round(time.Millisecond), status)
+	}
+	targets := make([]string, 0, len(r.Remaining))
+	for target := range r.Remaining {
+		targets = append(targets, target)
+	}
+	slices.Sort(targets)
+	for _, target := range targets {
+		fmt.Fprintf(&b, "%s: %d live objects remain\n", target, r.Remaining[target])
+	}
+	for _, leak := range r.Leaks {
+		fmt.Fprintf(&b, "leaked %s created at %s\n", leak.Type, leak.CreatedAt.Format(time.RFC3339))
+	}
+	if r.Interrupted {
+		b.WriteString("interrupted\n")
+	}
+	return b.String()
+}
+
+// shutdownTarget is a Shutdowner registered with RegisterShutdown.
+type shutdownTarget struct {
+	name   string
+	target Shutdowner
+}
+
+var (
+	shutdownMu      sync.Mutex
+	shutdownTargets []shutdownTarget // In registration order.
+)
+
+// RegisterShutdown registers a target to tear down in Shutdown under a name
+// used in the report. Targets are shut down in reverse registration order, so
+// a target should be registered after those it depends on, e.g. a factory
+// whose objects use the singletons of a container after the container.
+// It returns an error if the name is empty or already registered.
+// Example: oop.RegisterShutdown("workers", workerFactory)
+func RegisterShutdown(name string, target Shutdowner) error {
+	if name == "" {
+		return fmt.Errorf("name cannot be empty")
+	}
+	if target == nil {
+		return fmt.Errorf("target cannot be nil")
+	}
+
+	shutdownMu.Lock()
+	defer shutdownMu.Unlock()
+	for _, registered := range shutdownTargets {
+		if registered.name == name {
+			return fmt.Errorf("shutdown target %s is already registered", name)
+		}
+	}
+	shutdownTargets = append(shutdownTargets, shutdownTarget{name: name, target: target})
+	return nil
+}
+
+// UnregisterShutdown removes a target registered with RegisterShutdown, if any.
+// Example: oop.UnregisterShutdown("workers")
+func UnregisterShutdown(name string) {
+	shutdownMu.Lock()
+	defer shutdownMu.Unlock()
+	shutdownTargets = slices.DeleteFunc(shutdownTargets, func(registered shutdownTarget) bool { return registered.name == name })
+}
+
+// Shutdown tears down the targets registered with RegisterShutdown in reverse
+// registration order: the stop phase calls StopAll on each of them, then the
+// destroy phase calls DestroyAll on those that have it, e.g. destroying the
+// objects left in factories. Each phase stops early when ctx is done or its
+// timeout, see WithPhaseTimeout, expires; the steps that did not finish report
+// the context error. It returns a report of the steps, of the objects left in
+// factory targets and, if tracking is enabled, of the tracked objects still
+// alive, along with the errors of the steps joined.
+// Example: report, err := oop.Shutdown(ctx, oop.WithPhaseTimeout(oop.PhaseStop, 10*time.Second), oop.WithInterrupt())
+func Shutdown(ctx context.Context, opts ...ShutdownOption) (*ShutdownReport, error) {
+	options := shutdownOptions{timeouts: map[ShutdownPhase]time.Duration{}}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	shutdownMu.Lock()
+	targets := slices.Clone(shutdownTargets)
+	shutdownMu.Unlock()
+	slices.Reverse(targets)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var interrupted atomic.Bool
+	if len(options.signals) > 0 {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, options.signals...)
+		defer signal.Stop(signals)
+		go func() {
+			select {
+			case <-signals:
+				interrupted.Store(true)
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	report := &ShutdownReport{}
+	for _, phase := range []ShutdownPhase{PhaseStop, PhaseDestroy} {
+		report.Steps = append(report.Steps, runPhase(ctx, phase, options.timeouts[phase], targets)...)
+	}
+
+	for _, t := range targets {
+		if f, ok := t.target.(*ObjectFactory); ok {
+			if n := len(f.live.list()); n > 0 {
+				if report.Remaining == nil {
+					report.Remaining = map[string]int{}
+				}
+				report.Remaining[t.name] = n
+			}
+		}
+	}
+	report.Leaks = LiveObjects()
+	report.Interrupted = interrupted.Load()
+	return report, report.Err()
+}
+
+// runPhase runs a phase of Shutdown on the targets that support it, within
+// the timeout if it is positive.
+func runPhase(ctx context.Context, phase ShutdownPhase, timeout time.Duration, targets []shutdownTarget) []ShutdownStep {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var steps []ShutdownStep
+	for _, t := range targets {
+		run := t.target.StopAll
+		if phase == PhaseDestroy {
+			d, ok := t.target.(destroyer)
+			if !ok {
+				continue
+			}
+			run = d.DestroyAll
+		}
+
+		start := time.Now()
+		err := runStep(ctx, run)
+		steps = append(steps, ShutdownStep{Phase: phase, Target: t.name, Duration: time.Since(start), Err: err})
+	}
+	return steps
+}
+
+// runStep calls run, returning the context error instead if ctx is done
+// first; run is then abandoned and keeps running in the background.
+func runStep(ctx context.Context, run func(ctx context.Context) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- run(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// StopAll stops the started objects of the factory, in reverse creation
+// order, so objects are stopped before those they were created from. It
+// returns the errors of the objects that failed to stop joined, and stops
+// early with the context error when ctx is done.
+// Example: err := factory.StopAll(ctx)
+func (f *ObjectFactory) StopAll(ctx context.Context) error {
+	objs := f.live.list()
+	slices.Reverse(objs)
+
+	var errs []error
+	for _, obj := range objs {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		if obj.State() != StateStarted {
+			continue
+		}
+		if err := obj.Stop(); err != nil {
+			errs = append(errs, fmt.Errorf("stopping %T: %w", obj.GetUnderlyingObject(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// DestroyAll destroys the live objects of the factory, in reverse creation
+// order, stopping those that are started first. It stops early with the
+// context error when ctx is done.
+// Example: err := factory.DestroyAll(ctx)
+func (f *ObjectFactory) DestroyAll(ctx context.Context) error {
+	objs := f.live.list()
+	slices.Reverse(objs)
+
+	for _, obj := range objs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		obj.Destroy()
+	}
+	return nil
+}
+
+// StopAll stops the singletons the container built, in reverse build order,
+// so singletons are stopped before their dependencies. A singleton is stopped
+// with its Stop() error method if it has one, started objects with
+// ObjectWrapper.Stop, and otherwise closed if it is an io.Closer. Scoped and
+// transient instances are left to their owners. It returns the errors of the
+// singletons that failed to stop joined, and stops early with the context
+// error when ctx is done.
+// Example: err := c.StopAll(ctx)
+func (c *Container) StopAll(ctx context.Context) error {
+	root := c.root
+	root.mu.Lock()
+	singletons := slices.Clone(root.singletons)
+	root.mu.Unlock()
+	slices.Reverse(singletons)
+
+	var errs []error
+	for _, p := range singletons {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+
+		p.mu.Lock()
+		v := p.instance
+		p.mu.Unlock()
+		if !v.IsValid() {
+			continue
+		}
+		if err := stopInstance(v.Interface()); err != nil {
+			errs = append(errs, fmt.Errorf("stopping %s: %w", p, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// stopInstance stops or closes a singleton, see Container.StopAll.
+func stopInstance(instance any) error {
+	switch s := instance.(type) {
+	case *ObjectWrapper:
+		if s.State() != StateStarted {
+			return nil
+		}
+		return s.Stop()
+	case interface{ Stop() error }:
+		return s.Stop()
+	case io.Closer:
+		return s.Close()
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,175 @@
+package oop
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestShutdownService is a test class recording when it stops
+type TestShutdownService struct {
+	Name   string
+	Log    *[]string
+	Block  chan struct{} // If not nil, Stop waits until it is closed.
+	OnStop func()        // If not nil, called when Stop begins.
+}
+
+// Stop stops the service
+func (s *TestShutdownService) Stop() error {
+	if s.OnStop != nil {
+		s.OnStop()
+	}
+	if s.Block != nil {
+		<-s.Block
+		return nil
+	}
+	*s.Log = append(*s.Log, "stop "+s.Name)
+	return nil
+}
+
+// TestShutdownDB is a test singleton closed on shutdown
+type TestShutdownDB struct {
+	Log *[]string
+}
+
+// Close implements io.Closer
+func (db *TestShutdownDB) Close() error {
+	*db.Log = append(*db.Log, "close db")
+	return nil
+}
+
+// registerShutdown registers a shutdown target for the duration of a test
+func registerShutdown(t *testing.T, name string, target Shutdowner) {
+	t.Helper()
+	if err := RegisterShutdown(name, target); err != nil {
+		t.Fatalf("RegisterShutdown returned error: %v", err)
+	}
+	t.Cleanup(func() { UnregisterShutdown(name) })
+}
+
+// TestShutdown tests tearing down factories and containers in dependency order
+func TestShutdown(t *testing.T) {
+	var log []string
+	c := NewContainer()
+	c.Singleton(func() *TestShutdownDB { return &TestShutdownDB{Log: &log} })
+	if _, err := Resolve[*TestShutdownDB](c); err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+
+	factory := NewObjectFactory()
+	var objs []*ObjectWrapper
+	for _, name := range []string{"a", "b", "idle"} {
+		obj := factory.CreateObject(&TestShutdownService{Name: name, Log: &log})
+		obj.Initialize()
+		if name != "idle" {
+			obj.Start()
+		}
+		objs = append(objs, obj)
+	}
+
+	registerShutdown(t, "db", c)
+	registerShutdown(t, "services", factory)
+
+	report, err := Shutdown(context.Background())
+	if err != nil {
+		t.Fatalf("Shutdown returned error: %v", err)
+	}
+	if want := []string{"stop b", "stop a", "close db"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("Shutdown ran %v, want %v", log, want)
+	}
+	for _, obj := range objs {
+		if obj.State() != StateDestroyed {
+			t.Errorf("the object is %v after Shutdown, want destroyed", obj.State())
+		}
+	}
+
+	var steps []string
+	for _, step := range report.Steps {
+		steps = append(steps, step.Phase.String()+" "+step.Target)
+	}
+	if want := []string{"stop services", "stop db", "destroy services"}; !reflect.DeepEqual(steps, want) {
+		t.Errorf("Shutdown ran the steps %v, want %v", steps, want)
+	}
+	if len(report.Remaining) != 0 || report.Interrupted {
+		t.Errorf("Shutdown reported %+v", report)
+	}
+	if s := report.String(); !strings.Contains(s, "stop services (") || !strings.Contains(s, "): ok") {
+		t.Errorf("String returned %q", s)
+	}
+}
+
+// TestShutdownTimeout tests abandoning targets that exceed a phase timeout
+func TestShutdownTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	var log []string
+	factory := NewObjectFactory()
+	stuck := factory.CreateObject(&TestShutdownService{Name: "stuck", Log: &log, Block: block})
+	stuck.Initialize()
+	stuck.Start()
+	registerShutdown(t, "stuck", factory)
+
+	report, err := Shutdown(context.Background(),
+		WithPhaseTimeout(PhaseStop, 20*time.Millisecond),
+		WithPhaseTimeout(PhaseDestroy, 20*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown returned %v, want a deadline error", err)
+	}
+	if len(report.Steps) != 2 || report.Steps[0].Err == nil || report.Steps[1].Err == nil {
+		t.Errorf("Shutdown reported the steps %+v, want both timed out", report.Steps)
+	}
+	if report.Remaining["stuck"] != 1 {
+		t.Errorf("Shutdown reported %v remaining objects, want 1", report.Remaining)
+	}
+	if s := report.String(); !strings.Contains(s, "stuck: 1 live objects remain") {
+		t.Errorf("String returned %q", s)
+	}
+}
+
+// TestShutdownInterrupt tests cutting a shutdown short with a signal
+func TestShutdownInterrupt(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Skipf("FindProcess returned error: %v", err)
+	}
+	signalErr := make(chan error, 1)
+
+	var log []string
+	factory := NewObjectFactory()
+	stuck := factory.CreateObject(&TestShutdownService{Name: "stuck", Log: &log, Block: block, OnStop: func() {
+		signalErr <- process.Signal(os.Interrupt)
+	}})
+	stuck.Initialize()
+	stuck.Start()
+	registerShutdown(t, "stuck", factory)
+
+	report, err := Shutdown(context.Background(), WithInterrupt())
+	if err := <-signalErr; err != nil {
+		t.Skipf("Signal returned error: %v", err)
+	}
+	if !errors.Is(err, context.Canceled) || !report.Interrupted {
+		t.Errorf("Shutdown returned %v, interrupted %v, want an interrupted shutdown", err, report.Interrupted)
+	}
+}
+
+// TestRegisterShutdown tests the errors reported by RegisterShutdown
+func TestRegisterShutdown(t *testing.T) {
+	if err := RegisterShutdown("", NewObjectFactory()); err == nil {
+		t.Error("RegisterShutdown should return error for an empty name")
+	}
+	if err := RegisterShutdown("nil", nil); err == nil {
+		t.Error("RegisterShutdown should return error for a nil target")
+	}
+	registerShutdown(t, "twice", NewObjectFactory())
+	if err := RegisterShutdown("twice", NewObjectFactory()); err == nil {
+		t.Error("RegisterShutdown should return error for a duplicate name")
+	}
+}
@@ -0,0 +1,192 @@
+package oop
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Signal is a named event of a class that its objects emit to the slots
+// connected to them, see DefineSignal.
+type Signal struct {
+	Name     string         // Name of the signal.
+	ArgTypes []reflect.Type // Types of the arguments passed to the slots.
+}
+
+// Connection is a slot connected to a signal of an object, see Connect.
+type Connection struct {
+	obj    *ObjectWrapper
+	signal string
+	slot   reflect.Value // func(args...) or func(args...) error
+}
+
+// DefineSignal defines a signal of a class, which objects of the class and of
+// its subclasses emit with Emit. The class is registered if it is not already.
+// It returns an error if the class already has a signal with that name.
+// Example: oop.DefineSignal(reflect.TypeOf(Button{}), "Clicked", reflect.TypeOf(0), reflect.TypeOf(0))
+func DefineSignal(classType reflect.Type, name string, argTypes ...reflect.Type) error {
+	if classType == nil {
+		return fmt.Errorf("classType cannot be nil")
+	}
+	if name == "" {
+		return fmt.Errorf("signal name cannot be empty")
+	}
+	for i, t := range argTypes {
+		if t == nil {
+			return fmt.Errorf("argument %d of signal %s cannot be nil", i, name)
+		}
+	}
+
+	classType = classElem(classType)
+	info, err := registeredClass(classType)
+	if err != nil {
+		return err
+	}
+
+	info.mu.Lock()
+	defer info.mu.Unlock()
+
+	if _, ok := info.signals[name]; ok {
+		return fmt.Errorf("signal %s is already defined on %s", name, classType)
+	}
+	if info.signals == nil {
+		info.signals = map[string]*Signal{}
+	}
+	info.signals[name] = &Signal{Name: name, ArgTypes: slices.Clone(argTypes)}
+	return nil
+}
+
+// Connect connects a slot to a signal of the object, defined with
+// DefineSignal on its class or an ancestor. The slot is a function taking the
+// arguments of the signal, or values they are assignable to, with no result or
+// an error. Slots are disconnected when the object is destroyed.
+// Example: conn, err := buttonObj.Connect("Clicked", func(x, y int) { fmt.Println(x, y) })
+func (o *ObjectWrapper) Connect(signal string, slot interface{}) (*Connection, error) {
+	s, err := o.signal(signal)
+	if err != nil {
+		return nil, err
+	}
+
+	sv := reflect.ValueOf(slot)
+	if sv.Kind() != reflect.Func || sv.IsNil() {
+		return nil, fmt.Errorf("slot of signal %s must be a function, got %T", signal, slot)
+	}
+	st := sv.Type()
+	validOut := st.NumOut() == 0 || (st.NumOut() == 1 && st.Out(0) == errorType)
+	if st.IsVariadic() || st.NumIn() != len(s.ArgTypes) || !validOut {
+		return nil, fmt.Errorf("slot of signal %s must be a func%s with an optional error result, got %s", signal, signature(s.ArgTypes), st)
+	}
+	for i, t := range s.ArgTypes {
+		if !t.AssignableTo(st.In(i)) {
+			return nil, fmt.Errorf("slot of signal %s takes %s as argument %d, which %s is not assignable to", signal, st.In(i), i, t)
+		}
+	}
+
+	conn := &Connection{obj: o, signal: signal, slot: sv}
+
+	o.hooksMu.Lock()
+	defer o.hooksMu.Unlock()
+	if o.State() == StateDestroyed {
+		return nil, fmt.Errorf("object is not initialized")
+	}
+	if o.slots == nil {
+		o.slots = map[string][]*Connection{}
+	}
+	o.slots[signal] = append(o.slots[signal], conn)
+	return conn, nil
+}
+
+// Disconnect disconnects the slot from the signal. Disconnecting it again
+// does nothing.
+// Example: conn.Disconnect()
+func (c *Connection) Disconnect() {
+	o := c.obj
+	o.hooksMu.Lock()
+	defer o.hooksMu.Unlock()
+	o.slots[c.signal] = slices.DeleteFunc(o.slots[c.signal], func(other *Connection) bool { return other == c })
+}
+
+// Emit calls the slots connected to a signal of the object with the given
+// arguments, in the order they were connected and without holding the object's
+// locks. Arguments are converted like SetFields values. It returns an error if
+// the arguments do not match the signal, and otherwise the errors returned by
+// the slots, joined; every slot is called either way.
+// Example: err := buttonObj.Emit("Clicked", 10, 20)
+func (o *ObjectWrapper) Emit(signal string, args ...any) error {
+	s, err := o.signal(signal)
+	if err != nil {
+		return err
+	}
+	if len(args) != len(s.ArgTypes) {
+		return fmt.Errorf("signal %s takes %d arguments, got %d", signal, len(s.ArgTypes), len(args))
+	}
+
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		v, err := fieldValue(s.ArgTypes[i], arg)
+		if err != nil {
+			return fmt.Errorf("argument %d of signal %s: %w", i, signal, err)
+		}
+		in[i] = reflect.New(s.ArgTypes[i]).Elem()
+		in[i].Set(v)
+	}
+
+	o.hooksMu.Lock()
+	slots := slices.Clone(o.slots[signal])
+	o.hooksMu.Unlock()
+
+	var errs []error
+	for _, conn := range slots {
+		out := conn.slot.Call(in)
+		if len(out) == 1 && !out[0].IsNil() {
+			errs = append(errs, out[0].Interface().(error))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// signal returns the signal with the given name defined on the class of the
+// object or one of its ancestors.
+func (o *ObjectWrapper) signal(name string) (*Signal, error) {
+	o.klassMu.RLock()
+	klass := o.klass
+	o.klassMu.RUnlock()
+	if klass == nil || klass.Class == nil {
+		return nil, fmt.Errorf("object is not initialized")
+	}
+
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	for info := klass.Header.Info; info != nil; info = info.Parent {
+		info.mu.Lock()
+		s := info.signals[name]
+		info.mu.Unlock()
+		if s != nil {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("signal %s not found on %T", name, klass.Class)
+}
+
+// disconnectAll disconnects all slots of a destroyed object and closes its
+// event bus, see Events.
+func (o *ObjectWrapper) disconnectAll() {
+	o.hooksMu.Lock()
+	defer o.hooksMu.Unlock()
+	o.slots = nil
+	if o.events != nil {
+		o.events.close()
+	}
+}
+
+// signature formats argument types as a parameter list.
+func signature(types []reflect.Type) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.String()
+	}
+	return "(" + strings.Join(names, ", ") + ")"
+}
@@ -0,0 +1,134 @@
+package oop
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestWidget is a test base class with signals
+type TestWidget struct {
+	Title string
+}
+
+// TestButton is a test class extending TestWidget
+type TestButton struct {
+	TestWidget
+}
+
+// newTestButtonSignals defines the signals of TestWidget and TestButton once
+func newTestButtonSignals(t *testing.T) {
+	t.Helper()
+	widgetType := reflect.TypeOf(TestWidget{})
+	buttonType := reflect.TypeOf(TestButton{})
+	if err := Extend(buttonType, widgetType); err != nil {
+		t.Fatalf("Extend returned error: %v", err)
+	}
+	if _, err := registeredClass(widgetType); err != nil {
+		t.Fatalf("registeredClass returned error: %v", err)
+	}
+
+	info, _ := LookupType(buttonType)
+	info.mu.Lock()
+	defined := info.signals["Clicked"] != nil
+	info.mu.Unlock()
+	if defined {
+		return
+	}
+	if err := DefineSignal(buttonType, "Clicked", reflect.TypeOf(0), reflect.TypeOf(0)); err != nil {
+		t.Fatalf("DefineSignal returned error: %v", err)
+	}
+	if err := DefineSignal(widgetType, "Renamed", reflect.TypeOf("")); err != nil {
+		t.Fatalf("DefineSignal returned error: %v", err)
+	}
+}
+
+// TestDefineSignal tests the DefineSignal function
+func TestDefineSignal(t *testing.T) {
+	newTestButtonSignals(t)
+	buttonType := reflect.TypeOf(TestButton{})
+
+	if err := DefineSignal(buttonType, "Clicked"); err == nil {
+		t.Error("DefineSignal of a defined signal should return an error")
+	}
+	if err := DefineSignal(nil, "Clicked"); err == nil {
+		t.Error("DefineSignal with a nil type should return an error")
+	}
+	if err := DefineSignal(buttonType, ""); err == nil {
+		t.Error("DefineSignal with an empty name should return an error")
+	}
+	if err := DefineSignal(buttonType, "Pressed", nil); err == nil {
+		t.Error("DefineSignal with a nil argument type should return an error")
+	}
+}
+
+// TestConnect tests connecting slots and emitting signals
+func TestConnect(t *testing.T) {
+	newTestButtonSignals(t)
+	button := NewObjectFactory().CreateObject(&TestButton{})
+
+	var clicks [][2]int
+	conn, err := button.Connect("Clicked", func(x, y int) { clicks = append(clicks, [2]int{x, y}) })
+	if err != nil {
+		t.Fatalf("Connect returned error: %v", err)
+	}
+	var titles []any
+	errRejected := errors.New("rejected")
+	if _, err := button.Connect("Renamed", func(title any) error {
+		titles = append(titles, title)
+		return errRejected
+	}); err != nil {
+		t.Fatalf("Connect to a signal of the parent class returned error: %v", err)
+	}
+
+	if err := button.Emit("Clicked", 1, int64(2)); err != nil {
+		t.Errorf("Emit returned error: %v", err)
+	}
+	if err := button.Emit("Renamed", "OK"); !errors.Is(err, errRejected) {
+		t.Errorf("Emit returned %v, want the slot's error", err)
+	}
+	if len(clicks) != 1 || clicks[0] != [2]int{1, 2} || len(titles) != 1 || titles[0] != "OK" {
+		t.Errorf("the slots were called with %v and %v", clicks, titles)
+	}
+
+	// Test that disconnected slots are not called
+	conn.Disconnect()
+	conn.Disconnect()
+	button.Emit("Clicked", 3, 4)
+	if len(clicks) != 1 {
+		t.Errorf("a disconnected slot was called with %v", clicks[1:])
+	}
+
+	// Test with invalid arguments
+	if err := button.Emit("Clicked", 1); err == nil {
+		t.Error("Emit with too few arguments should return an error")
+	}
+	if err := button.Emit("Clicked", "1", 2); err == nil {
+		t.Error("Emit with an argument of the wrong type should return an error")
+	}
+	if err := button.Emit("Missing"); err == nil {
+		t.Error("Emit of an undefined signal should return an error")
+	}
+	if _, err := button.Connect("Clicked", func(x string, y int) {}); err == nil {
+		t.Error("Connect with a slot of the wrong signature should return an error")
+	}
+	if _, err := button.Connect("Clicked", func(x, y int) int { return 0 }); err == nil {
+		t.Error("Connect with a slot returning a value should return an error")
+	}
+	if _, err := button.Connect("Clicked", nil); err == nil {
+		t.Error("Connect with a nil slot should return an error")
+	}
+
+	// Test that slots are disconnected on Destroy
+	button.Connect("Clicked", func(x, y int) { t.Error("a slot of a destroyed object was called") })
+	button.Destroy()
+	if button.slots != nil {
+		t.Errorf("Destroy left the slots %v connected", button.slots)
+	}
+	if err := button.Emit("Clicked", 5, 6); err == nil {
+		t.Error("Emit on a destroyed object should return an error")
+	}
+	if _, err := button.Connect("Clicked", func(x, y int) {}); err == nil {
+		t.Error("Connect on a destroyed object should return an error")
+	}
+}
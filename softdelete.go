@@ -0,0 +1,135 @@
+package oop
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SoftDestroy marks the object as logically deleted. The object keeps its
+// state and lifecycle, so it can still be read through GetUnderlyingObject for
+// auditing, but it is left out of projections (ToMap, Fields) and Intern, and
+// field mutations (SetFields, SetProp, FieldRef setters, ApplyAtomic) fail.
+// Resurrect undoes it; the factory's Sweep destroys it for good.
+// Example: err := userObj.SoftDestroy()
+func (o *ObjectWrapper) SoftDestroy() error {
+	o.lifecycleMu.Lock()
+	defer o.lifecycleMu.Unlock()
+
+	if o.State() == StateDestroyed {
+		return fmt.Errorf("object is destroyed")
+	}
+
+	o.mu.Lock() // Waits for in-flight mutations.
+	defer o.mu.Unlock()
+	if o.deletedAt.Load() != 0 {
+		return fmt.Errorf("object is already soft-deleted")
+	}
+	o.deletedAt.Store(time.Now().UnixNano())
+	o.trash.add(o)
+	return nil
+}
+
+// Resurrect restores a soft-deleted object, so it accepts mutations again.
+// Example: err := userObj.Resurrect()
+func (o *ObjectWrapper) Resurrect() error {
+	o.lifecycleMu.Lock()
+	defer o.lifecycleMu.Unlock()
+
+	if o.State() == StateDestroyed {
+		return fmt.Errorf("object is destroyed")
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.deletedAt.Load() == 0 {
+		return fmt.Errorf("object is not soft-deleted")
+	}
+	o.deletedAt.Store(0)
+	o.trash.remove(o)
+	return nil
+}
+
+// IsSoftDeleted reports whether the object was soft-deleted and not resurrected.
+func (o *ObjectWrapper) IsSoftDeleted() bool {
+	return o.deletedAt.Load() != 0
+}
+
+// DeletedAt returns when the object was soft-deleted, and false if it is not.
+func (o *ObjectWrapper) DeletedAt() (time.Time, bool) {
+	deletedAt := o.deletedAt.Load()
+	if deletedAt == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, deletedAt), true
+}
+
+// checkMutable returns an error if the object rejects mutations because it is
+// soft-deleted.
+func (o *ObjectWrapper) checkMutable() error {
+	if o.IsSoftDeleted() {
+		return fmt.Errorf("object is soft-deleted")
+	}
+	return nil
+}
+
+// Sweep destroys the objects of the factory that were soft-deleted at least
+// retention ago and returns how many it destroyed.
+// Example: n := factory.Sweep(30 * 24 * time.Hour)
+func (f *ObjectFactory) Sweep(retention time.Duration) int {
+	cutoff := time.Now().Add(-retention).UnixNano()
+
+	swept := 0
+	for _, obj := range f.trash.list() {
+		if obj.sweep(cutoff) {
+			swept++
+		}
+	}
+	return swept
+}
+
+// StartSweeper calls Sweep with the retention period every interval in a new
+// goroutine, until the returned stop function is called.
+// Example: stop := factory.StartSweeper(30*24*time.Hour, time.Hour); defer stop()
+func (f *ObjectFactory) StartSweeper(retention, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				f.Sweep(retention)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		<-stopped
+	}
+}
+
+// sweep destroys the object if it was soft-deleted at or before cutoff, a
+// Unix time in nanoseconds, and was not resurrected in the meantime.
+func (o *ObjectWrapper) sweep(cutoff int64) bool {
+	o.lifecycleMu.Lock()
+
+	deletedAt := o.deletedAt.Load()
+	if deletedAt == 0 || deletedAt > cutoff || o.State() == StateDestroyed {
+		o.lifecycleMu.Unlock()
+		return false
+	}
+	cascade, children := o.destroy()
+	o.unlockLifecycle()
+
+	destroyRelated(cascade)
+	destroyChildren(children)
+	return true
+}
@@ -0,0 +1,148 @@
+package oop
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestSoftDestroy tests the SoftDestroy and Resurrect methods
+func TestSoftDestroy(t *testing.T) {
+	factory := NewObjectFactory()
+	obj := factory.CreateObject(&TestDog{Name: "Rex"})
+	defer obj.Destroy()
+
+	if obj.IsSoftDeleted() {
+		t.Fatal("new object should not be soft-deleted")
+	}
+	before := time.Now()
+	if err := obj.SoftDestroy(); err != nil {
+		t.Fatalf("SoftDestroy returned error: %v", err)
+	}
+	if at, ok := obj.DeletedAt(); !ok || at.Before(before) {
+		t.Errorf("DeletedAt returned %v, %v", at, ok)
+	}
+	if err := obj.SoftDestroy(); err == nil {
+		t.Error("SoftDestroy of a soft-deleted object should return an error")
+	}
+
+	// Test that the state is kept but mutations and projections are rejected
+	if dog := obj.GetUnderlyingObject().(*TestDog); dog.Name != "Rex" {
+		t.Errorf("soft-deleted object changed to %+v", dog)
+	}
+	if err := obj.SetFields(map[string]interface{}{"Name": "Max"}); err == nil {
+		t.Error("SetFields on a soft-deleted object should return an error")
+	}
+	if err := obj.SetProp("Name", "Max"); err == nil {
+		t.Error("SetProp on a soft-deleted object should return an error")
+	}
+	if err := ApplyAtomic(func(tx Access) error { return nil }, obj); err == nil {
+		t.Error("ApplyAtomic on a soft-deleted object should return an error")
+	}
+	if _, err := obj.ToMap(); err == nil {
+		t.Error("ToMap of a soft-deleted object should return an error")
+	}
+	for range obj.Fields() {
+		t.Error("Fields of a soft-deleted object should yield nothing")
+	}
+
+	// Test resurrecting
+	if err := obj.Resurrect(); err != nil {
+		t.Fatalf("Resurrect returned error: %v", err)
+	}
+	if _, ok := obj.DeletedAt(); ok || obj.IsSoftDeleted() {
+		t.Error("resurrected object should not be soft-deleted")
+	}
+	if err := obj.SetFields(map[string]interface{}{"Name": "Max"}); err != nil {
+		t.Errorf("SetFields after Resurrect returned error: %v", err)
+	}
+	if err := obj.Resurrect(); err == nil {
+		t.Error("Resurrect of a live object should return an error")
+	}
+
+	// Test with a destroyed object
+	obj.Destroy()
+	if err := obj.SoftDestroy(); err == nil {
+		t.Error("SoftDestroy of a destroyed object should return an error")
+	}
+	if err := obj.Resurrect(); err == nil {
+		t.Error("Resurrect of a destroyed object should return an error")
+	}
+}
+
+// TestSweep tests the Sweep and StartSweeper methods
+func TestSweep(t *testing.T) {
+	factory := NewObjectFactory()
+	kept := factory.CreateObject(&TestDog{Name: "Rex"})
+	defer kept.Destroy()
+	deleted := factory.CreateObject(&TestDog{Name: "Max"})
+	resurrected := factory.CreateObject(&TestDog{Name: "Bo"})
+	defer resurrected.Destroy()
+
+	deleted.SoftDestroy()
+	resurrected.SoftDestroy()
+	resurrected.Resurrect()
+
+	// Test the retention period
+	if n := factory.Sweep(time.Hour); n != 0 {
+		t.Errorf("Sweep destroyed %d objects within the retention period", n)
+	}
+	if n := factory.Sweep(0); n != 1 {
+		t.Errorf("Sweep destroyed %d objects, want 1", n)
+	}
+	if deleted.State() != StateDestroyed {
+		t.Error("Sweep should destroy the soft-deleted object")
+	}
+	if kept.State() == StateDestroyed || resurrected.State() == StateDestroyed {
+		t.Error("Sweep should only destroy soft-deleted objects")
+	}
+	if n := factory.Sweep(0); n != 0 {
+		t.Errorf("Sweep destroyed %d objects again", n)
+	}
+
+	// Test that destroyed objects are forgotten
+	destroyed := factory.CreateObject(&TestDog{Name: "Ace"})
+	destroyed.SoftDestroy()
+	destroyed.Destroy()
+	if n := factory.Sweep(0); n != 0 {
+		t.Errorf("Sweep destroyed %d objects that were already destroyed", n)
+	}
+
+	// Test the background sweeper
+	swept := factory.CreateObject(&TestDog{Name: "Jax"})
+	swept.SoftDestroy()
+	stop := factory.StartSweeper(0, time.Millisecond)
+	deadline := time.Now().Add(5 * time.Second)
+	for swept.State() != StateDestroyed && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	stop()
+	stop()
+	if swept.State() != StateDestroyed {
+		t.Error("StartSweeper should destroy soft-deleted objects")
+	}
+}
+
+// TestSoftDestroyIntern tests that Intern skips soft-deleted objects
+func TestSoftDestroyIntern(t *testing.T) {
+	if err := RegisterNaturalKey(reflect.TypeOf(TestCustomer{}), "Email"); err != nil {
+		t.Fatalf("RegisterNaturalKey returned error: %v", err)
+	}
+	factory := NewObjectFactory()
+
+	ann, _ := factory.Intern(&TestCustomer{Email: "soft@example.com", Name: "Ann"})
+	defer ann.Destroy()
+	ann.SoftDestroy()
+
+	again, err := factory.Intern(&TestCustomer{Email: "soft@example.com", Name: "Annie"})
+	if err != nil {
+		t.Fatalf("Intern returned error: %v", err)
+	}
+	defer again.Destroy()
+	if again == ann {
+		t.Error("Intern should not return a soft-deleted object")
+	}
+	if got := ann.GetUnderlyingObject().(*TestCustomer); got.Name != "Ann" {
+		t.Errorf("Intern changed the soft-deleted object to %+v", got)
+	}
+}
@@ -0,0 +1,76 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// TypeToken is an interned handle of a type with its metadata computed once.
+// There is a single token per type, so tokens can be compared with ==, and
+// passing a token instead of a reflect.Type saves the reflect.TypeOf call and
+// registry lookup at every call site.
+type TypeToken struct {
+	Type   reflect.Type // The type the token was created for.
+	Elem   reflect.Type // The class type: the element type of pointer types, otherwise Type.
+	TypeID uintptr      // Type ID of Elem, as in TypeInfo.
+
+	info atomic.Pointer[ClassInfo] // ClassInfo of Elem, cached once the class is registered.
+}
+
+// typeTokens interns the tokens, keyed by type.
+var typeTokens sync.Map
+
+// TypeTokenFor returns the token of type T.
+// Example: dogToken := oop.TypeTokenFor[Dog]()
+func TypeTokenFor[T any]() *TypeToken {
+	return typeToken(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// typeToken returns the interned token of a type.
+func typeToken(t reflect.Type) *TypeToken {
+	if token, ok := typeTokens.Load(t); ok {
+		return token.(*TypeToken)
+	}
+
+	elem := classElem(t)
+	token, _ := typeTokens.LoadOrStore(t, &TypeToken{Type: t, Elem: elem, TypeID: typeIDOf(elem)})
+	return token.(*TypeToken)
+}
+
+// ClassInfo returns the ClassInfo of the token's class, the same one New uses.
+// Registered classes return their registered ClassInfo, which is cached in the
+// token.
+func (t *TypeToken) ClassInfo() *ClassInfo {
+	if info := t.info.Load(); info != nil {
+		return info
+	}
+	if info, ok := LookupType(t.Elem); ok {
+		t.info.Store(info) // Registrations are never removed.
+		return info
+	}
+	return makeClassInfo(t.Elem)
+}
+
+// New creates a new instance of the token's class, like New.
+// Example: klass := dogToken.New(nil, nil)
+func (t *TypeToken) New(allocator Allocator, init interface{}) *Klass {
+	klass, _ := newKlass(allocator, t.ClassInfo(), t.Elem, init)
+	return klass
+}
+
+// Cast casts an object to the token's type, like Cast.
+// Example: animal := oop.TypeTokenFor[IAnimal]().Cast(dog)
+func (t *TypeToken) Cast(obj any) interface{} {
+	return Cast(obj, t.Type)
+}
+
+// Extend declares that the token's class inherits from the parent's class, like Extend.
+// Example: oop.TypeTokenFor[Dog]().Extend(oop.TypeTokenFor[Animal]())
+func (t *TypeToken) Extend(parent *TypeToken) error {
+	if parent == nil {
+		return fmt.Errorf("parent cannot be nil")
+	}
+	return Extend(t.Elem, parent.Elem)
+}
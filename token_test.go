@@ -0,0 +1,66 @@
+package oop
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestToken is a test class created through a TypeToken
+type TestToken struct {
+	TestMammal
+	Name string
+}
+
+// TestTypeTokenFor tests the TypeTokenFor function and the TypeToken methods
+func TestTypeTokenFor(t *testing.T) {
+	token := TypeTokenFor[TestToken]()
+	if token != TypeTokenFor[TestToken]() {
+		t.Error("TypeTokenFor should return the same token for the same type")
+	}
+	if token == TypeTokenFor[*TestToken]() {
+		t.Error("TypeTokenFor should return different tokens for different types")
+	}
+	if ptrToken := TypeTokenFor[*TestToken](); ptrToken.Elem != token.Type {
+		t.Errorf("Elem is %v, want the element type", ptrToken.Elem)
+	}
+	if token.TypeID != typeIDOf(reflect.TypeOf(TestToken{})) {
+		t.Error("TypeID does not match the type")
+	}
+
+	// Test ClassInfo before and after registration
+	if token.ClassInfo() != token.ClassInfo() || token.ClassInfo() != makeClassInfo(token.Elem) {
+		t.Error("ClassInfo should return the ClassInfo New uses")
+	}
+	info, err := Register(token.Type)
+	if err != nil {
+		t.Fatalf("Register returned error: %v", err)
+	}
+	if token.ClassInfo() != info || token.ClassInfo() != info {
+		t.Error("ClassInfo should return the registered ClassInfo")
+	}
+
+	// Test New
+	klass := token.New(nil, nil)
+	if _, ok := klass.Class.(*TestToken); !ok || klass.Header.Info != info {
+		t.Errorf("New returned %T with %v, want *TestToken with the registered ClassInfo", klass.Class, klass.Header.Info)
+	}
+	klass.Deinit()
+
+	// Test Extend and Cast
+	if err := token.Extend(TypeTokenFor[TestMammal]()); err != nil {
+		t.Fatalf("Extend returned error: %v", err)
+	}
+	if !IsSubclassOf(token.Type, reflect.TypeOf(TestMammal{})) {
+		t.Error("Extend should declare the parent class")
+	}
+	if err := token.Extend(nil); err == nil {
+		t.Error("Extend should return error for a nil parent")
+	}
+	obj := &TestToken{Name: "token"}
+	if mammal := TypeTokenFor[*TestMammal]().Cast(obj); mammal != &obj.TestMammal {
+		t.Errorf("Cast returned %v, want the embedded TestMammal", mammal)
+	}
+	if _, ok := TypeTokenFor[TestAnimal]().Cast(&TestDog{}).(TestAnimal); !ok {
+		t.Error("Cast should cast to interface types")
+	}
+}
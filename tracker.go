@@ -0,0 +1,193 @@
+package oop
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+	"weak"
+)
+
+// TrackedObject is a live Klass recorded by the instance tracker, see EnableTracking.
+type TrackedObject struct {
+	Klass     *Klass
+	Type      reflect.Type // Class of the object.
+	CreatedAt time.Time
+	Stack     []byte // Stack that created the object, nil without WithStackTraces.
+}
+
+// TrackingOption configures the instance tracker.
+type TrackingOption func(*trackingOptions)
+
+// trackingOptions holds the options of EnableTracking.
+type trackingOptions struct {
+	stacks bool
+}
+
+// WithStackTraces records the stack that created each tracked object.
+// Recording the stack slows down creation, so it is meant for debugging.
+// Example: oop.EnableTracking(oop.WithStackTraces())
+func WithStackTraces() TrackingOption {
+	return func(o *trackingOptions) {
+		o.stacks = true
+	}
+}
+
+// trackedEntry is the record of a tracked Klass.
+type trackedEntry struct {
+	order     uint64 // When the Klass was recorded, for stable results.
+	classType reflect.Type
+	createdAt time.Time
+	stack     []byte
+}
+
+// instanceTracker records the live Klass instances while tracking is enabled.
+// Instances are referenced weakly, so the tracker does not keep them alive.
+type instanceTracker struct {
+	enabled atomic.Bool
+	mu      sync.Mutex
+	stacks  bool
+	objects map[weak.Pointer[Klass]]trackedEntry
+	next    uint64
+}
+
+var tracker = &instanceTracker{}
+
+// EnableTracking starts recording every Klass created by New, and so by the
+// factories, with its class and creation time, until it is deinitialized or
+// garbage collected. LiveObjects and DumpLeaks list the recorded objects, to
+// find objects that were created but never destroyed. Objects created before
+// tracking was enabled are not recorded. Enabling tracking again replaces the
+// options and keeps the recorded objects.
+// Example: oop.EnableTracking(oop.WithStackTraces())
+func EnableTracking(opts ...TrackingOption) {
+	var options trackingOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	tracker.stacks = options.stacks
+	if tracker.objects == nil {
+		tracker.objects = map[weak.Pointer[Klass]]trackedEntry{}
+	}
+	tracker.enabled.Store(true)
+}
+
+// DisableTracking stops recording objects and forgets the recorded ones.
+func DisableTracking() {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	tracker.enabled.Store(false)
+	tracker.objects = nil
+}
+
+// LiveObjects returns the tracked objects that are neither deinitialized nor
+// garbage collected, in the order they were created. Given class types, it
+// returns only the objects of those classes. It returns nil if tracking is not
+// enabled, see EnableTracking.
+// Example: dogs := oop.LiveObjects(reflect.TypeOf(Dog{}))
+func LiveObjects(classTypes ...reflect.Type) []TrackedObject {
+	filter := make([]reflect.Type, len(classTypes))
+	for i, t := range classTypes {
+		filter[i] = classElem(t)
+	}
+
+	tracker.mu.Lock()
+	type ordered struct {
+		obj   TrackedObject
+		order uint64
+	}
+	var entries []ordered
+	for key, entry := range tracker.objects {
+		if len(filter) > 0 && !slices.Contains(filter, entry.classType) {
+			continue
+		}
+		if k := key.Value(); k != nil {
+			obj := TrackedObject{Klass: k, Type: entry.classType, CreatedAt: entry.createdAt, Stack: entry.stack}
+			entries = append(entries, ordered{obj: obj, order: entry.order})
+		}
+	}
+	tracker.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].order < entries[j].order })
+	var objs []TrackedObject
+	for _, entry := range entries {
+		objs = append(objs, entry.obj)
+	}
+	return objs
+}
+
+// DumpLeaks writes a report of the objects LiveObjects returns to w: the
+// class, creation time and age of each, with its creation stack if recorded.
+// Example: oop.DumpLeaks(os.Stderr)
+func DumpLeaks(w io.Writer, classTypes ...reflect.Type) error {
+	objs := LiveObjects(classTypes...)
+	if _, err := fmt.Fprintf(w, "%d live objects\n", len(objs)); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, obj := range objs {
+		if _, err := fmt.Fprintf(w, "%s created at %s (%s ago)\n", obj.Type, obj.CreatedAt.Format(time.RFC3339Nano), now.Sub(obj.CreatedAt).Round(time.Millisecond)); err != nil {
+			return err
+		}
+		if obj.Stack != nil {
+			if _, err := fmt.Fprintf(w, "%s\n", obj.Stack); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// add records a new Klass, or one taken out of a PooledFactory, if tracking is
+// enabled.
+func (t *instanceTracker) add(k *Klass) {
+	if !t.enabled.Load() {
+		return
+	}
+
+	entry := trackedEntry{classType: classElem(reflect.TypeOf(k.Class)), createdAt: time.Now()}
+	key := weak.Make(k)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.objects == nil {
+		return // Disabled in the meantime.
+	}
+	if t.stacks {
+		entry.stack = debug.Stack()
+	}
+	t.next++
+	entry.order = t.next
+	t.objects[key] = entry
+
+	// Pooled instances are added on each reuse, but need a single cleanup.
+	if !k.cleanup {
+		k.cleanup = true
+		runtime.AddCleanup(k, t.forget, key)
+	}
+}
+
+// remove forgets a deinitialized Klass, or one put back in a PooledFactory.
+func (t *instanceTracker) remove(k *Klass) {
+	if !t.enabled.Load() {
+		return
+	}
+	t.forget(weak.Make(k))
+}
+
+// forget removes the record of a Klass, which may have been collected.
+func (t *instanceTracker) forget(key weak.Pointer[Klass]) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.objects, key)
+}
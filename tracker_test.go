@@ -0,0 +1,78 @@
+package oop
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestTrackedJob is a test class for the instance tracker
+type TestTrackedJob struct {
+	Name string
+}
+
+// TestTrackedWorker is another test class for the instance tracker
+type TestTrackedWorker struct {
+	Name string
+}
+
+// TestLiveObjects tests that tracked objects are listed until they are destroyed
+func TestLiveObjects(t *testing.T) {
+	jobType := reflect.TypeOf(TestTrackedJob{})
+	factory := NewObjectFactory()
+
+	// Test that objects are not tracked before tracking is enabled
+	untracked := factory.CreateObject(&TestTrackedJob{Name: "untracked"})
+	defer untracked.Destroy()
+	if objs := LiveObjects(jobType); objs != nil {
+		t.Errorf("LiveObjects without tracking returned %v", objs)
+	}
+
+	EnableTracking(WithStackTraces())
+	defer DisableTracking()
+
+	build := factory.CreateObject(&TestTrackedJob{Name: "build"})
+	deploy := factory.CreateObject(&TestTrackedJob{Name: "deploy"})
+	worker := factory.CreateObject(&TestTrackedWorker{Name: "worker"})
+	defer deploy.Destroy()
+	defer worker.Destroy()
+
+	objs := LiveObjects(jobType)
+	if len(objs) != 2 || objs[0].Klass.Class != build.GetUnderlyingObject() || objs[1].Klass.Class != deploy.GetUnderlyingObject() {
+		t.Fatalf("LiveObjects returned %v, want build and deploy", objs)
+	}
+	if objs[0].Type != jobType || objs[0].CreatedAt.IsZero() {
+		t.Errorf("LiveObjects returned %+v", objs[0])
+	}
+	if !strings.Contains(string(objs[0].Stack), "TestLiveObjects") {
+		t.Errorf("LiveObjects returned the stack:\n%s", objs[0].Stack)
+	}
+	if objs := LiveObjects(jobType, reflect.TypeOf(&TestTrackedWorker{})); len(objs) != 3 {
+		t.Errorf("LiveObjects with two classes returned %d objects, want 3", len(objs))
+	}
+
+	// Test that destroyed objects are forgotten
+	build.Destroy()
+	if objs := LiveObjects(jobType); len(objs) != 1 || objs[0].Klass.Class != deploy.GetUnderlyingObject() {
+		t.Errorf("LiveObjects returned %v after Destroy, want deploy", objs)
+	}
+}
+
+// TestDumpLeaks tests the report of the live objects
+func TestDumpLeaks(t *testing.T) {
+	EnableTracking()
+	defer DisableTracking()
+
+	obj := NewObjectFactory().CreateObject(&TestTrackedWorker{Name: "leaked"})
+	defer obj.Destroy()
+
+	var buf bytes.Buffer
+	if err := DumpLeaks(&buf, reflect.TypeOf(TestTrackedWorker{})); err != nil {
+		t.Fatalf("DumpLeaks returned error: %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "1 live objects\n") || !strings.Contains(out, "oop.TestTrackedWorker created at") {
+		t.Errorf("DumpLeaks wrote:\n%s", out)
+	}
+}
@@ -0,0 +1,150 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Traverse follows a path of relations from start, an object given as a
+// wrapper or as a pointer to a class instance, and returns the distinct
+// objects at the end of the path for which pred returns true, in the order
+// they are reached. The path lists relation names separated by dots; the
+// objects of HasMany relations are all followed, which "[*]" can make
+// explicit. Objects reached several times, e.g. through cycles, are visited
+// once per step. A nil pred accepts all objects.
+// Example: orders, err := oop.Traverse(order, "Owner.Friends[*].Orders", nil)
+func Traverse(start any, path string, pred func(obj any) bool) ([]any, error) {
+	v, ok := objectValue(start)
+	if !ok {
+		return nil, fmt.Errorf("start must be a pointer to a class instance, got %T", start)
+	}
+	if path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+
+	current := []reflect.Value{v}
+	for _, step := range strings.Split(path, ".") {
+		name, expand := strings.CutSuffix(step, "[*]")
+		if name == "" {
+			return nil, fmt.Errorf("invalid path %q", path)
+		}
+
+		var next []reflect.Value
+		seen := map[uintptr]bool{}
+		for _, obj := range current {
+			r, ok := relationOf(obj.Elem().Type(), name)
+			if !ok {
+				return nil, fmt.Errorf("relation %s not found on %s", name, obj.Elem().Type())
+			}
+			if expand && r.Kind != HasMany {
+				return nil, fmt.Errorf("relation %s holds a single object and cannot be expanded", name)
+			}
+
+			for _, related := range neighbors(obj, r) {
+				if !seen[related.Pointer()] {
+					seen[related.Pointer()] = true
+					next = append(next, related)
+				}
+			}
+		}
+		current = next
+	}
+
+	results := make([]any, 0, len(current))
+	for _, obj := range current {
+		if pred == nil || pred(obj.Interface()) {
+			results = append(results, obj.Interface())
+		}
+	}
+	return results, nil
+}
+
+// ShortestPath returns the shortest chain of objects from a to b, both
+// included, following the named relations, or all defined relations if none
+// are named. Objects are given as wrappers or as pointers to class instances,
+// and each is visited once, so cycles end the search. It returns false if b
+// cannot be reached from a.
+// Example: path, ok := oop.ShortestPath(ann, bob, "Friends")
+func ShortestPath(a, b any, relNames ...string) ([]any, bool) {
+	from, ok := objectValue(a)
+	if !ok {
+		return nil, false
+	}
+	to, ok := objectValue(b)
+	if !ok {
+		return nil, false
+	}
+
+	allowed := map[string]bool{}
+	for _, name := range relNames {
+		allowed[name] = true
+	}
+
+	previous := map[uintptr]reflect.Value{from.Pointer(): {}}
+	queue := []reflect.Value{from}
+	for len(queue) > 0 {
+		obj := queue[0]
+		queue = queue[1:]
+
+		if obj.Pointer() == to.Pointer() {
+			var path []any
+			for v := obj; v.IsValid(); v = previous[v.Pointer()] {
+				path = append(path, v.Interface())
+			}
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path, true
+		}
+
+		for _, r := range relationsOf(obj.Elem().Type()) {
+			if len(allowed) > 0 && !allowed[r.Name] {
+				continue
+			}
+			for _, related := range neighbors(obj, r) {
+				if _, seen := previous[related.Pointer()]; !seen {
+					previous[related.Pointer()] = obj
+					queue = append(queue, related)
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+// objectValue returns an object, given as a wrapper or as a pointer to a
+// class instance, as a pointer value.
+func objectValue(obj any) (reflect.Value, bool) {
+	if wrapper, ok := obj.(*ObjectWrapper); ok {
+		obj = wrapper.GetUnderlyingObject()
+	}
+
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	return v, true
+}
+
+// relationOf returns the named relation of a struct type.
+func relationOf(t reflect.Type, name string) (*Relation, bool) {
+	info, ok := LookupType(t)
+	if !ok {
+		return nil, false
+	}
+	return info.Relation(name)
+}
+
+// neighbors returns the objects related to obj through a relation, copied
+// out of the relation field so they can be used without holding relationMu.
+func neighbors(obj reflect.Value, r *Relation) []reflect.Value {
+	relationMu.Lock()
+	defer relationMu.Unlock()
+
+	objs := relatedObjects(obj.Elem().Field(r.index))
+	for i, related := range objs {
+		objs[i] = reflect.ValueOf(related.Interface())
+	}
+	return objs
+}
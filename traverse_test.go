@@ -0,0 +1,134 @@
+package oop
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestMember is a test class with friends and a club
+type TestMember struct {
+	Name    string
+	Friends []*TestMember
+	Club    *TestClub
+}
+
+// TestClub is a test class with members
+type TestClub struct {
+	Name    string
+	Members []*TestMember
+}
+
+// newTestSociety relates members and clubs:
+// ann - bob - cat - dan, ann and cat in chess, bob in golf, eve alone.
+func newTestSociety(t *testing.T) map[string]*TestMember {
+	t.Helper()
+	memberType := reflect.TypeOf(TestMember{})
+	clubType := reflect.TypeOf(TestClub{})
+	if err := DefineRelation(memberType, "Friends", HasMany, memberType, ""); err != nil {
+		t.Fatalf("DefineRelation returned error: %v", err)
+	}
+	if err := DefineRelation(memberType, "Club", BelongsTo, clubType, "Members"); err != nil {
+		t.Fatalf("DefineRelation returned error: %v", err)
+	}
+
+	members := map[string]*TestMember{}
+	for _, name := range []string{"ann", "bob", "cat", "dan", "eve"} {
+		members[name] = &TestMember{Name: name}
+	}
+	befriend := func(a, b string) {
+		members[a].Friends = append(members[a].Friends, members[b])
+		members[b].Friends = append(members[b].Friends, members[a])
+	}
+	befriend("ann", "bob")
+	befriend("bob", "cat")
+	befriend("cat", "dan")
+
+	chess := &TestClub{Name: "chess", Members: []*TestMember{members["ann"], members["cat"]}}
+	golf := &TestClub{Name: "golf", Members: []*TestMember{members["bob"]}}
+	members["ann"].Club, members["cat"].Club, members["bob"].Club = chess, chess, golf
+	return members
+}
+
+// names returns the names of members
+func names(objs []any) []string {
+	result := make([]string, len(objs))
+	for i, obj := range objs {
+		result[i] = obj.(*TestMember).Name
+	}
+	return result
+}
+
+// TestTraverse tests the Traverse function
+func TestTraverse(t *testing.T) {
+	members := newTestSociety(t)
+
+	// Test a path through a cycle
+	objs, err := Traverse(members["ann"], "Friends[*].Friends", nil)
+	if err != nil {
+		t.Fatalf("Traverse returned error: %v", err)
+	}
+	if got := names(objs); !reflect.DeepEqual(got, []string{"ann", "cat"}) {
+		t.Errorf("Traverse returned %v, want [ann cat]", got)
+	}
+
+	// Test a path through a single relation and a predicate
+	objs, err = Traverse(members["cat"], "Club.Members[*]", func(obj any) bool {
+		return obj.(*TestMember).Name != "cat"
+	})
+	if err != nil {
+		t.Fatalf("Traverse returned error: %v", err)
+	}
+	if got := names(objs); !reflect.DeepEqual(got, []string{"ann"}) {
+		t.Errorf("Traverse returned %v, want [ann]", got)
+	}
+
+	// Test a wrapped start object
+	obj := NewObjectFactory().CreateObject(members["eve"])
+	defer obj.Destroy()
+	if objs, err := Traverse(obj, "Friends[*]", nil); err != nil || len(objs) != 0 {
+		t.Errorf("Traverse returned %v, %v, want no objects", objs, err)
+	}
+
+	// Test invalid paths
+	for _, path := range []string{"", "Club[*]", "Missing", "Friends[*]..Club"} {
+		if _, err := Traverse(members["ann"], path, nil); err == nil {
+			t.Errorf("Traverse with path %q should return an error", path)
+		}
+	}
+	if _, err := Traverse(42, "Friends[*]", nil); err == nil {
+		t.Error("Traverse from a non-object should return an error")
+	}
+}
+
+// TestShortestPath tests the ShortestPath function
+func TestShortestPath(t *testing.T) {
+	members := newTestSociety(t)
+
+	path, ok := ShortestPath(members["ann"], members["dan"], "Friends")
+	if !ok {
+		t.Fatal("ShortestPath should find a path")
+	}
+	if got := names(path); !reflect.DeepEqual(got, []string{"ann", "bob", "cat", "dan"}) {
+		t.Errorf("ShortestPath returned %v", got)
+	}
+
+	// Test that all relations are followed by default
+	path, ok = ShortestPath(members["ann"], members["cat"])
+	if !ok || len(path) != 3 {
+		t.Fatalf("ShortestPath returned %v, %v, want a path of 3 objects", path, ok)
+	}
+	if club, isClub := path[1].(*TestClub); !isClub || club.Name != "chess" {
+		t.Errorf("ShortestPath went through %v, want the chess club", path[1])
+	}
+
+	// Test the trivial path and unreachable objects
+	if path, ok := ShortestPath(members["ann"], members["ann"]); !ok || len(path) != 1 {
+		t.Errorf("ShortestPath to itself returned %v, %v", path, ok)
+	}
+	if _, ok := ShortestPath(members["ann"], members["eve"]); ok {
+		t.Error("ShortestPath should not find a path to an unrelated object")
+	}
+	if _, ok := ShortestPath(members["ann"], nil); ok {
+		t.Error("ShortestPath to nil should return false")
+	}
+}
@@ -0,0 +1,87 @@
+package oop
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// treeMu guards the parents and children of all objects, see SetParent.
+var treeMu sync.Mutex
+
+// SetParent makes the object a child of parent, moving it from its current
+// parent if it has one, or detaches it from its parent if parent is nil.
+// Destroying an object destroys its children after it, depth first. It
+// returns an error if either object is destroyed or parent is the object or
+// one of its descendants.
+// Example: err := buttonObj.SetParent(windowObj)
+func (o *ObjectWrapper) SetParent(parent *ObjectWrapper) error {
+	treeMu.Lock()
+	defer treeMu.Unlock()
+
+	if o.State() == StateDestroyed {
+		return fmt.Errorf("object is not initialized")
+	}
+	if parent == o.parent {
+		return nil
+	}
+	if parent != nil {
+		if parent.State() == StateDestroyed {
+			return fmt.Errorf("parent is destroyed")
+		}
+		for p := parent; p != nil; p = p.parent {
+			if p == o {
+				return fmt.Errorf("an object cannot be a descendant of itself")
+			}
+		}
+	}
+
+	if o.parent != nil {
+		o.parent.children = slices.DeleteFunc(o.parent.children, func(child *ObjectWrapper) bool { return child == o })
+	}
+	o.parent = parent
+	if parent != nil {
+		parent.children = append(parent.children, o)
+	}
+	return nil
+}
+
+// Parent returns the parent of the object, or nil if it has none.
+func (o *ObjectWrapper) Parent() *ObjectWrapper {
+	treeMu.Lock()
+	defer treeMu.Unlock()
+	return o.parent
+}
+
+// Children returns the children of the object in the order they were added.
+// Example: for _, child := range windowObj.Children() { child.Stop() }
+func (o *ObjectWrapper) Children() []*ObjectWrapper {
+	treeMu.Lock()
+	defer treeMu.Unlock()
+	return slices.Clone(o.children)
+}
+
+// detachTree detaches a destroyed object from its parent and its children,
+// and returns the children, for the caller to destroy.
+func (o *ObjectWrapper) detachTree() []*ObjectWrapper {
+	treeMu.Lock()
+	defer treeMu.Unlock()
+
+	if o.parent != nil {
+		o.parent.children = slices.DeleteFunc(o.parent.children, func(child *ObjectWrapper) bool { return child == o })
+		o.parent = nil
+	}
+	children := o.children
+	o.children = nil
+	for _, child := range children {
+		child.parent = nil
+	}
+	return children
+}
+
+// destroyChildren destroys the children of a destroyed object.
+func destroyChildren(children []*ObjectWrapper) {
+	for _, child := range children {
+		child.Destroy()
+	}
+}
@@ -0,0 +1,102 @@
+package oop
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSetParent tests building ownership trees
+func TestSetParent(t *testing.T) {
+	factory := NewObjectFactory()
+	window := factory.CreateObject(&TestWidget{Title: "window"})
+	panel := factory.CreateObject(&TestWidget{Title: "panel"})
+	button := factory.CreateObject(&TestWidget{Title: "button"})
+	defer window.Destroy()
+
+	if err := panel.SetParent(window); err != nil {
+		t.Fatalf("SetParent returned error: %v", err)
+	}
+	if err := button.SetParent(window); err != nil {
+		t.Fatalf("SetParent returned error: %v", err)
+	}
+	if children := window.Children(); len(children) != 2 || children[0] != panel || children[1] != button {
+		t.Errorf("Children returned %v, want panel and button", children)
+	}
+
+	// Test moving a child to another parent
+	if err := button.SetParent(panel); err != nil {
+		t.Fatalf("SetParent returned error: %v", err)
+	}
+	if children := window.Children(); len(children) != 1 || children[0] != panel {
+		t.Errorf("Children returned %v after the move, want panel", children)
+	}
+	if button.Parent() != panel {
+		t.Errorf("Parent returned %v, want panel", button.Parent())
+	}
+
+	// Test that cycles are rejected
+	if err := window.SetParent(button); err == nil {
+		t.Error("SetParent to a descendant should return an error")
+	}
+	if err := window.SetParent(window); err == nil {
+		t.Error("SetParent to the object itself should return an error")
+	}
+
+	// Test detaching
+	if err := button.SetParent(nil); err != nil {
+		t.Fatalf("SetParent(nil) returned error: %v", err)
+	}
+	if button.Parent() != nil || len(panel.Children()) != 0 {
+		t.Error("SetParent(nil) did not detach the object")
+	}
+	button.Destroy()
+	if err := button.SetParent(window); err == nil {
+		t.Error("SetParent of a destroyed object should return an error")
+	}
+	if err := panel.SetParent(button); err == nil {
+		t.Error("SetParent to a destroyed parent should return an error")
+	}
+}
+
+// TestDestroyChildren tests that destroying a parent destroys its subtree
+func TestDestroyChildren(t *testing.T) {
+	factory := NewObjectFactory()
+	window := factory.CreateObject(&TestWidget{Title: "window"})
+	panel := factory.CreateObject(&TestWidget{Title: "panel"})
+	label := factory.CreateObject(&TestWidget{Title: "label"})
+	status := factory.CreateObject(&TestWidget{Title: "status"})
+	panel.SetParent(window)
+	label.SetParent(panel)
+	status.SetParent(window)
+
+	var order []string
+	for _, obj := range []*ObjectWrapper{window, panel, label, status} {
+		title := obj.GetUnderlyingObject().(*TestWidget).Title
+		obj.OnTransition(func(from, to State) {
+			if to == StateDestroyed {
+				order = append(order, title)
+			}
+		})
+	}
+
+	// Test that a child leaves the tree when it is destroyed
+	other := factory.CreateObject(&TestWidget{Title: "other"})
+	other.SetParent(window)
+	other.Destroy()
+	if len(window.Children()) != 2 {
+		t.Errorf("Children returned %v, the destroyed child should be removed", window.Children())
+	}
+
+	window.Destroy()
+	if want := "window,panel,label,status"; strings.Join(order, ",") != want {
+		t.Errorf("the objects were destroyed in the order %v, want %s", order, want)
+	}
+	for _, obj := range []*ObjectWrapper{panel, label, status} {
+		if obj.State() != StateDestroyed || obj.Parent() != nil || len(obj.Children()) != 0 {
+			t.Errorf("a child was left with state %s, parent %v and children %v", obj.State(), obj.Parent(), obj.Children())
+		}
+	}
+	if used, _ := factory.MemoryUsage(); used != 0 {
+		t.Errorf("MemoryUsage = %d after destroying the tree, want 0", used)
+	}
+}
@@ -0,0 +1,64 @@
+package oop
+
+import (
+	"hash/fnv"
+	"reflect"
+	"sync"
+)
+
+// typeIDs assigns the type IDs of the process. IDs are numbered from 1 in
+// the order types are first seen, so they never collide and are never reused.
+var typeIDs = struct {
+	assigned sync.Map   // reflect.Type -> uintptr
+	mu       sync.Mutex // Serializes the assignment of new IDs.
+	last     uintptr    // Last assigned ID.
+}{}
+
+// TypeIDOf returns the type ID of a type, as in TypeInfo. Type IDs identify
+// types within a process: they are assigned in the order types are first
+// seen, so they differ between runs. Use StableTypeID for IDs that are
+// serialized or compared across processes.
+// Example: id := oop.TypeIDOf(reflect.TypeOf(Dog{}))
+func TypeIDOf(t reflect.Type) uintptr {
+	if t == nil {
+		return 0
+	}
+	return typeIDOf(t)
+}
+
+// typeIDOf returns the type ID of a type, assigning the next one if the type
+// has none yet.
+func typeIDOf(t reflect.Type) uintptr {
+	if id, ok := typeIDs.assigned.Load(t); ok {
+		return id.(uintptr)
+	}
+
+	typeIDs.mu.Lock()
+	defer typeIDs.mu.Unlock()
+	if id, ok := typeIDs.assigned.Load(t); ok {
+		return id.(uintptr) // Assigned by another goroutine.
+	}
+	typeIDs.last++
+	typeIDs.assigned.Store(t, typeIDs.last)
+	return typeIDs.last
+}
+
+// StableTypeID returns the 64-bit FNV-1a hash of the full package path and
+// name of a type, e.g. "github.com/acme/zoo.Dog". Unlike TypeIDOf, it is the
+// same in every run and build, so it can be serialized; unnamed types are
+// hashed by their description.
+// Example: id := oop.StableTypeID(reflect.TypeOf(Dog{}))
+func StableTypeID(t reflect.Type) uint64 {
+	if t == nil {
+		return 0
+	}
+
+	name := t.String()
+	if t.Name() != "" && t.PkgPath() != "" {
+		name = t.PkgPath() + "." + t.Name()
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return h.Sum64()
+}
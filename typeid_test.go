@@ -0,0 +1,75 @@
+package oop
+
+import (
+	"hash/fnv"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// TestTypeIDOf tests the TypeIDOf function
+func TestTypeIDOf(t *testing.T) {
+	dogType := reflect.TypeOf(TestDog{})
+	if TypeIDOf(dogType) != TypeIDOf(dogType) {
+		t.Error("TypeIDOf should return the same ID for the same type")
+	}
+	if TypeIDOf(dogType) == TypeIDOf(reflect.PointerTo(dogType)) {
+		t.Error("TypeIDOf should return different IDs for different types")
+	}
+	if TypeIDOf(nil) != 0 {
+		t.Error("TypeIDOf(nil) should return 0")
+	}
+
+	// Test that new types get increasing IDs
+	first := TypeIDOf(reflect.TypeOf([3]TestDog{}))
+	second := TypeIDOf(reflect.TypeOf([4]TestDog{}))
+	if first == 0 || second <= first {
+		t.Errorf("TypeIDOf assigned %d and %d, want increasing IDs", first, second)
+	}
+
+	// Test concurrent assignment
+	types := []reflect.Type{reflect.TypeOf([5]TestDog{}), reflect.TypeOf([6]TestDog{}), reflect.TypeOf([7]TestDog{})}
+	ids := make([][]uintptr, 8)
+	var wg sync.WaitGroup
+	for i := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, typ := range types {
+				ids[i] = append(ids[i], TypeIDOf(typ))
+			}
+		}()
+	}
+	wg.Wait()
+	for _, got := range ids {
+		if !reflect.DeepEqual(got, ids[0]) {
+			t.Errorf("concurrent TypeIDOf returned %v and %v", got, ids[0])
+		}
+	}
+	if ids[0][0] == ids[0][1] || ids[0][1] == ids[0][2] || ids[0][0] == ids[0][2] {
+		t.Errorf("concurrent TypeIDOf assigned colliding IDs %v", ids[0])
+	}
+}
+
+// TestStableTypeID tests the StableTypeID function
+func TestStableTypeID(t *testing.T) {
+	h := fnv.New64a()
+	h.Write([]byte("github.com/dracory/oop.TestDog"))
+	if got := StableTypeID(reflect.TypeOf(TestDog{})); got != h.Sum64() {
+		t.Errorf("StableTypeID is %d, want %d", got, h.Sum64())
+	}
+	if StableTypeID(reflect.TypeOf(TestDog{})) == StableTypeID(reflect.TypeOf(&TestDog{})) {
+		t.Error("StableTypeID should differ between a type and its pointer type")
+	}
+	if StableTypeID(nil) != 0 {
+		t.Error("StableTypeID(nil) should return 0")
+	}
+
+	// Test that ClassInfo carries both IDs
+	klass := New(nil, reflect.TypeOf(TestDog{}), &TestDog{})
+	defer klass.Deinit()
+	info := klass.Header.Info.TypeInfo
+	if info.TypeID != TypeIDOf(reflect.TypeOf(TestDog{})) || info.StableID != h.Sum64() {
+		t.Errorf("TypeInfo is %+v", info)
+	}
+}
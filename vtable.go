@@ -0,0 +1,118 @@
+package oop
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// Vtable is the virtual method table of a class for one interface.
+// Entries are in the interface's method order, so a method can be dispatched
+// by index without looking it up by name.
+type Vtable struct {
+	Interface reflect.Type  // The interface type this vtable implements.
+	Receiver  reflect.Type  // The receiver type the entries are bound to.
+	Entries   []VtableEntry // One entry per interface method.
+}
+
+// VtableEntry is a single method in a Vtable.
+type VtableEntry struct {
+	Name string        // Name of the method.
+	Func reflect.Value // Method expression; takes the receiver as its first argument.
+}
+
+// Index returns the index of the named method, or -1 if the vtable has no such method.
+func (v *Vtable) Index(name string) int {
+	for i, entry := range v.Entries {
+		if entry.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Call calls the method at the given index on the receiver.
+func (v *Vtable) Call(receiver interface{}, index int, args ...interface{}) ([]interface{}, error) {
+	if index < 0 || index >= len(v.Entries) {
+		return nil, fmt.Errorf("vtable index %d out of range", index)
+	}
+	if reflect.TypeOf(receiver) != v.Receiver {
+		return nil, fmt.Errorf("receiver must be %s, got %T", v.Receiver, receiver)
+	}
+
+	return callMethod(v.Entries[index].Func, append([]interface{}{receiver}, args...))
+}
+
+// Vtable returns the vtable of the class instance for the given interface type.
+// Vtables are built on first use and cached in the ClassInfo, with the
+// methods replaced by Override.
+// It returns nil if the class does not implement the interface.
+// Example: vt := klass.Vtable(reflect.TypeOf((*IAnimal)(nil)).Elem())
+func (k *Klass) Vtable(ifaceType reflect.Type) *Vtable {
+	if k == nil || k.Class == nil || ifaceType == nil || ifaceType.Kind() != reflect.Interface {
+		return nil
+	}
+
+	receiver := reflect.TypeOf(k.Class)
+	if !implements(receiver, ifaceType) {
+		return nil
+	}
+
+	info := k.Header.Info
+	if info == nil {
+		return buildVtable(receiver, ifaceType)
+	}
+
+	typeID := typeIDOf(ifaceType)
+
+	info.mu.Lock()
+	defer info.mu.Unlock()
+
+	for _, vi := range info.Vtables {
+		if vi.TypeID == typeID {
+			if vt := (*Vtable)(vi.Vtable); vt.Receiver == receiver {
+				return vt
+			}
+		}
+	}
+
+	vt := buildVtable(receiver, ifaceType)
+	info.applyOverrides(vt)
+	info.Vtables = append(info.Vtables, VtableInfo{
+		TypeID: typeID,
+		Vtable: unsafe.Pointer(vt),
+	})
+	return vt
+}
+
+// buildVtable builds the vtable of a receiver type for an interface it implements.
+func buildVtable(receiver, ifaceType reflect.Type) *Vtable {
+	vt := &Vtable{
+		Interface: ifaceType,
+		Receiver:  receiver,
+		Entries:   make([]VtableEntry, ifaceType.NumMethod()),
+	}
+
+	for i := range ifaceType.NumMethod() {
+		name := ifaceType.Method(i).Name
+		method, _ := receiver.MethodByName(name)
+		vt.Entries[i] = VtableEntry{Name: name, Func: method.Func}
+	}
+
+	return vt
+}
+
+// methodTable enumerates the methods of a class, keyed by name.
+// Methods are taken from the pointer type, which includes value receiver methods.
+func methodTable(classType reflect.Type) map[string]reflect.Method {
+	if classType.Kind() != reflect.Ptr && classType.Kind() != reflect.Interface {
+		classType = reflect.PointerTo(classType)
+	}
+
+	methods := make(map[string]reflect.Method, classType.NumMethod())
+	for i := range classType.NumMethod() {
+		m := classType.Method(i)
+		methods[m.Name] = m
+	}
+	return methods
+}
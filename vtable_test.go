@@ -0,0 +1,76 @@
+package oop
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestKlassVtable tests the Klass.Vtable method
+func TestKlassVtable(t *testing.T) {
+	ifaceType := reflect.TypeOf((*TestInterface)(nil)).Elem()
+
+	ts := &TestStruct{Value: 42}
+	klass := New(nil, reflect.TypeOf(TestStruct{}), ts)
+
+	vt := klass.Vtable(ifaceType)
+	if vt == nil {
+		t.Fatal("Vtable returned nil")
+	}
+	if vt.Interface != ifaceType {
+		t.Errorf("Interface is %v, want %v", vt.Interface, ifaceType)
+	}
+	if len(vt.Entries) != 1 || vt.Entries[0].Name != "GetValue" {
+		t.Fatalf("Entries are %v", vt.Entries)
+	}
+
+	// Test dispatch by index
+	index := vt.Index("GetValue")
+	if index != 0 {
+		t.Fatalf("Index returned %d, want 0", index)
+	}
+	results, err := vt.Call(ts, index)
+	if err != nil {
+		t.Fatalf("Call returned error: %v", err)
+	}
+	if results[0] != 42 {
+		t.Errorf("Call returned %v, want 42", results[0])
+	}
+
+	// Test that the vtable is cached in the ClassInfo
+	if klass.Vtable(ifaceType) != vt {
+		t.Error("Vtable did not return the cached vtable")
+	}
+	if len(klass.Header.Info.Vtables) != 1 {
+		t.Errorf("ClassInfo has %d vtables, want 1", len(klass.Header.Info.Vtables))
+	}
+
+	// Test with non-implemented interface
+	if klass.Vtable(reflect.TypeOf((*TestAnimal)(nil)).Elem()) != nil {
+		t.Error("Vtable should return nil for non-implemented interface")
+	}
+
+	// Test with non-interface type
+	if klass.Vtable(reflect.TypeOf(TestStruct{})) != nil {
+		t.Error("Vtable should return nil for non-interface type")
+	}
+
+	// Test errors
+	if vt.Index("Unknown") != -1 {
+		t.Error("Index should return -1 for unknown method")
+	}
+	if _, err := vt.Call(ts, 1); err == nil {
+		t.Error("Call should return error for out of range index")
+	}
+	if _, err := vt.Call(&TestStruct2{}, 0); err == nil {
+		t.Error("Call should return error for wrong receiver type")
+	}
+}
+
+// TestMethodTable tests that New enumerates the methods of the class
+func TestMethodTable(t *testing.T) {
+	klass := New(nil, reflect.TypeOf(TestStruct{}), nil)
+
+	if _, ok := klass.Header.Info.methods["GetValue"]; !ok {
+		t.Error("method table does not contain GetValue")
+	}
+}